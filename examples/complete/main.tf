@@ -210,8 +210,10 @@ resource "flipt_flag" "experimental_features" {
   enabled         = true
   type            = "VARIANT_FLAG_TYPE"
   metadata = {
-    team  = "platform"
-    stage = "alpha"
+    team     = "platform"
+    stage    = "alpha"
+    priority = 2
+    owners   = ["alice", "bob"]
   }
 }
 
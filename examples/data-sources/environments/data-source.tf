@@ -0,0 +1,9 @@
+data "flipt_environments" "all" {}
+
+output "environment_keys" {
+  value = [for env in data.flipt_environments.all.environments : env.key]
+}
+
+output "default_environment" {
+  value = one([for env in data.flipt_environments.all.environments : env.key if env.default])
+}
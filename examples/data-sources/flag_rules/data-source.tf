@@ -0,0 +1,17 @@
+data "flipt_flag_rules" "checkout" {
+  namespace_key = "production"
+  flag_key      = "new-checkout"
+}
+
+output "checkout_rule_segments" {
+  value = { for rule in data.flipt_flag_rules.checkout.rules : rule.rank => rule.segment_keys }
+}
+
+# Rules can be referenced by their id, for example by a distribution
+output "checkout_rule_ids" {
+  value = [for rule in data.flipt_flag_rules.checkout.rules : rule.id]
+}
+
+output "checkout_targets_beta" {
+  value = anytrue([for rule in data.flipt_flag_rules.checkout.rules : contains(rule.segment_keys, "beta-users")])
+}
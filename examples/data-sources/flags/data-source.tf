@@ -0,0 +1,15 @@
+data "flipt_flags" "payments" {
+  namespace_key  = "production"
+  key_prefix     = "checkout-"
+  enabled        = true
+  metadata_match = { team = "payments" }
+}
+
+output "payments_flag_keys" {
+  value = [for flag in data.flipt_flags.payments.flags : flag.key]
+}
+
+# Metadata keeps its JSON types, as on flipt_flag
+output "payments_flag_owners" {
+  value = { for flag in data.flipt_flags.payments.flags : flag.key => try(flag.metadata.owner, null) }
+}
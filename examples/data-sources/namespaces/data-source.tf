@@ -0,0 +1,13 @@
+data "flipt_namespaces" "teams" {
+  key_prefix = "team-"
+}
+
+# Look up the flags of every team namespace
+data "flipt_flags" "team" {
+  for_each      = { for ns in data.flipt_namespaces.teams.namespaces : ns.key => ns }
+  namespace_key = each.key
+}
+
+output "team_flag_counts" {
+  value = { for key, flags in data.flipt_flags.team : key => length(flags.flags) }
+}
@@ -0,0 +1,12 @@
+data "flipt_segment_constraints" "beta" {
+  namespace_key = "production"
+  segment_key   = "beta-users"
+}
+
+output "beta_match_type" {
+  value = data.flipt_segment_constraints.beta.match_type
+}
+
+output "beta_properties" {
+  value = [for c in data.flipt_segment_constraints.beta.constraints : c.property]
+}
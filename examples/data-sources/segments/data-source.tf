@@ -0,0 +1,8 @@
+data "flipt_segments" "internal" {
+  namespace_key = "production"
+  key_prefix    = "internal-"
+}
+
+output "internal_segment_keys" {
+  value = [for segment in data.flipt_segments.internal.segments : segment.key]
+}
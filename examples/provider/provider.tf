@@ -16,3 +16,33 @@ provider "flipt" {
 #   endpoint = "http://localhost:8080"
 #   jwt      = "your_jwt_token_here"     # Uses JWT authentication
 # }
+
+# Provider configuration with custom retry and timeout settings
+# provider "flipt" {
+#   endpoint        = "http://localhost:8080"
+#   max_retries     = 5      # Retry failed requests up to 5 times
+#   retry_wait_min  = "500ms"
+#   retry_wait_max  = "10s"
+#   request_timeout = "1m"
+# }
+
+# Provider configuration from the environment
+# export FLIPT_ENDPOINT=http://localhost:8080
+# export FLIPT_TOKEN=your_static_token_here
+# provider "flipt" {}
+
+# Provider configuration for a server using an internal CA and mutual TLS
+# provider "flipt" {
+#   endpoint     = "https://flipt.internal:8443"
+#   ca_cert_file = "/etc/ssl/internal-ca.pem"
+#   client_cert  = "/etc/flipt/client.pem"     # PEM content or a file path
+#   client_key   = "/etc/flipt/client-key.pem" # PEM content or a file path
+# }
+
+# Provider configuration with a default environment and namespace for all
+# resources and data sources that do not set their own
+# provider "flipt" {
+#   endpoint                = "http://localhost:8080"
+#   default_environment_key = "production"
+#   default_namespace_key   = "team-a"
+# }
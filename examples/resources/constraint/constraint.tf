@@ -49,6 +49,41 @@ resource "flipt_constraint" "premium_tier" {
   description   = "Match premium subscription tier"
 }
 
+# Several constraints may share a property
+resource "flipt_segment" "adults" {
+  namespace_key = flipt_namespace.example.key
+  key           = "adults"
+  name          = "Adults"
+  match_type    = "ALL_MATCH_TYPE"
+}
+
+resource "flipt_constraint" "min_age" {
+  namespace_key = flipt_namespace.example.key
+  segment_key   = flipt_segment.adults.key
+  property      = "age"
+  type          = "NUMBER_COMPARISON_TYPE"
+  operator      = "gte"
+  value         = "18"
+}
+
+resource "flipt_constraint" "max_age" {
+  namespace_key = flipt_namespace.example.key
+  segment_key   = flipt_segment.adults.key
+  property      = "age"
+  type          = "NUMBER_COMPARISON_TYPE"
+  operator      = "lte"
+  value         = "65"
+}
+
+# Operators such as present take no value
+resource "flipt_constraint" "has_age" {
+  namespace_key = flipt_namespace.example.key
+  segment_key   = flipt_segment.adults.key
+  property      = "age"
+  type          = "NUMBER_COMPARISON_TYPE"
+  operator      = "present"
+}
+
 # Note: Import example (not used in this config)
-# terraform import flipt_constraint.premium_tier default/premium-users/subscription_tier
+# terraform import flipt_constraint.premium_tier default/example/premium-users/subscription_tier/eq/premium
 
@@ -13,10 +13,10 @@ provider "flipt" {
 resource "flipt_distribution" "example" {
   namespace_key = "default"
   flag_key      = "my-feature"
-  rule_id       = "rule-id-here"
-  variant_id    = "variant-id-here"
+  rule_id       = flipt_rule.example.id
+  variant_key   = flipt_variant.example.key
   rollout       = 50.0
 }
 
 # Import existing distribution
-# terraform import flipt_distribution.example distribution-id
+# terraform import flipt_distribution.example default/default/my-feature/0/variant-key
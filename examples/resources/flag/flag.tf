@@ -17,7 +17,13 @@ resource "flipt_flag" "example" {
   description   = "Controls access to my feature"
   enabled       = true
   type          = "VARIANT_FLAG_TYPE"
+
+  metadata = {
+    owner    = "team-a"
+    priority = 1
+    beta     = true
+  }
 }
 
 # Import existing flag
-# terraform import flipt_flag.example default/existing-flag-key
+# terraform import flipt_flag.example default/default/existing-flag-key
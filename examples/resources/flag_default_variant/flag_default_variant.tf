@@ -0,0 +1,36 @@
+terraform {
+  required_providers {
+    flipt = {
+      source = "lerentis/flipt"
+    }
+  }
+}
+
+provider "flipt" {
+  endpoint = "http://localhost:8080"
+}
+
+resource "flipt_flag" "checkout" {
+  namespace_key = "default"
+  key           = "checkout"
+  name          = "Checkout"
+  type          = "VARIANT_FLAG_TYPE"
+  enabled       = true
+}
+
+resource "flipt_variant" "control" {
+  namespace_key = "default"
+  flag_key      = flipt_flag.checkout.key
+  key           = "control"
+  name          = "Control"
+}
+
+# Serve the control variant when no rule matches
+resource "flipt_flag_default_variant" "checkout" {
+  namespace_key = "default"
+  flag_key      = flipt_flag.checkout.key
+  variant_key   = flipt_variant.control.key
+}
+
+# Import the default variant of an existing flag
+# terraform import flipt_flag_default_variant.checkout default/default/checkout
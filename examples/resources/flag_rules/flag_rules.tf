@@ -0,0 +1,70 @@
+terraform {
+  required_providers {
+    flipt = {
+      source = "lerentis/flipt"
+    }
+  }
+}
+
+provider "flipt" {
+  endpoint = "http://localhost:8080"
+}
+
+resource "flipt_flag" "checkout" {
+  namespace_key = "default"
+  key           = "checkout"
+  name          = "Checkout"
+  type          = "VARIANT_FLAG_TYPE"
+  enabled       = true
+}
+
+resource "flipt_variant" "control" {
+  namespace_key = "default"
+  flag_key      = flipt_flag.checkout.key
+  key           = "control"
+}
+
+resource "flipt_variant" "treatment" {
+  namespace_key = "default"
+  flag_key      = flipt_flag.checkout.key
+  key           = "treatment"
+}
+
+resource "flipt_segment" "beta" {
+  namespace_key = "default"
+  key           = "beta-users"
+  name          = "Beta Users"
+  match_type    = "ALL_MATCH_TYPE"
+}
+
+resource "flipt_segment" "everyone" {
+  namespace_key = "default"
+  key           = "everyone"
+  name          = "Everyone"
+  match_type    = "ANY_MATCH_TYPE"
+}
+
+# All rules of the flag, evaluated in the order listed
+resource "flipt_flag_rules" "checkout" {
+  namespace_key = "default"
+  flag_key      = flipt_flag.checkout.key
+
+  rules = [
+    {
+      segment_keys = [flipt_segment.beta.key]
+      distributions = [
+        { variant_key = flipt_variant.treatment.key, rollout = 100 },
+      ]
+    },
+    {
+      segment_keys = [flipt_segment.everyone.key]
+      distributions = [
+        { variant_key = flipt_variant.control.key, rollout = 50 },
+        { variant_key = flipt_variant.treatment.key, rollout = 50 },
+      ]
+    },
+  ]
+}
+
+# Import the rules of an existing flag
+# terraform import flipt_flag_rules.checkout default/default/checkout
@@ -23,3 +23,14 @@ resource "flipt_namespace" "staging" {
   description = "Namespace for staging feature flags"
   protected   = false
 }
+
+# Destroying this namespace also deletes the flags and segments created in it
+# outside of Terraform
+resource "flipt_namespace" "preview" {
+  key           = "preview"
+  name          = "Preview Environments"
+  force_destroy = true
+}
+
+# Import existing namespace
+# terraform import flipt_namespace.staging default/staging
@@ -0,0 +1,51 @@
+terraform {
+  required_providers {
+    flipt = {
+      source = "lerentis/flipt"
+    }
+  }
+}
+
+provider "flipt" {
+  endpoint = "http://localhost:8080"
+}
+
+# Create a boolean flag
+resource "flipt_flag" "dark_mode" {
+  namespace_key = "default"
+  key           = "dark-mode"
+  name          = "Dark Mode"
+  type          = "BOOLEAN_FLAG_TYPE"
+  enabled       = true
+}
+
+# Create a segment
+resource "flipt_segment" "internal_users" {
+  namespace_key = "default"
+  key           = "internal-users"
+  name          = "Internal Users"
+  match_type    = "ALL_MATCH_TYPE"
+}
+
+# Enable the flag for everyone in the segment
+resource "flipt_rollout" "internal" {
+  namespace_key    = "default"
+  flag_key         = flipt_flag.dark_mode.key
+  rank             = 0
+  type             = "SEGMENT_ROLLOUT_TYPE"
+  segment_keys     = [flipt_segment.internal_users.key]
+  segment_operator = "OR_SEGMENT_OPERATOR"
+  value            = true
+}
+
+# Enable the flag for 25% of the remaining users, after the segment rollout
+resource "flipt_rollout" "gradual" {
+  namespace_key = "default"
+  flag_key      = flipt_flag.dark_mode.key
+  type          = "THRESHOLD_ROLLOUT_TYPE"
+  percentage    = 25
+  value         = true
+}
+
+# Import existing rollout
+# terraform import flipt_rollout.gradual default/default/dark-mode/1
@@ -44,5 +44,5 @@ resource "flipt_rule" "example" {
 }
 
 # Note: Import example (not used in this config)
-# terraform import flipt_rule.example namespace_key/flag_key/rule_id
+# terraform import flipt_rule.example default/example/my-feature/0
 
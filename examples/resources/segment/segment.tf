@@ -19,4 +19,4 @@ resource "flipt_segment" "example" {
 }
 
 # Import existing segment
-# terraform import flipt_segment.example default/existing-segment-key
+# terraform import flipt_segment.example default/default/existing-segment-key
@@ -0,0 +1,49 @@
+terraform {
+  required_providers {
+    flipt = {
+      source = "lerentis/flipt"
+    }
+  }
+}
+
+provider "flipt" {
+  endpoint = "http://localhost:8080"
+}
+
+resource "flipt_segment" "adults" {
+  namespace_key = "default"
+  key           = "adults"
+  name          = "Adults"
+  match_type    = "ALL_MATCH_TYPE"
+}
+
+# All constraints of the segment, written in a single request
+resource "flipt_segment_constraints" "adults" {
+  namespace_key = "default"
+  segment_key   = flipt_segment.adults.key
+
+  constraints = [
+    {
+      property = "age"
+      type     = "NUMBER_COMPARISON_TYPE"
+      operator = "gte"
+      value    = "18"
+    },
+    {
+      property    = "country"
+      type        = "STRING_COMPARISON_TYPE"
+      operator    = "eq"
+      value       = "NL"
+      description = "Only available in the Netherlands"
+    },
+  ]
+}
+
+# Take over a constraint previously managed by flipt_constraint (Terraform 1.8+)
+# moved {
+#   from = flipt_constraint.min_age
+#   to   = flipt_segment_constraints.adults
+# }
+
+# Import the constraints of an existing segment
+# terraform import flipt_segment_constraints.adults default/default/adults
@@ -19,3 +19,6 @@ resource "flipt_variant" "example" {
   description   = "First variant option"
   attachment    = jsonencode({ color = "red", size = "large" })
 }
+
+# Import existing variant
+# terraform import flipt_variant.example default/default/my-feature/variant-a
@@ -0,0 +1,218 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+// Package client implements a typed client for the Flipt v2 HTTP API used by
+// the provider's resources and data sources.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Config holds the settings used to construct a Client.
+type Config struct {
+	// Endpoint is the base URL of the Flipt server, e.g. http://localhost:8080.
+	Endpoint string
+	// Token is a static token sent using Bearer authentication.
+	Token string
+	// JWT is a JSON Web Token sent using JWT authentication. It is ignored
+	// when Token is set.
+	JWT string
+	// HTTPClient is used to perform requests. http.DefaultClient is used when nil.
+	HTTPClient *http.Client
+	// MaxRetries is the number of times a failed request is retried. Requests
+	// are not retried when zero.
+	MaxRetries int
+	// RetryWaitMin is the wait before the first retry. It doubles with every
+	// further attempt up to RetryWaitMax.
+	RetryWaitMin time.Duration
+	// RetryWaitMax caps the wait between retries.
+	RetryWaitMax time.Duration
+}
+
+// Client is a typed client for the Flipt v2 API.
+type Client struct {
+	endpoint   string
+	token      string
+	jwt        string
+	httpClient *http.Client
+
+	maxRetries   int
+	retryWaitMin time.Duration
+	retryWaitMax time.Duration
+}
+
+// New returns a Client for the given configuration.
+func New(cfg Config) *Client {
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	retryWaitMax := cfg.RetryWaitMax
+	if retryWaitMax < cfg.RetryWaitMin {
+		retryWaitMax = cfg.RetryWaitMin
+	}
+
+	return &Client{
+		endpoint:     strings.TrimRight(cfg.Endpoint, "/"),
+		token:        cfg.Token,
+		jwt:          cfg.JWT,
+		httpClient:   httpClient,
+		maxRetries:   cfg.MaxRetries,
+		retryWaitMin: cfg.RetryWaitMin,
+		retryWaitMax: retryWaitMax,
+	}
+}
+
+// Endpoint returns the base URL of the Flipt server.
+func (c *Client) Endpoint() string {
+	return c.endpoint
+}
+
+// url joins the escaped path segments onto the API base URL.
+func (c *Client) url(segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, s := range segments {
+		escaped[i] = url.PathEscape(s)
+	}
+	return c.endpoint + "/api/v2/" + strings.Join(escaped, "/")
+}
+
+// paginate calls fetch for each page of a list endpoint, adding the page
+// token returned by the previous page to listURL, until the server returns no
+// next page token.
+func paginate(listURL string, fetch func(pageURL string) (nextPageToken string, err error)) error {
+	pageToken := ""
+	for {
+		pageURL := listURL
+		if pageToken != "" {
+			pageURL += "?" + url.Values{"pageToken": {pageToken}}.Encode()
+		}
+
+		next, err := fetch(pageURL)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		if next == pageToken {
+			return fmt.Errorf("listing %s returned the same page token %q twice", listURL, next)
+		}
+		pageToken = next
+	}
+}
+
+// do performs an HTTP request against the API. in is encoded as the JSON
+// request body when non-nil and the response body is decoded into out when
+// non-nil. Non-2xx responses are returned as *APIError. Rate limited requests,
+// and gateway or connection errors on idempotent requests, are retried up to
+// the configured number of times.
+func (c *Client) do(ctx context.Context, method, url string, in, out interface{}) error {
+	var reqBody []byte
+	if in != nil {
+		var err error
+		reqBody, err = json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("unable to marshal request: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, url, reqBody)
+
+		if attempt < c.maxRetries && shouldRetry(ctx, method, resp, err) {
+			wait := backoff(c.retryWaitMin, c.retryWaitMax, attempt, resp)
+			if resp != nil {
+				_, _ = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+
+			tflog.Debug(ctx, "Retrying Flipt API request", map[string]interface{}{
+				"method":  method,
+				"url":     url,
+				"attempt": attempt + 1,
+				"wait":    wait.String(),
+				"error":   retryReason(resp, err),
+			})
+
+			if err := sleep(ctx, wait); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return c.handleResponse(method, url, resp, out)
+	}
+}
+
+// send performs a single attempt of a request.
+func (c *Client) send(ctx context.Context, method, url string, reqBody []byte) (*http.Response, error) {
+	var body io.Reader
+	if reqBody != nil {
+		body = bytes.NewReader(reqBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	c.addAuthHeader(req)
+
+	return c.httpClient.Do(req)
+}
+
+// handleResponse reads the response, turning non-2xx responses into *APIError
+// and decoding the body into out otherwise.
+func (c *Client) handleResponse(method, url string, resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("unable to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &APIError{
+			Method:     method,
+			URL:        url,
+			StatusCode: resp.StatusCode,
+			Body:       string(respBody),
+		}
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("unable to parse response: %w, body: %s", err, string(respBody))
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) addAuthHeader(req *http.Request) {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else if c.jwt != "" {
+		req.Header.Set("Authorization", "JWT "+c.jwt)
+	}
+}
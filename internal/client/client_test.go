@@ -0,0 +1,430 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClient_AuthHeader(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         Config
+		expectedHeader string
+	}{
+		{
+			name:           "Bearer token authentication",
+			config:         Config{Token: "test-token", JWT: "ignored"},
+			expectedHeader: "Bearer test-token",
+		},
+		{
+			name:           "JWT authentication",
+			config:         Config{JWT: "test.jwt.token"},
+			expectedHeader: "JWT test.jwt.token",
+		},
+		{
+			name:   "No authentication",
+			config: Config{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var authHeader string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authHeader = r.Header.Get("Authorization")
+				_, _ = w.Write([]byte(`{"environments":[]}`))
+			}))
+			defer server.Close()
+
+			tt.config.Endpoint = server.URL
+			if _, err := New(tt.config).ListEnvironments(context.Background()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if authHeader != tt.expectedHeader {
+				t.Errorf("Expected Authorization header to be %q, got %q", tt.expectedHeader, authHeader)
+			}
+		})
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		expected   error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusConflict, ErrConflict},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.statusCode), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(`{"message":"boom"}`))
+			}))
+			defer server.Close()
+
+			_, err := New(Config{Endpoint: server.URL}).GetFlag(context.Background(), "default", "ns", "flag")
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, err)
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected *APIError, got %T", err)
+			}
+			if apiErr.StatusCode != tt.statusCode || apiErr.Body != `{"message":"boom"}` {
+				t.Errorf("Unexpected error details: %+v", apiErr)
+			}
+		})
+	}
+}
+
+func TestClient_GetFlag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Expected GET, got %s", r.Method)
+		}
+		if r.URL.Path != "/api/v2/environments/production/namespaces/team-a/resources/flipt.core.Flag/my-flag" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+
+		_, _ = w.Write([]byte(`{
+			"resource": {
+				"namespaceKey": "team-a",
+				"key": "my-flag",
+				"payload": {
+					"@type": "flipt.core.Flag",
+					"key": "my-flag",
+					"name": "My Flag",
+					"type": "VARIANT_FLAG_TYPE",
+					"enabled": true,
+					"variants": [{"key": "on", "attachment": {"color": "blue"}}],
+					"rules": [{"segments": ["beta"], "segmentOperator": "OR_SEGMENT_OPERATOR", "rank": 0}],
+					"defaultVariant": "on"
+				}
+			},
+			"revision": "abc123"
+		}`))
+	}))
+	defer server.Close()
+
+	flag, err := New(Config{Endpoint: server.URL + "/"}).GetFlag(context.Background(), "production", "team-a", "my-flag")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if flag.Key != "my-flag" || flag.Name != "My Flag" || !flag.Enabled {
+		t.Errorf("Unexpected flag: %+v", flag)
+	}
+	if flag.Revision != "abc123" {
+		t.Errorf("Expected revision abc123, got %q", flag.Revision)
+	}
+	if len(flag.Variants) != 1 || !reflect.DeepEqual(flag.Variants[0].Attachment, map[string]interface{}{"color": "blue"}) {
+		t.Errorf("Unexpected variants: %+v", flag.Variants)
+	}
+	if len(flag.Rules) != 1 || flag.Rules[0].Segments[0] != "beta" {
+		t.Errorf("Unexpected rules: %+v", flag.Rules)
+	}
+	if flag.DefaultVariant != "on" {
+		t.Errorf("Expected default variant on, got %q", flag.DefaultVariant)
+	}
+}
+
+func TestClient_PutFlag(t *testing.T) {
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("Expected PUT, got %s", r.Method)
+		}
+		if r.URL.Path != "/api/v2/environments/default/namespaces/ns/resources" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected JSON content type, got %q", ct)
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &request); err != nil {
+			t.Fatalf("Unable to parse request: %v", err)
+		}
+
+		// Echo the payload back like the server does.
+		response := map[string]interface{}{
+			"resource": map[string]interface{}{
+				"namespaceKey": "ns",
+				"key":          request["key"],
+				"payload":      request["payload"],
+			},
+			"revision": "def456",
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	flag, err := New(Config{Endpoint: server.URL}).PutFlag(context.Background(), "default", "ns", &Flag{
+		Key:     "my-flag",
+		Name:    "My Flag",
+		Type:    "BOOLEAN_FLAG_TYPE",
+		Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if request["key"] != "my-flag" {
+		t.Errorf("Expected key my-flag, got %v", request["key"])
+	}
+	payload, ok := request["payload"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected payload object, got %T", request["payload"])
+	}
+	if payload["@type"] != TypeFlag {
+		t.Errorf("Expected @type %s, got %v", TypeFlag, payload["@type"])
+	}
+	if payload["type"] != "BOOLEAN_FLAG_TYPE" || payload["enabled"] != true {
+		t.Errorf("Unexpected payload: %v", payload)
+	}
+	if _, ok := payload["Revision"]; ok {
+		t.Error("Revision must not be sent as part of the payload")
+	}
+
+	if flag.Key != "my-flag" || flag.Revision != "def456" {
+		t.Errorf("Unexpected flag: %+v", flag)
+	}
+}
+
+func TestClient_GetEnvironment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/environments" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"environments":[{"key":"default","name":"Default","default":true}]}`))
+	}))
+	defer server.Close()
+
+	c := New(Config{Endpoint: server.URL})
+
+	env, err := c.GetEnvironment(context.Background(), "default")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if env.Name != "Default" || !env.Default {
+		t.Errorf("Unexpected environment: %+v", env)
+	}
+
+	if _, err := c.GetEnvironment(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestClient_PathEscaping(t *testing.T) {
+	var rawPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawPath = r.URL.EscapedPath()
+		_, _ = w.Write([]byte(`{"namespace":{"key":"a/b","name":"A"}}`))
+	}))
+	defer server.Close()
+
+	if _, err := New(Config{Endpoint: server.URL}).GetNamespace(context.Background(), "default", "a/b"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if rawPath != "/api/v2/environments/default/namespaces/a%2Fb" {
+		t.Errorf("Expected escaped key in path, got %s", rawPath)
+	}
+}
+
+func TestClient_UpdateFlag(t *testing.T) {
+	tests := []struct {
+		name      string
+		conflicts int
+		expectErr bool
+	}{
+		{name: "No conflict", conflicts: 0},
+		{name: "Conflict resolved by retrying", conflicts: 2},
+		{name: "Retries exhausted", conflicts: maxConflictRetries + 1, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revision := 1
+			puts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					_, _ = fmt.Fprintf(w, `{"resource":{"key":"my-flag","payload":{"key":"my-flag","name":"My Flag"}},"revision":"%d"}`, revision)
+				case http.MethodPut:
+					var req resourceRequest
+					_ = json.NewDecoder(r.Body).Decode(&req)
+					if req.Revision != fmt.Sprint(revision) {
+						t.Errorf("Expected revision %d, got %q", revision, req.Revision)
+					}
+
+					puts++
+					if puts <= tt.conflicts {
+						// Someone else changed the namespace in the meantime.
+						revision++
+						w.WriteHeader(http.StatusConflict)
+						return
+					}
+					revision++
+					_, _ = fmt.Fprintf(w, `{"resource":{"key":"my-flag","payload":{"key":"my-flag","name":"Renamed"}},"revision":"%d"}`, revision)
+				}
+			}))
+			defer server.Close()
+
+			flag, err := New(Config{Endpoint: server.URL}).UpdateFlag(context.Background(), "default", "ns", "my-flag", func(flag *Flag) error {
+				flag.Name = "Renamed"
+				return nil
+			})
+
+			if tt.expectErr {
+				if !errors.Is(err, ErrConflict) {
+					t.Fatalf("Expected ErrConflict, got %v", err)
+				}
+				if !strings.Contains(err.Error(), `flag "my-flag" in namespace "ns"`) {
+					t.Errorf("Expected error to name the flag, got %q", err)
+				}
+				if puts != maxConflictRetries+1 {
+					t.Errorf("Expected %d attempts, got %d", maxConflictRetries+1, puts)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if flag.Name != "Renamed" {
+				t.Errorf("Expected updated flag, got %+v", flag)
+			}
+			if puts != tt.conflicts+1 {
+				t.Errorf("Expected %d attempts, got %d", tt.conflicts+1, puts)
+			}
+		})
+	}
+}
+
+func TestClient_UpdateFlagModifyError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Unexpected %s request", r.Method)
+		}
+		_, _ = w.Write([]byte(`{"resource":{"key":"my-flag","payload":{"key":"my-flag"}},"revision":"1"}`))
+	}))
+	defer server.Close()
+
+	errStop := errors.New("stop")
+	_, err := New(Config{Endpoint: server.URL}).UpdateFlag(context.Background(), "default", "ns", "my-flag", func(flag *Flag) error {
+		return errStop
+	})
+	if err != errStop {
+		t.Fatalf("Expected error from modify, got %v", err)
+	}
+}
+
+func TestClient_ListFlags(t *testing.T) {
+	var pageTokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Expected GET, got %s", r.Method)
+		}
+		if r.URL.Path != "/api/v2/environments/production/namespaces/team-a/resources/flipt.core.Flag" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+
+		pageToken := r.URL.Query().Get("pageToken")
+		pageTokens = append(pageTokens, pageToken)
+		switch pageToken {
+		case "":
+			_, _ = w.Write([]byte(`{
+				"resources": [
+					{"namespaceKey": "team-a", "key": "a", "payload": {"@type": "flipt.core.Flag", "key": "a", "name": "A"}},
+					{"namespaceKey": "team-a", "key": "b", "payload": {"@type": "flipt.core.Flag", "key": "b", "name": "B"}}
+				],
+				"revision": "abc123",
+				"nextPageToken": "page-2"
+			}`))
+		case "page-2":
+			_, _ = w.Write([]byte(`{
+				"resources": [
+					{"namespaceKey": "team-a", "key": "c", "payload": {"@type": "flipt.core.Flag", "key": "c", "name": "C"}}
+				],
+				"revision": "abc123"
+			}`))
+		default:
+			t.Errorf("Unexpected page token %q", pageToken)
+		}
+	}))
+	defer server.Close()
+
+	flags, err := New(Config{Endpoint: server.URL}).ListFlags(context.Background(), "production", "team-a")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var keys []string
+	for _, flag := range flags {
+		keys = append(keys, flag.Key)
+	}
+	if !reflect.DeepEqual(keys, []string{"a", "b", "c"}) {
+		t.Errorf("Expected flags a, b and c, got %v", keys)
+	}
+	if !reflect.DeepEqual(pageTokens, []string{"", "page-2"}) {
+		t.Errorf("Expected two pages, got page tokens %q", pageTokens)
+	}
+}
+
+func TestClient_ListRepeatedPageToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"resources": [], "nextPageToken": "same"}`))
+	}))
+	defer server.Close()
+
+	_, err := New(Config{Endpoint: server.URL}).ListSegments(context.Background(), "default", "default")
+	if err == nil || !strings.Contains(err.Error(), "same page token") {
+		t.Fatalf("Expected an error for a repeated page token, got %v", err)
+	}
+}
+
+func TestClient_ListNamespaces(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/environments/production/namespaces" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+
+		if r.URL.Query().Get("pageToken") == "" {
+			_, _ = w.Write([]byte(`{"items": [{"key": "default", "name": "Default", "protected": true}], "nextPageToken": "next"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"items": [{"key": "team-a", "name": "Team A", "description": "Team A flags"}]}`))
+	}))
+	defer server.Close()
+
+	namespaces, err := New(Config{Endpoint: server.URL}).ListNamespaces(context.Background(), "production")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []Namespace{
+		{Key: "default", Name: "Default", Protected: true},
+		{Key: "team-a", Name: "Team A", Description: "Team A flags"},
+	}
+	if !reflect.DeepEqual(namespaces, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, namespaces)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListEnvironments returns all environments configured on the server.
+func (c *Client) ListEnvironments(ctx context.Context) ([]Environment, error) {
+	var response struct {
+		Environments []Environment `json:"environments"`
+	}
+
+	if err := c.do(ctx, http.MethodGet, c.url("environments"), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Environments, nil
+}
+
+// GetEnvironment returns the environment with the given key. ErrNotFound is
+// returned when no such environment exists.
+func (c *Client) GetEnvironment(ctx context.Context, key string) (*Environment, error) {
+	environments, err := c.ListEnvironments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, env := range environments {
+		if env.Key == key {
+			return &env, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
@@ -0,0 +1,52 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrNotFound is returned when the requested object does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when the request conflicts with the current
+	// state of the object, e.g. it already exists or was modified concurrently.
+	ErrConflict = errors.New("conflict")
+	// ErrUnauthorized is returned when the request was rejected because of
+	// missing or invalid credentials.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is returned when the credentials are valid but lack the
+	// permissions for the request.
+	ErrForbidden = errors.New("forbidden")
+)
+
+// APIError is returned for any non-2xx response from the Flipt API.
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: status: %d, body: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// Is allows errors.Is to match an APIError against the sentinel errors of
+// this package based on its status code.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	}
+	return false
+}
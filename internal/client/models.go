@@ -0,0 +1,115 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package client
+
+// Resource type URLs used by the Flipt v2 resources API.
+const (
+	TypeFlag    = "flipt.core.Flag"
+	TypeSegment = "flipt.core.Segment"
+)
+
+// Environment is a Flipt environment. Environments are configured server-side
+// and are read-only through the API.
+type Environment struct {
+	Key     string `json:"key"`
+	Name    string `json:"name"`
+	Default bool   `json:"default"`
+}
+
+// Namespace is a Flipt namespace within an environment.
+type Namespace struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Protected   bool   `json:"protected"`
+}
+
+// Flag is the payload of a flipt.core.Flag resource.
+type Flag struct {
+	Key            string                 `json:"key"`
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description"`
+	Type           string                 `json:"type"`
+	Enabled        bool                   `json:"enabled"`
+	Variants       []Variant              `json:"variants,omitempty"`
+	Rules          []Rule                 `json:"rules,omitempty"`
+	Rollouts       []Rollout              `json:"rollouts,omitempty"`
+	DefaultVariant string                 `json:"defaultVariant,omitempty"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+
+	// Revision is the revision of the namespace the flag was read at. It is
+	// not part of the payload.
+	Revision string `json:"-"`
+}
+
+// Variant is a variant of a flag.
+type Variant struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Attachment is any JSON value, decoded as by encoding/json.
+	Attachment interface{} `json:"attachment"`
+}
+
+// Rule is an evaluation rule of a variant flag.
+type Rule struct {
+	ID              string         `json:"id,omitempty"`
+	Segments        []string       `json:"segments"`
+	SegmentOperator string         `json:"segmentOperator"`
+	Rank            int64          `json:"rank"`
+	Distributions   []Distribution `json:"distributions,omitempty"`
+}
+
+// Distribution assigns a percentage of the traffic matched by a rule to a
+// variant.
+type Distribution struct {
+	ID      string  `json:"id,omitempty"`
+	Variant string  `json:"variant"`
+	Rollout float64 `json:"rollout"`
+}
+
+// Rollout is an evaluation rollout of a boolean flag. Rollouts have no rank
+// of their own, they are evaluated in the order of the flag's rollouts.
+// Exactly one of Segment and Threshold is set, depending on Type.
+type Rollout struct {
+	Type        string            `json:"type"`
+	Description string            `json:"description,omitempty"`
+	Segment     *RolloutSegment   `json:"segment,omitempty"`
+	Threshold   *RolloutThreshold `json:"threshold,omitempty"`
+}
+
+// RolloutSegment returns Value for entities matching the segments.
+type RolloutSegment struct {
+	Value           bool     `json:"value"`
+	Segments        []string `json:"segments"`
+	SegmentOperator string   `json:"segmentOperator"`
+}
+
+// RolloutThreshold returns Value for a percentage of the entities.
+type RolloutThreshold struct {
+	Percentage float64 `json:"percentage"`
+	Value      bool    `json:"value"`
+}
+
+// Segment is the payload of a flipt.core.Segment resource.
+type Segment struct {
+	Key         string       `json:"key"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	MatchType   string       `json:"matchType"`
+	Constraints []Constraint `json:"constraints,omitempty"`
+
+	// Revision is the revision of the namespace the segment was read at. It
+	// is not part of the payload.
+	Revision string `json:"-"`
+}
+
+// Constraint is a single constraint of a segment.
+type Constraint struct {
+	Type        string `json:"type"`
+	Property    string `json:"property"`
+	Operator    string `json:"operator"`
+	Value       string `json:"value"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,69 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+type namespaceResponse struct {
+	Namespace Namespace `json:"namespace"`
+	Revision  string    `json:"revision"`
+}
+
+type namespaceListResponse struct {
+	Items         []Namespace `json:"items"`
+	Revision      string      `json:"revision"`
+	NextPageToken string      `json:"nextPageToken"`
+}
+
+// ListNamespaces returns every namespace in an environment.
+func (c *Client) ListNamespaces(ctx context.Context, envKey string) ([]Namespace, error) {
+	var namespaces []Namespace
+	err := paginate(c.url("environments", envKey, "namespaces"), func(pageURL string) (string, error) {
+		var response namespaceListResponse
+		if err := c.do(ctx, http.MethodGet, pageURL, nil, &response); err != nil {
+			return "", err
+		}
+		namespaces = append(namespaces, response.Items...)
+		return response.NextPageToken, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return namespaces, nil
+}
+
+// GetNamespace returns the namespace with the given key.
+func (c *Client) GetNamespace(ctx context.Context, envKey, key string) (*Namespace, error) {
+	var response namespaceResponse
+	if err := c.do(ctx, http.MethodGet, c.url("environments", envKey, "namespaces", key), nil, &response); err != nil {
+		return nil, err
+	}
+	return &response.Namespace, nil
+}
+
+// CreateNamespace creates a namespace and returns it as stored by the server.
+func (c *Client) CreateNamespace(ctx context.Context, envKey string, ns *Namespace) (*Namespace, error) {
+	var response namespaceResponse
+	if err := c.do(ctx, http.MethodPost, c.url("environments", envKey, "namespaces"), ns, &response); err != nil {
+		return nil, err
+	}
+	return &response.Namespace, nil
+}
+
+// UpdateNamespace updates a namespace and returns it as stored by the server.
+func (c *Client) UpdateNamespace(ctx context.Context, envKey string, ns *Namespace) (*Namespace, error) {
+	var response namespaceResponse
+	if err := c.do(ctx, http.MethodPut, c.url("environments", envKey, "namespaces"), ns, &response); err != nil {
+		return nil, err
+	}
+	return &response.Namespace, nil
+}
+
+// DeleteNamespace deletes the namespace with the given key.
+func (c *Client) DeleteNamespace(ctx context.Context, envKey, key string) error {
+	return c.do(ctx, http.MethodDelete, c.url("environments", envKey, "namespaces", key), nil, nil)
+}
@@ -0,0 +1,306 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// maxConflictRetries bounds how often UpdateFlag and UpdateSegment re-read a
+// resource after a write was rejected because it was changed concurrently.
+const maxConflictRetries = 5
+
+// resourceResponse is the envelope returned by the resources API for a
+// single resource.
+type resourceResponse struct {
+	Resource struct {
+		NamespaceKey string          `json:"namespaceKey"`
+		Key          string          `json:"key"`
+		Payload      json.RawMessage `json:"payload"`
+	} `json:"resource"`
+	Revision string `json:"revision"`
+}
+
+// resourceListResponse is the envelope returned by the resources API for a
+// page of resources of one type.
+type resourceListResponse struct {
+	Resources []struct {
+		NamespaceKey string          `json:"namespaceKey"`
+		Key          string          `json:"key"`
+		Payload      json.RawMessage `json:"payload"`
+	} `json:"resources"`
+	Revision      string `json:"revision"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// resourceRequest is the body of create and update calls to the resources API.
+// When Revision is set the server rejects the update with a conflict if the
+// namespace has changed since that revision.
+type resourceRequest struct {
+	Key      string      `json:"key"`
+	Payload  interface{} `json:"payload"`
+	Revision string      `json:"revision,omitempty"`
+}
+
+// typedPayload adds the @type discriminator required by the resources API to
+// a payload.
+type typedPayload struct {
+	TypeURL string
+	Payload interface{}
+}
+
+func (p typedPayload) MarshalJSON() ([]byte, error) {
+	body, err := json.Marshal(p.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+
+	typeURL, err := json.Marshal(p.TypeURL)
+	if err != nil {
+		return nil, err
+	}
+	fields["@type"] = typeURL
+
+	return json.Marshal(fields)
+}
+
+func (c *Client) resourcesURL(envKey, nsKey string) string {
+	return c.url("environments", envKey, "namespaces", nsKey, "resources")
+}
+
+// getResource reads a single resource and decodes its payload into out. The
+// namespace revision is returned alongside.
+func (c *Client) getResource(ctx context.Context, envKey, nsKey, typeURL, key string, out interface{}) (string, error) {
+	var response resourceResponse
+	url := c.url("environments", envKey, "namespaces", nsKey, "resources", typeURL, key)
+	if err := c.do(ctx, http.MethodGet, url, nil, &response); err != nil {
+		return "", err
+	}
+
+	if err := json.Unmarshal(response.Resource.Payload, out); err != nil {
+		return "", fmt.Errorf("unable to parse %s payload: %w", typeURL, err)
+	}
+
+	return response.Revision, nil
+}
+
+// listResources reads every resource of the given type in a namespace,
+// following the next page tokens of the server, and passes the payload of
+// each to decode.
+func (c *Client) listResources(ctx context.Context, envKey, nsKey, typeURL string, decode func(payload json.RawMessage) error) error {
+	listURL := c.url("environments", envKey, "namespaces", nsKey, "resources", typeURL)
+	return paginate(listURL, func(pageURL string) (string, error) {
+		var response resourceListResponse
+		if err := c.do(ctx, http.MethodGet, pageURL, nil, &response); err != nil {
+			return "", err
+		}
+
+		for _, r := range response.Resources {
+			if err := decode(r.Payload); err != nil {
+				return "", fmt.Errorf("unable to parse %s payload of %q: %w", typeURL, r.Key, err)
+			}
+		}
+		return response.NextPageToken, nil
+	})
+}
+
+// writeResource creates (POST) or replaces (PUT) a resource and decodes the
+// stored payload into out. A non-empty revision is sent for optimistic
+// concurrency control.
+func (c *Client) writeResource(ctx context.Context, method, envKey, nsKey, typeURL, key, revision string, payload, out interface{}) (string, error) {
+	req := resourceRequest{
+		Key:      key,
+		Payload:  typedPayload{TypeURL: typeURL, Payload: payload},
+		Revision: revision,
+	}
+
+	var response resourceResponse
+	if err := c.do(ctx, method, c.resourcesURL(envKey, nsKey), req, &response); err != nil {
+		return "", err
+	}
+
+	stored := response.Resource.Payload
+	if len(stored) == 0 {
+		// Fall back to what was sent when the server does not echo the payload.
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return "", err
+		}
+		stored = body
+	}
+
+	if err := json.Unmarshal(stored, out); err != nil {
+		return "", fmt.Errorf("unable to parse %s payload: %w", typeURL, err)
+	}
+
+	return response.Revision, nil
+}
+
+// DeleteResource deletes the resource of the given type and key.
+func (c *Client) DeleteResource(ctx context.Context, envKey, nsKey, typeURL, key string) error {
+	url := c.url("environments", envKey, "namespaces", nsKey, "resources", typeURL, key)
+	return c.do(ctx, http.MethodDelete, url, nil, nil)
+}
+
+// GetFlag returns the flag with the given key.
+func (c *Client) GetFlag(ctx context.Context, envKey, nsKey, key string) (*Flag, error) {
+	var flag Flag
+	revision, err := c.getResource(ctx, envKey, nsKey, TypeFlag, key, &flag)
+	if err != nil {
+		return nil, err
+	}
+	flag.Revision = revision
+	return &flag, nil
+}
+
+// ListFlags returns every flag in a namespace.
+func (c *Client) ListFlags(ctx context.Context, envKey, nsKey string) ([]Flag, error) {
+	var flags []Flag
+	err := c.listResources(ctx, envKey, nsKey, TypeFlag, func(payload json.RawMessage) error {
+		var flag Flag
+		if err := json.Unmarshal(payload, &flag); err != nil {
+			return err
+		}
+		flags = append(flags, flag)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+// CreateFlag creates a flag and returns it as stored by the server.
+func (c *Client) CreateFlag(ctx context.Context, envKey, nsKey string, flag *Flag) (*Flag, error) {
+	return c.writeFlag(ctx, http.MethodPost, envKey, nsKey, flag)
+}
+
+// PutFlag replaces the payload of an existing flag and returns it as stored
+// by the server. If flag.Revision is set and the flag was changed since, an
+// error matching ErrConflict is returned.
+func (c *Client) PutFlag(ctx context.Context, envKey, nsKey string, flag *Flag) (*Flag, error) {
+	return c.writeFlag(ctx, http.MethodPut, envKey, nsKey, flag)
+}
+
+func (c *Client) writeFlag(ctx context.Context, method, envKey, nsKey string, flag *Flag) (*Flag, error) {
+	var stored Flag
+	revision, err := c.writeResource(ctx, method, envKey, nsKey, TypeFlag, flag.Key, flag.Revision, flag, &stored)
+	if err != nil {
+		return nil, err
+	}
+	stored.Revision = revision
+	return &stored, nil
+}
+
+// UpdateFlag reads the flag, applies modify to it and writes it back at the
+// revision it was read at. If the flag is changed by someone else in between,
+// the cycle is retried up to maxConflictRetries times before an error matching
+// ErrConflict is returned. Errors returned by modify abort the update and are
+// returned as is.
+func (c *Client) UpdateFlag(ctx context.Context, envKey, nsKey, key string, modify func(*Flag) error) (*Flag, error) {
+	var err error
+	for attempt := 0; attempt <= maxConflictRetries; attempt++ {
+		var flag *Flag
+		flag, err = c.GetFlag(ctx, envKey, nsKey, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := modify(flag); err != nil {
+			return nil, err
+		}
+
+		var stored *Flag
+		stored, err = c.PutFlag(ctx, envKey, nsKey, flag)
+		if !errors.Is(err, ErrConflict) {
+			return stored, err
+		}
+	}
+
+	return nil, fmt.Errorf("flag %q in namespace %q kept changing, gave up after %d attempts: %w", key, nsKey, maxConflictRetries+1, err)
+}
+
+// GetSegment returns the segment with the given key.
+func (c *Client) GetSegment(ctx context.Context, envKey, nsKey, key string) (*Segment, error) {
+	var segment Segment
+	revision, err := c.getResource(ctx, envKey, nsKey, TypeSegment, key, &segment)
+	if err != nil {
+		return nil, err
+	}
+	segment.Revision = revision
+	return &segment, nil
+}
+
+// ListSegments returns every segment in a namespace.
+func (c *Client) ListSegments(ctx context.Context, envKey, nsKey string) ([]Segment, error) {
+	var segments []Segment
+	err := c.listResources(ctx, envKey, nsKey, TypeSegment, func(payload json.RawMessage) error {
+		var segment Segment
+		if err := json.Unmarshal(payload, &segment); err != nil {
+			return err
+		}
+		segments = append(segments, segment)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return segments, nil
+}
+
+// CreateSegment creates a segment and returns it as stored by the server.
+func (c *Client) CreateSegment(ctx context.Context, envKey, nsKey string, segment *Segment) (*Segment, error) {
+	return c.writeSegment(ctx, http.MethodPost, envKey, nsKey, segment)
+}
+
+// PutSegment replaces the payload of an existing segment and returns it as
+// stored by the server. If segment.Revision is set and the segment was
+// changed since, an error matching ErrConflict is returned.
+func (c *Client) PutSegment(ctx context.Context, envKey, nsKey string, segment *Segment) (*Segment, error) {
+	return c.writeSegment(ctx, http.MethodPut, envKey, nsKey, segment)
+}
+
+func (c *Client) writeSegment(ctx context.Context, method, envKey, nsKey string, segment *Segment) (*Segment, error) {
+	var stored Segment
+	revision, err := c.writeResource(ctx, method, envKey, nsKey, TypeSegment, segment.Key, segment.Revision, segment, &stored)
+	if err != nil {
+		return nil, err
+	}
+	stored.Revision = revision
+	return &stored, nil
+}
+
+// UpdateSegment reads the segment, applies modify to it and writes it back at
+// the revision it was read at, retrying on conflicts like UpdateFlag.
+func (c *Client) UpdateSegment(ctx context.Context, envKey, nsKey, key string, modify func(*Segment) error) (*Segment, error) {
+	var err error
+	for attempt := 0; attempt <= maxConflictRetries; attempt++ {
+		var segment *Segment
+		segment, err = c.GetSegment(ctx, envKey, nsKey, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := modify(segment); err != nil {
+			return nil, err
+		}
+
+		var stored *Segment
+		stored, err = c.PutSegment(ctx, envKey, nsKey, segment)
+		if !errors.Is(err, ErrConflict) {
+			return stored, err
+		}
+	}
+
+	return nil, fmt.Errorf("segment %q in namespace %q kept changing, gave up after %d attempts: %w", key, nsKey, maxConflictRetries+1, err)
+}
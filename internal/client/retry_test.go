@@ -0,0 +1,225 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first failures requests with the given status before
+// answering successfully, and counts the requests it received.
+func flakyServer(t *testing.T, failures int, status int, header http.Header) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(atomic.AddInt32(&requests, 1)) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(`{"resource":{"key":"my-flag","payload":{"key":"my-flag","name":"My Flag"}},"revision":"1"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func retryingClient(endpoint string, maxRetries int) *Client {
+	return New(Config{
+		Endpoint:     endpoint,
+		MaxRetries:   maxRetries,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: 5 * time.Millisecond,
+	})
+}
+
+func TestClient_Retry(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		status           int
+		failures         int
+		maxRetries       int
+		expectedRequests int32
+		expectErr        bool
+	}{
+		{name: "GET retried on 503", method: http.MethodGet, status: http.StatusServiceUnavailable, failures: 2, maxRetries: 3, expectedRequests: 3},
+		{name: "GET retried on 502", method: http.MethodGet, status: http.StatusBadGateway, failures: 1, maxRetries: 3, expectedRequests: 2},
+		{name: "PUT retried on 504", method: http.MethodPut, status: http.StatusGatewayTimeout, failures: 1, maxRetries: 3, expectedRequests: 2},
+		{name: "DELETE retried on 503", method: http.MethodDelete, status: http.StatusServiceUnavailable, failures: 1, maxRetries: 3, expectedRequests: 2},
+		{name: "POST not retried on 503", method: http.MethodPost, status: http.StatusServiceUnavailable, failures: 1, maxRetries: 3, expectedRequests: 1, expectErr: true},
+		{name: "POST retried on 429", method: http.MethodPost, status: http.StatusTooManyRequests, failures: 2, maxRetries: 3, expectedRequests: 3},
+		{name: "Not retried on 500", method: http.MethodGet, status: http.StatusInternalServerError, failures: 1, maxRetries: 3, expectedRequests: 1, expectErr: true},
+		{name: "Retries exhausted", method: http.MethodGet, status: http.StatusServiceUnavailable, failures: 10, maxRetries: 3, expectedRequests: 4, expectErr: true},
+		{name: "Retries disabled", method: http.MethodGet, status: http.StatusServiceUnavailable, failures: 1, maxRetries: 0, expectedRequests: 1, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := flakyServer(t, tt.failures, tt.status, nil)
+
+			c := retryingClient(server.URL, tt.maxRetries)
+			err := c.do(context.Background(), tt.method, server.URL+"/api/v2/test", map[string]string{"key": "value"}, nil)
+
+			if tt.expectErr {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+					t.Errorf("Expected *APIError with status %d, got %v", tt.status, err)
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+
+			if got := atomic.LoadInt32(requests); got != tt.expectedRequests {
+				t.Errorf("Expected %d requests, got %d", tt.expectedRequests, got)
+			}
+		})
+	}
+}
+
+func TestClient_RetryResendsBody(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := make([]byte, 64)
+		n, _ := r.Body.Read(buf)
+		bodies = append(bodies, string(buf[:n]))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	if err := retryingClient(server.URL, 1).do(context.Background(), http.MethodPut, server.URL, map[string]string{"key": "value"}, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(bodies) != 2 || bodies[0] != bodies[1] || bodies[1] != `{"key":"value"}` {
+		t.Errorf("Expected the same body on every attempt, got %q", bodies)
+	}
+}
+
+func TestClient_RetryConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	endpoint := server.URL
+	server.Close()
+
+	var attempts int32
+	c := retryingClient(endpoint, 2)
+	c.httpClient = &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&attempts, 1)
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+
+	if _, err := c.GetFlag(context.Background(), "default", "ns", "my-flag"); err == nil {
+		t.Fatal("Expected connection error")
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+
+	attempts = 0
+	if _, err := c.CreateFlag(context.Background(), "default", "ns", &Flag{Key: "my-flag"}); err == nil {
+		t.Fatal("Expected connection error")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected POST to be attempted once, got %d", attempts)
+	}
+}
+
+func TestClient_RetryCertificateError(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var attempts int32
+	c := retryingClient(server.URL, 3)
+	c.httpClient = &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&attempts, 1)
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+
+	if _, err := c.ListEnvironments(context.Background()); err == nil {
+		t.Fatal("Expected certificate error")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected certificate errors not to be retried, got %d attempts", attempts)
+	}
+}
+
+func TestClient_RetryAfter(t *testing.T) {
+	header := http.Header{"Retry-After": []string{"1"}}
+	server, requests := flakyServer(t, 1, http.StatusTooManyRequests, header)
+
+	c := New(Config{Endpoint: server.URL, MaxRetries: 1, RetryWaitMin: time.Millisecond, RetryWaitMax: time.Millisecond})
+
+	start := time.Now()
+	if _, err := c.GetFlag(context.Background(), "default", "ns", "my-flag"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Expected Retry-After to be honoured, retried after %s", elapsed)
+	}
+	if *requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", *requests)
+	}
+}
+
+func TestClient_RetryContextCanceled(t *testing.T) {
+	header := http.Header{"Retry-After": []string{"60"}}
+	server, requests := flakyServer(t, 10, http.StatusServiceUnavailable, header)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := retryingClient(server.URL, 5).GetFlag(ctx, "default", "ns", "my-flag")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context deadline error, got %v", err)
+	}
+	if *requests != 1 {
+		t.Errorf("Expected 1 request, got %d", *requests)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt  int
+		header   string
+		expected time.Duration
+	}{
+		{attempt: 0, expected: time.Second},
+		{attempt: 1, expected: 2 * time.Second},
+		{attempt: 2, expected: 4 * time.Second},
+		{attempt: 10, expected: 30 * time.Second},
+		{attempt: 0, header: "7", expected: 7 * time.Second},
+		{attempt: 0, header: "invalid", expected: time.Second},
+		{attempt: 0, header: "Mon, 02 Jan 2006 15:04:05 GMT", expected: 0},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.header != "" {
+			resp.Header.Set("Retry-After", tt.header)
+		}
+
+		if got := backoff(time.Second, 30*time.Second, tt.attempt, resp); got != tt.expected {
+			t.Errorf("backoff(attempt %d, Retry-After %q) = %s, expected %s", tt.attempt, tt.header, got, tt.expected)
+		}
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
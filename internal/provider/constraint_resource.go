@@ -4,24 +4,28 @@
 package provider
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
 )
 
 var _ resource.Resource = &ConstraintResource{}
 var _ resource.ResourceWithImportState = &ConstraintResource{}
+var _ resource.ResourceWithModifyPlan = &ConstraintResource{}
+var _ resource.ResourceWithUpgradeState = &ConstraintResource{}
+var _ resource.ResourceWithValidateConfig = &ConstraintResource{}
 
 func NewConstraintResource() resource.Resource {
 	return &ConstraintResource{}
@@ -40,6 +44,7 @@ type ConstraintResourceModel struct {
 	Operator       types.String `tfsdk:"operator"`
 	Value          types.String `tfsdk:"value"`
 	Description    types.String `tfsdk:"description"`
+	ID             types.String `tfsdk:"id"`
 }
 
 func (r *ConstraintResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -49,17 +54,23 @@ func (r *ConstraintResource) Metadata(ctx context.Context, req resource.Metadata
 func (r *ConstraintResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "Flipt constraint resource (belongs to a segment)",
+		Version:             1,
 
 		Attributes: map[string]schema.Attribute{
 			"namespace_key": schema.StringAttribute{
-				MarkdownDescription: "Namespace key where the segment belongs",
-				Required:            true,
+				MarkdownDescription: "Namespace key where the segment belongs. Defaults to the provider's `default_namespace_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
 				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
 			"environment_key": schema.StringAttribute{
-				MarkdownDescription: "Environment key (defaults to 'default' if not specified)",
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
 				Optional:            true,
 				Computed:            true,
 				PlanModifiers: []planmodifier.String{
@@ -70,33 +81,52 @@ func (r *ConstraintResource) Schema(ctx context.Context, req resource.SchemaRequ
 			"segment_key": schema.StringAttribute{
 				MarkdownDescription: "Segment key that this constraint belongs to",
 				Required:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
 			"property": schema.StringAttribute{
-				MarkdownDescription: "Property name for the constraint (unique identifier)",
+				MarkdownDescription: "Property name for the constraint",
 				Required:            true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
 			"type": schema.StringAttribute{
-				MarkdownDescription: "Constraint type (e.g., STRING_COMPARISON_TYPE)",
+				MarkdownDescription: "Constraint type: STRING_COMPARISON_TYPE, NUMBER_COMPARISON_TYPE, BOOLEAN_COMPARISON_TYPE, DATETIME_COMPARISON_TYPE or ENTITY_ID_COMPARISON_TYPE",
 				Required:            true,
+				Validators: []validator.String{
+					constraintTypeValidator(),
+				},
 			},
 			"operator": schema.StringAttribute{
-				MarkdownDescription: "Comparison operator (e.g., eq, suffix, prefix)",
+				MarkdownDescription: "Comparison operator. The operators available depend on the type (e.g., eq, neq, prefix, suffix, isoneof, lt, gte, present, empty, true)",
 				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"value": schema.StringAttribute{
-				MarkdownDescription: "Value to compare against",
-				Required:            true,
+				MarkdownDescription: "Value to compare against. Omitted for the operators that take no value: empty, notempty, present, notpresent, true and false. Numbers and datetimes (RFC 3339) must parse, and isoneof/isnotoneof take a JSON array",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"description": schema.StringAttribute{
 				MarkdownDescription: "Description of the constraint",
 				Optional:            true,
 			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Identifier of the constraint within its segment, composed of the property, operator and value with `%` and `/` escaped as `%25` and `%2F`",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 		},
 	}
 }
@@ -118,6 +148,20 @@ func (r *ConstraintResource) Configure(ctx context.Context, req resource.Configu
 	r.config = providerConfig
 }
 
+func (r *ConstraintResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data ConstraintResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateConstraint(&resp.Diagnostics, path.Empty(), data.Type, data.Operator, data.Value)
+}
+
+func (r *ConstraintResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultKeys(ctx, r.config, true, req, resp)
+}
+
 func (r *ConstraintResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data ConstraintResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -125,11 +169,10 @@ func (r *ConstraintResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Creating constraint", map[string]interface{}{
 		"environment_key": envKey,
@@ -138,119 +181,30 @@ func (r *ConstraintResource) Create(ctx context.Context, req resource.CreateRequ
 		"property":        data.Property.ValueString(),
 	})
 
-	// First, get the current segment to read existing constraints
-	segmentURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Segment/%s",
-		r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.SegmentKey.ValueString())
-
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", segmentURL, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read segment, got error: %s", err))
-		return
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read segment, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var segmentResponse struct {
-		Resource struct {
-			Payload struct {
-				Key         string                   `json:"key"`
-				Name        string                   `json:"name"`
-				Description string                   `json:"description"`
-				MatchType   string                   `json:"matchType"`
-				Constraints []map[string]interface{} `json:"constraints"`
-			} `json:"payload"`
-		} `json:"resource"`
-	}
-
-	body, _ := io.ReadAll(httpResp.Body)
-	if err := json.Unmarshal(body, &segmentResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse segment response: %s", err))
-		return
-	}
-
-	// Build new constraint
-	newConstraint := map[string]interface{}{
-		"property": data.Property.ValueString(),
-		"type":     data.Type.ValueString(),
-		"operator": data.Operator.ValueString(),
-		"value":    data.Value.ValueString(),
-	}
-
-	if !data.Description.IsNull() && !data.Description.IsUnknown() {
-		newConstraint["description"] = data.Description.ValueString()
-	} else {
-		newConstraint["description"] = ""
-	}
-
-	// Add new constraint to existing constraints
-	existingConstraints := segmentResponse.Resource.Payload.Constraints
-	if existingConstraints == nil {
-		existingConstraints = []map[string]interface{}{}
-	}
-	allConstraints := append(existingConstraints, newConstraint)
-
-	// Update the segment with all constraints (including the new one)
-	segmentPayload := map[string]interface{}{
-		"@type":       "flipt.core.Segment",
-		"key":         segmentResponse.Resource.Payload.Key,
-		"name":        segmentResponse.Resource.Payload.Name,
-		"description": segmentResponse.Resource.Payload.Description,
-		"matchType":   segmentResponse.Resource.Payload.MatchType,
-		"constraints": allConstraints,
-	}
-
-	updateReq := map[string]interface{}{
-		"key":     data.SegmentKey.ValueString(),
-		"payload": segmentPayload,
-	}
-
-	reqBody, err := json.Marshal(updateReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Serialization Error", fmt.Sprintf("Unable to marshal request: %s", err))
-		return
-	}
-
-	updateURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources", r.config.Endpoint, envKey, data.NamespaceKey.ValueString())
-	httpReq, err = http.NewRequestWithContext(ctx, "PUT", updateURL, bytes.NewReader(reqBody))
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err = r.config.HTTPClient.Do(httpReq)
+	// Serialize with other changes to the segment's constraints
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeSegment, data.SegmentKey.ValueString())
+	defer unlock()
+
+	// Add new constraint to the existing constraints of the segment. A
+	// constraint with the same identity is replaced so that retrying after a
+	// lost response does not add it twice.
+	newConstraint := constraintFromModel(data)
+	id := constraintID(newConstraint)
+	_, err := r.config.Client.UpdateSegment(ctx, envKey, data.NamespaceKey.ValueString(), data.SegmentKey.ValueString(), func(segment *client.Segment) error {
+		if i := findConstraint(segment.Constraints, id); i >= 0 {
+			segment.Constraints[i] = newConstraint
+			return nil
+		}
+		segment.Constraints = append(segment.Constraints, newConstraint)
+		return nil
+	})
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create constraint, got error: %s", err))
+		addParentWriteError(&resp.Diagnostics, "create constraint", "segment", data.SegmentKey.ValueString(), err)
 		return
 	}
-	defer httpResp.Body.Close()
 
-	body, _ = io.ReadAll(httpResp.Body)
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to create constraint, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	// Parse response to confirm constraint was created
-	if err := json.Unmarshal(body, &segmentResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s", err))
-		return
-	}
+	data.ID = types.StringValue(id)
 
-	// State is already set from plan
 	tflog.Trace(ctx, "created a constraint resource")
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -262,11 +216,10 @@ func (r *ConstraintResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Reading constraint", map[string]interface{}{
 		"environment_key": envKey,
@@ -276,81 +229,53 @@ func (r *ConstraintResource) Read(ctx context.Context, req resource.ReadRequest,
 	})
 
 	// Get the segment to read its constraints
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Segment/%s",
-		r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.SegmentKey.ValueString())
-
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	segment, err := r.config.Client.GetSegment(ctx, envKey, data.NamespaceKey.ValueString(), data.SegmentKey.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
+		handleReadError(ctx, resp, "segment", data.SegmentKey.ValueString(), err)
 		return
 	}
 
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
-	if err != nil {
-		resp.State.RemoveResource(ctx)
-		return
+	// Find the constraint by its identity. Constraints imported by property
+	// alone are matched by property as long as it is unambiguous.
+	i := -1
+	if !data.ID.IsNull() {
+		i = findConstraint(segment.Constraints, data.ID.ValueString())
+	} else {
+		for j, c := range segment.Constraints {
+			if c.Property != data.Property.ValueString() {
+				continue
+			}
+			if i >= 0 {
+				resp.Diagnostics.AddError("Ambiguous Constraint",
+					fmt.Sprintf("Segment '%s' has several constraints on property '%s'. Import the constraint by "+
+						"environment_key/namespace_key/segment_key/property/operator/value instead.", data.SegmentKey.ValueString(), data.Property.ValueString()))
+				return
+			}
+			i = j
+		}
 	}
-	defer httpResp.Body.Close()
 
-	if httpResp.StatusCode == http.StatusNotFound {
+	if i < 0 {
 		resp.State.RemoveResource(ctx)
 		return
 	}
+	c := segment.Constraints[i]
 
-	body, err := io.ReadAll(httpResp.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Read Error", fmt.Sprintf("Unable to read response: %s", err))
-		return
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read segment, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var segmentResponse struct {
-		Resource struct {
-			Payload struct {
-				Constraints []struct {
-					Property    string `json:"property"`
-					Type        string `json:"type"`
-					Operator    string `json:"operator"`
-					Value       string `json:"value"`
-					Description string `json:"description"`
-				} `json:"constraints"`
-			} `json:"payload"`
-		} `json:"resource"`
-	}
-
-	if err := json.Unmarshal(body, &segmentResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s", err))
-		return
+	data.Type = types.StringValue(c.Type)
+	data.Operator = types.StringValue(c.Operator)
+	if c.Value != "" {
+		data.Value = types.StringValue(c.Value)
+	} else {
+		data.Value = types.StringNull()
 	}
 
-	// Find the constraint by property
-	var found bool
-	for _, c := range segmentResponse.Resource.Payload.Constraints {
-		if c.Property == data.Property.ValueString() {
-			found = true
-
-			data.Type = types.StringValue(c.Type)
-			data.Operator = types.StringValue(c.Operator)
-			data.Value = types.StringValue(c.Value)
-
-			if c.Description != "" {
-				data.Description = types.StringValue(c.Description)
-			} else {
-				data.Description = types.StringNull()
-			}
-			break
-		}
+	if c.Description != "" {
+		data.Description = types.StringValue(c.Description)
+	} else {
+		data.Description = types.StringNull()
 	}
 
-	if !found {
-		resp.State.RemoveResource(ctx)
-		return
-	}
+	data.ID = types.StringValue(constraintID(c))
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -362,11 +287,10 @@ func (r *ConstraintResource) Update(ctx context.Context, req resource.UpdateRequ
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Updating constraint", map[string]interface{}{
 		"environment_key": envKey,
@@ -375,124 +299,33 @@ func (r *ConstraintResource) Update(ctx context.Context, req resource.UpdateRequ
 		"property":        data.Property.ValueString(),
 	})
 
-	// Get the current segment to read existing constraints
-	segmentURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Segment/%s",
-		r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.SegmentKey.ValueString())
-
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", segmentURL, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read segment, got error: %s", err))
-		return
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read segment, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var segmentResponse struct {
-		Resource struct {
-			Payload struct {
-				Key         string                   `json:"key"`
-				Name        string                   `json:"name"`
-				Description string                   `json:"description"`
-				MatchType   string                   `json:"matchType"`
-				Constraints []map[string]interface{} `json:"constraints"`
-			} `json:"payload"`
-		} `json:"resource"`
-	}
-
-	body, _ := io.ReadAll(httpResp.Body)
-	if err := json.Unmarshal(body, &segmentResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse segment response: %s", err))
-		return
-	}
-
-	// Find and update the constraint in the constraints array
-	var found bool
-	existingConstraints := segmentResponse.Resource.Payload.Constraints
-	if existingConstraints == nil {
-		existingConstraints = []map[string]interface{}{}
-	}
-
-	for i, c := range existingConstraints {
-		if prop, ok := c["property"].(string); ok && prop == data.Property.ValueString() {
-			found = true
-			// Update the constraint
-			existingConstraints[i] = map[string]interface{}{
-				"property": data.Property.ValueString(),
-				"type":     data.Type.ValueString(),
-				"operator": data.Operator.ValueString(),
-				"value":    data.Value.ValueString(),
-			}
-
-			if !data.Description.IsNull() && !data.Description.IsUnknown() {
-				existingConstraints[i]["description"] = data.Description.ValueString()
-			} else {
-				existingConstraints[i]["description"] = ""
-			}
-			break
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeSegment, data.SegmentKey.ValueString())
+	defer unlock()
+
+	// Find and update the constraint in the constraints array. Changes to the
+	// property, operator or value replace the resource, so its identity is
+	// the same before and after the update.
+	constraint := constraintFromModel(data)
+	id := constraintID(constraint)
+	_, err := r.config.Client.UpdateSegment(ctx, envKey, data.NamespaceKey.ValueString(), data.SegmentKey.ValueString(), func(segment *client.Segment) error {
+		i := findConstraint(segment.Constraints, id)
+		if i < 0 {
+			return errNotInParent
 		}
-	}
-
-	if !found {
-		resp.Diagnostics.AddError("Not Found", fmt.Sprintf("Constraint with property %s not found in segment", data.Property.ValueString()))
-		return
-	}
-
-	// Update the segment with all constraints (including the modified one)
-	segmentPayload := map[string]interface{}{
-		"@type":       "flipt.core.Segment",
-		"key":         segmentResponse.Resource.Payload.Key,
-		"name":        segmentResponse.Resource.Payload.Name,
-		"description": segmentResponse.Resource.Payload.Description,
-		"matchType":   segmentResponse.Resource.Payload.MatchType,
-		"constraints": existingConstraints,
-	}
-
-	updateReq := map[string]interface{}{
-		"key":     data.SegmentKey.ValueString(),
-		"payload": segmentPayload,
-	}
-
-	reqBody, err := json.Marshal(updateReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Serialization Error", fmt.Sprintf("Unable to marshal request: %s", err))
-		return
-	}
-
-	updateURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources", r.config.Endpoint, envKey, data.NamespaceKey.ValueString())
-	httpReq, err = http.NewRequestWithContext(ctx, "PUT", updateURL, bytes.NewReader(reqBody))
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
+		segment.Constraints[i] = constraint
+		return nil
+	})
+	if errors.Is(err, errNotInParent) {
+		resp.Diagnostics.AddError("Not Found", fmt.Sprintf("Constraint %s not found in segment", id))
 		return
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err = r.config.HTTPClient.Do(httpReq)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update constraint, got error: %s", err))
+		addParentWriteError(&resp.Diagnostics, "update constraint", "segment", data.SegmentKey.ValueString(), err)
 		return
 	}
-	defer httpResp.Body.Close()
 
-	body, _ = io.ReadAll(httpResp.Body)
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to update constraint, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
+	data.ID = types.StringValue(id)
 
-	// State is already set from plan
 	tflog.Trace(ctx, "updated a constraint resource")
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -504,126 +337,177 @@ func (r *ConstraintResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Deleting constraint", map[string]interface{}{
 		"environment_key": envKey,
 		"namespace_key":   data.NamespaceKey.ValueString(),
 		"segment_key":     data.SegmentKey.ValueString(),
 		"property":        data.Property.ValueString(),
+		"id":              data.ID.ValueString(),
 	})
 
-	// Get the current segment to read existing constraints
-	segmentURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Segment/%s",
-		r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.SegmentKey.ValueString())
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeSegment, data.SegmentKey.ValueString())
+	defer unlock()
 
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", segmentURL, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
+	id := constraintID(constraintFromModel(data))
+	_, err := r.config.Client.UpdateSegment(ctx, envKey, data.NamespaceKey.ValueString(), data.SegmentKey.ValueString(), func(segment *client.Segment) error {
+		i := findConstraint(segment.Constraints, id)
+		if i < 0 {
+			return errNotInParent
+		}
 
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
+		// Remove the constraint from the constraints array, leaving other
+		// constraints on the same property in place
+		segment.Constraints = append(segment.Constraints[:i], segment.Constraints[i+1:]...)
+		return nil
+	})
 	if err != nil {
-		// If segment doesn't exist, constraint is already gone
-		return
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode == http.StatusNotFound {
-		// Segment doesn't exist, constraint is already gone
-		return
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read segment, status: %d, body: %s", httpResp.StatusCode, string(body)))
+		if errors.Is(err, client.ErrNotFound) || errors.Is(err, errNotInParent) {
+			// Segment or constraint doesn't exist, constraint is already gone
+			return
+		}
+		addParentWriteError(&resp.Diagnostics, "delete constraint", "segment", data.SegmentKey.ValueString(), err)
 		return
 	}
 
-	var segmentResponse struct {
-		Resource struct {
-			Payload struct {
-				Key         string                   `json:"key"`
-				Name        string                   `json:"name"`
-				Description string                   `json:"description"`
-				MatchType   string                   `json:"matchType"`
-				Constraints []map[string]interface{} `json:"constraints"`
-			} `json:"payload"`
-		} `json:"resource"`
-	}
+	tflog.Trace(ctx, "deleted a constraint resource")
+}
 
-	body, _ := io.ReadAll(httpResp.Body)
-	if err := json.Unmarshal(body, &segmentResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse segment response: %s", err))
-		return
+// ImportState imports a constraint by an ID of the form
+// environment_key/namespace_key/segment_key/property/operator/value, where
+// the value is the remainder of the ID and may contain slashes. The property,
+// operator and value are escaped like in the constraint ID, so a "/" in the
+// property is written as %2F and a "%" as %25. The short form
+// environment_key/namespace_key/segment_key/property is accepted when the
+// segment has a single constraint on the property.
+func (r *ConstraintResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	var parts []string
+	var ok bool
+	if strings.Count(req.ID, "/") >= 5 {
+		parts, ok = parseImportIDRest(req, resp, "environment_key", "namespace_key", "segment_key", "property", "operator", "value")
+	} else {
+		parts, ok = parseImportID(req, resp, "environment_key", "namespace_key", "segment_key", "property")
 	}
-
-	// Remove the constraint from the constraints array
-	existingConstraints := segmentResponse.Resource.Payload.Constraints
-	if existingConstraints == nil {
-		// No constraints, already deleted
+	if !ok {
 		return
 	}
 
-	var updatedConstraints []map[string]interface{}
-	for _, c := range existingConstraints {
-		if prop, ok := c["property"].(string); ok && prop != data.Property.ValueString() {
-			updatedConstraints = append(updatedConstraints, c)
+	// The property, operator and value may be escaped like in the ID of the
+	// constraint
+	for i := 3; i < len(parts); i++ {
+		part, err := url.PathUnescape(parts[i])
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid Import ID",
+				fmt.Sprintf("Unable to unescape %q in import ID %q: %s. Write %%25 for a %% and %%2F for a / in the property or value.", parts[i], req.ID, err))
+			return
 		}
+		parts[i] = part
 	}
 
-	// Update the segment without the deleted constraint
-	segmentPayload := map[string]interface{}{
-		"@type":       "flipt.core.Segment",
-		"key":         segmentResponse.Resource.Payload.Key,
-		"name":        segmentResponse.Resource.Payload.Name,
-		"description": segmentResponse.Resource.Payload.Description,
-		"matchType":   segmentResponse.Resource.Payload.MatchType,
-		"constraints": updatedConstraints,
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("environment_key"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("namespace_key"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("segment_key"), parts[2])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("property"), parts[3])...)
+	if len(parts) == 6 {
+		constraint := client.Constraint{Property: parts[3], Operator: parts[4], Value: parts[5]}
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("operator"), parts[4])...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("value"), parts[5])...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), constraintID(constraint))...)
 	}
+}
 
-	updateReq := map[string]interface{}{
-		"key":     data.SegmentKey.ValueString(),
-		"payload": segmentPayload,
+// UpgradeState migrates constraints stored before the computed id was added,
+// when the property alone identified a constraint.
+func (r *ConstraintResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: &schema.Schema{
+				Attributes: map[string]schema.Attribute{
+					"namespace_key":   schema.StringAttribute{Optional: true, Computed: true},
+					"environment_key": schema.StringAttribute{Optional: true, Computed: true},
+					"segment_key":     schema.StringAttribute{Required: true},
+					"property":        schema.StringAttribute{Required: true},
+					"type":            schema.StringAttribute{Required: true},
+					"operator":        schema.StringAttribute{Required: true},
+					"value":           schema.StringAttribute{Required: true},
+					"description":     schema.StringAttribute{Optional: true},
+				},
+			},
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var prior constraintResourceModelV0
+				resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				data := ConstraintResourceModel{
+					NamespaceKey:   prior.NamespaceKey,
+					EnvironmentKey: prior.EnvironmentKey,
+					SegmentKey:     prior.SegmentKey,
+					Property:       prior.Property,
+					Type:           prior.Type,
+					Operator:       prior.Operator,
+					Value:          prior.Value,
+					Description:    prior.Description,
+				}
+				data.ID = types.StringValue(constraintID(constraintFromModel(data)))
+
+				resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+			},
+		},
 	}
+}
 
-	reqBody, err := json.Marshal(updateReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Serialization Error", fmt.Sprintf("Unable to marshal request: %s", err))
-		return
-	}
+// constraintResourceModelV0 is the state of a constraint in schema version 0.
+type constraintResourceModelV0 struct {
+	NamespaceKey   types.String `tfsdk:"namespace_key"`
+	EnvironmentKey types.String `tfsdk:"environment_key"`
+	SegmentKey     types.String `tfsdk:"segment_key"`
+	Property       types.String `tfsdk:"property"`
+	Type           types.String `tfsdk:"type"`
+	Operator       types.String `tfsdk:"operator"`
+	Value          types.String `tfsdk:"value"`
+	Description    types.String `tfsdk:"description"`
+}
 
-	updateURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources", r.config.Endpoint, envKey, data.NamespaceKey.ValueString())
-	httpReq, err = http.NewRequestWithContext(ctx, "PUT", updateURL, bytes.NewReader(reqBody))
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
+// constraintIDEscaper escapes the parts of a constraint ID, so that a "/" in
+// a property or value is not taken for the separator.
+var constraintIDEscaper = strings.NewReplacer("%", "%25", "/", "%2F")
 
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err = r.config.HTTPClient.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete constraint, got error: %s", err))
-		return
+// constraintID returns the identity of a constraint within its segment.
+// Several constraints may share a property, but not property, operator and
+// value.
+func constraintID(c client.Constraint) string {
+	return constraintIDEscaper.Replace(c.Property) + "/" + constraintIDEscaper.Replace(c.Operator) + "/" + constraintIDEscaper.Replace(c.Value)
+}
+
+// findConstraint returns the index of the constraint with the given
+// identity, or -1 if there is none.
+func findConstraint(constraints []client.Constraint, id string) int {
+	for i, c := range constraints {
+		if constraintID(c) == id {
+			return i
+		}
 	}
-	defer httpResp.Body.Close()
+	return -1
+}
 
-	body, _ = io.ReadAll(httpResp.Body)
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to delete constraint, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
+// constraintFromModel builds the constraint payload from the resource model.
+func constraintFromModel(data ConstraintResourceModel) client.Constraint {
+	constraint := client.Constraint{
+		Property: data.Property.ValueString(),
+		Type:     data.Type.ValueString(),
+		Operator: data.Operator.ValueString(),
+		Value:    data.Value.ValueString(),
 	}
 
-	tflog.Trace(ctx, "deleted a constraint resource")
-}
+	if !data.Description.IsNull() && !data.Description.IsUnknown() {
+		constraint.Description = data.Description.ValueString()
+	}
 
-func (r *ConstraintResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	return constraint
 }
@@ -4,12 +4,17 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"terraform-provider-flipt/internal/client"
 )
 
 func TestAccConstraintResource(t *testing.T) {
@@ -28,8 +33,16 @@ func TestAccConstraintResource(t *testing.T) {
 					resource.TestCheckResourceAttr("flipt_constraint.test", "type", "STRING_COMPARISON_TYPE"),
 					resource.TestCheckResourceAttr("flipt_constraint.test", "operator", "suffix"),
 					resource.TestCheckResourceAttr("flipt_constraint.test", "value", "@test.com"),
+					resource.TestCheckResourceAttr("flipt_constraint.test", "id", "email/suffix/@test.com"),
 				),
 			},
+			// ImportState testing
+			{
+				ResourceName:      "flipt_constraint.test",
+				ImportState:       true,
+				ImportStateId:     "default/test-namespace/test-segment/email",
+				ImportStateVerify: true,
+			},
 			// Update and Read testing
 			{
 				Config: testAccConstraintResourceConfig("default", "test-namespace", "test-segment", "email", "STRING_COMPARISON_TYPE", "suffix", "@updated.com"),
@@ -135,3 +148,191 @@ func TestConstraintResourceHTTP(t *testing.T) {
 		t.Fatal("Expected server URL to be set")
 	}
 }
+
+func TestAccConstraintResourceSameProperty(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConstraintResourceSamePropertyConfig("65"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_constraint.min_age", "id", "age/gte/18"),
+					resource.TestCheckResourceAttr("flipt_constraint.max_age", "id", "age/lte/65"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "flipt_constraint.max_age",
+				ImportState:       true,
+				ImportStateId:     "default/test-same-property-ns/adults/age/lte/65",
+				ImportStateVerify: true,
+			},
+			// Replacing one constraint leaves the other in place
+			{
+				Config: testAccConstraintResourceSamePropertyConfig("70"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_constraint.min_age", "id", "age/gte/18"),
+					resource.TestCheckResourceAttr("flipt_constraint.max_age", "id", "age/lte/70"),
+				),
+			},
+		},
+	})
+}
+
+func testAccConstraintResourceSamePropertyConfig(maxAge string) string {
+	return fmt.Sprintf(`
+provider "flipt" {
+  endpoint = %[1]q
+}
+
+resource "flipt_namespace" "test" {
+  key  = "test-same-property-ns"
+  name = "Test Namespace"
+}
+
+resource "flipt_segment" "test" {
+  namespace_key = flipt_namespace.test.key
+  key           = "adults"
+  name          = "Adults"
+  match_type    = "ALL_MATCH_TYPE"
+}
+
+resource "flipt_constraint" "min_age" {
+  namespace_key = flipt_namespace.test.key
+  segment_key   = flipt_segment.test.key
+  property      = "age"
+  type          = "NUMBER_COMPARISON_TYPE"
+  operator      = "gte"
+  value         = "18"
+}
+
+resource "flipt_constraint" "max_age" {
+  namespace_key = flipt_namespace.test.key
+  segment_key   = flipt_segment.test.key
+  property      = "age"
+  type          = "NUMBER_COMPARISON_TYPE"
+  operator      = "lte"
+  value         = %[2]q
+}
+`, getTestFliptEndpoint(), maxAge)
+}
+
+// ageConstraint returns the model of a constraint on the age property of the
+// adults segment.
+func ageConstraint(operator, value string) *ConstraintResourceModel {
+	return &ConstraintResourceModel{
+		NamespaceKey:   types.StringValue("default"),
+		EnvironmentKey: types.StringValue("default"),
+		SegmentKey:     types.StringValue("adults"),
+		Property:       types.StringValue("age"),
+		Type:           types.StringValue("NUMBER_COMPARISON_TYPE"),
+		Operator:       types.StringValue(operator),
+		Value:          types.StringValue(value),
+		Description:    types.StringNull(),
+		ID:             types.StringUnknown(),
+	}
+}
+
+// storedConstraints returns the constraints of the adults segment on the fake
+// server.
+func storedConstraints(t *testing.T, fake *fakeFlipt) []client.Constraint {
+	t.Helper()
+
+	raw, err := json.Marshal(fake.get("default", "default", client.TypeSegment, "adults")["constraints"])
+	if err != nil {
+		t.Fatalf("Unable to marshal constraints: %v", err)
+	}
+	var constraints []client.Constraint
+	if err := json.Unmarshal(raw, &constraints); err != nil {
+		t.Fatalf("Unable to unmarshal constraints: %v", err)
+	}
+	return constraints
+}
+
+func TestConstraintResourceSameProperty(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "default", client.TypeSegment, "adults", map[string]interface{}{
+		"name":      "Adults",
+		"matchType": "ALL_MATCH_TYPE",
+	})
+
+	r := NewConstraintResource()
+	configureResource(t, r, fake.config())
+
+	for _, c := range []*ConstraintResourceModel{ageConstraint("gte", "18"), ageConstraint("lte", "65")} {
+		if resp, _ := createResource(t, r, c); resp.Diagnostics.HasError() {
+			t.Fatalf("Create failed: %v", resp.Diagnostics)
+		}
+	}
+	if constraints := storedConstraints(t, fake); len(constraints) != 2 {
+		t.Fatalf("Expected 2 constraints, got %+v", constraints)
+	}
+
+	// Updating one constraint leaves the other one alone
+	state := ageConstraint("lte", "65")
+	state.ID = types.StringValue("age/lte/65")
+	plan := ageConstraint("lte", "65")
+	plan.Description = types.StringValue("Upper bound")
+	plan.ID = state.ID
+	if resp, _ := updateResource(t, r, plan, state); resp.Diagnostics.HasError() {
+		t.Fatalf("Update failed: %v", resp.Diagnostics)
+	}
+	constraints := storedConstraints(t, fake)
+	if constraints[0].Description != "" || constraints[1].Description != "Upper bound" {
+		t.Fatalf("Unexpected constraints after update: %+v", constraints)
+	}
+
+	// Reading finds each constraint by its identity
+	readResp, readState := readResource(t, r, state)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("Read failed: %v", readResp.Diagnostics)
+	}
+	var data ConstraintResourceModel
+	readState.Get(context.Background(), &data)
+	if data.Operator.ValueString() != "lte" || data.Description.ValueString() != "Upper bound" {
+		t.Errorf("Read the wrong constraint: %s %s", data.Operator, data.Description)
+	}
+
+	if resp := deleteResource(t, r, state); resp.Diagnostics.HasError() {
+		t.Fatalf("Delete failed: %v", resp.Diagnostics)
+	}
+	constraints = storedConstraints(t, fake)
+	if len(constraints) != 1 || constraints[0].Operator != "gte" {
+		t.Fatalf("Unexpected constraints after delete: %+v", constraints)
+	}
+}
+
+func TestConstraintID(t *testing.T) {
+	// Without escaping, both constraints would have the ID a/eq/b/eq/c
+	first := constraintID(client.Constraint{Property: "a", Operator: "eq", Value: "b/eq/c"})
+	second := constraintID(client.Constraint{Property: "a/eq/b", Operator: "eq", Value: "c"})
+
+	if first != "a/eq/b%2Feq%2Fc" || second != "a%2Feq%2Fb/eq/c" {
+		t.Errorf("Unexpected constraint IDs %q and %q", first, second)
+	}
+}
+
+func TestConstraintResourceUpgradeState(t *testing.T) {
+	str := func(v string) tftypes.Value { return tftypes.NewValue(tftypes.String, v) }
+
+	resp, state := upgradeResourceState(t, NewConstraintResource(), 0, map[string]tftypes.Value{
+		"namespace_key":   str("default"),
+		"environment_key": str("default"),
+		"segment_key":     str("adults"),
+		"property":        str("age"),
+		"type":            str("NUMBER_COMPARISON_TYPE"),
+		"operator":        str("gte"),
+		"value":           str("18"),
+		"description":     tftypes.NewValue(tftypes.String, nil),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Upgrade failed: %v", resp.Diagnostics)
+	}
+
+	var data ConstraintResourceModel
+	state.Get(context.Background(), &data)
+	if data.ID.ValueString() != "age/gte/18" || data.Property.ValueString() != "age" {
+		t.Errorf("Unexpected upgraded state: %+v", data)
+	}
+}
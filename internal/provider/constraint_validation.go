@@ -0,0 +1,123 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// constraintOperators lists the operators Flipt accepts for each constraint
+// type.
+var constraintOperators = map[string][]string{
+	"STRING_COMPARISON_TYPE":    {"eq", "neq", "empty", "notempty", "prefix", "suffix", "isoneof", "isnotoneof", "contains", "notcontains"},
+	"NUMBER_COMPARISON_TYPE":    {"eq", "neq", "present", "notpresent", "lt", "lte", "gt", "gte", "isoneof", "isnotoneof"},
+	"BOOLEAN_COMPARISON_TYPE":   {"true", "false", "present", "notpresent"},
+	"DATETIME_COMPARISON_TYPE":  {"eq", "neq", "present", "notpresent", "lt", "lte", "gt", "gte"},
+	"ENTITY_ID_COMPARISON_TYPE": {"eq", "neq", "isoneof", "isnotoneof"},
+}
+
+// noValueOperators are the operators that do not compare against a value.
+var noValueOperators = map[string]bool{
+	"empty":      true,
+	"notempty":   true,
+	"present":    true,
+	"notpresent": true,
+	"true":       true,
+	"false":      true,
+}
+
+// constraintTypeValidator validates the type of a constraint.
+func constraintTypeValidator() validator.String {
+	return stringvalidator.OneOf(slices.Sorted(maps.Keys(constraintOperators))...)
+}
+
+// validateConstraint checks the operator and value of a constraint against
+// what Flipt accepts for its type, reporting errors on the attributes below
+// p. Unknown values are skipped until they are known.
+func validateConstraint(diags *diag.Diagnostics, p path.Path, typ, operator, value types.String) {
+	operators, ok := constraintOperators[typ.ValueString()]
+	if !ok {
+		// Unknown types are reported by constraintTypeValidator
+		return
+	}
+
+	if operator.IsNull() || operator.IsUnknown() {
+		return
+	}
+	op := operator.ValueString()
+	if !slices.Contains(operators, op) {
+		diags.AddAttributeError(p.AtName("operator"), "Invalid Constraint Operator",
+			fmt.Sprintf("Operator of a %s constraint must be one of %s, got: %q", typ.ValueString(), strings.Join(operators, ", "), op))
+		return
+	}
+
+	if value.IsUnknown() {
+		return
+	}
+	v := value.ValueString()
+
+	if noValueOperators[op] {
+		if v != "" {
+			diags.AddAttributeError(p.AtName("value"), "Unexpected Constraint Value",
+				fmt.Sprintf("The %s operator does not compare against a value, remove the value %q", op, v))
+		}
+		return
+	}
+	if v == "" {
+		diags.AddAttributeError(p.AtName("value"), "Missing Constraint Value",
+			fmt.Sprintf("The %s operator of a %s constraint needs a value", op, typ.ValueString()))
+		return
+	}
+
+	if err := validateConstraintValue(typ.ValueString(), op, v); err != nil {
+		diags.AddAttributeError(p.AtName("value"), "Invalid Constraint Value",
+			fmt.Sprintf("Invalid value for the %s operator of a %s constraint: %s", op, typ.ValueString(), err))
+	}
+}
+
+// validateConstraintValue checks that a value parses as the constraint type
+// expects.
+func validateConstraintValue(typ, operator, value string) error {
+	if operator == "isoneof" || operator == "isnotoneof" {
+		if typ == "NUMBER_COMPARISON_TYPE" {
+			var numbers []float64
+			if err := json.Unmarshal([]byte(value), &numbers); err != nil {
+				return fmt.Errorf("expected a JSON array of numbers, such as [1, 2], got: %q", value)
+			}
+			return nil
+		}
+
+		var values []string
+		if err := json.Unmarshal([]byte(value), &values); err != nil {
+			return fmt.Errorf(`expected a JSON array of strings, such as ["a", "b"], got: %q`, value)
+		}
+		return nil
+	}
+
+	switch typ {
+	case "NUMBER_COMPARISON_TYPE":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("expected a number, got: %q", value)
+		}
+	case "DATETIME_COMPARISON_TYPE":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			if _, err := time.Parse(time.DateOnly, value); err != nil {
+				return fmt.Errorf("expected an RFC 3339 timestamp, such as 2006-01-02T15:04:05Z, or a date, such as 2006-01-02, got: %q", value)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"terraform-provider-flipt/internal/client"
+)
+
+func TestConstraintResourceValidateConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		typ      string
+		operator string
+		value    types.String
+		summary  string
+		path     path.Path
+	}{
+		{name: "string", typ: "STRING_COMPARISON_TYPE", operator: "suffix", value: types.StringValue("@example.com")},
+		{name: "string one of", typ: "STRING_COMPARISON_TYPE", operator: "isoneof", value: types.StringValue(`["a", "b"]`)},
+		{name: "number", typ: "NUMBER_COMPARISON_TYPE", operator: "gte", value: types.StringValue("18.5")},
+		{name: "number one of", typ: "NUMBER_COMPARISON_TYPE", operator: "isnotoneof", value: types.StringValue("[1, 2]")},
+		{name: "boolean", typ: "BOOLEAN_COMPARISON_TYPE", operator: "true", value: types.StringNull()},
+		{name: "datetime", typ: "DATETIME_COMPARISON_TYPE", operator: "lt", value: types.StringValue("2026-01-02T15:04:05Z")},
+		{name: "date", typ: "DATETIME_COMPARISON_TYPE", operator: "gt", value: types.StringValue("2026-01-02")},
+		{name: "present", typ: "NUMBER_COMPARISON_TYPE", operator: "present", value: types.StringNull()},
+		{name: "unknown value", typ: "NUMBER_COMPARISON_TYPE", operator: "eq", value: types.StringUnknown()},
+		{
+			name: "operator of another type", typ: "NUMBER_COMPARISON_TYPE", operator: "prefix", value: types.StringValue("1"),
+			summary: "Invalid Constraint Operator", path: path.Root("operator"),
+		},
+		{
+			name: "misspelled operator", typ: "STRING_COMPARISON_TYPE", operator: "equals", value: types.StringValue("x"),
+			summary: "Invalid Constraint Operator", path: path.Root("operator"),
+		},
+		{
+			name: "missing value", typ: "STRING_COMPARISON_TYPE", operator: "eq", value: types.StringNull(),
+			summary: "Missing Constraint Value", path: path.Root("value"),
+		},
+		{
+			name: "value without operand", typ: "STRING_COMPARISON_TYPE", operator: "empty", value: types.StringValue("x"),
+			summary: "Unexpected Constraint Value", path: path.Root("value"),
+		},
+		{
+			name: "not a number", typ: "NUMBER_COMPARISON_TYPE", operator: "lt", value: types.StringValue("ten"),
+			summary: "Invalid Constraint Value", path: path.Root("value"),
+		},
+		{
+			name: "not a datetime", typ: "DATETIME_COMPARISON_TYPE", operator: "eq", value: types.StringValue("01/02/2026"),
+			summary: "Invalid Constraint Value", path: path.Root("value"),
+		},
+		{
+			name: "one of without array", typ: "ENTITY_ID_COMPARISON_TYPE", operator: "isoneof", value: types.StringValue("a,b"),
+			summary: "Invalid Constraint Value", path: path.Root("value"),
+		},
+		{
+			name: "number one of with strings", typ: "NUMBER_COMPARISON_TYPE", operator: "isoneof", value: types.StringValue(`["1"]`),
+			summary: "Invalid Constraint Value", path: path.Root("value"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := ageConstraint(tt.operator, "")
+			data.Type = types.StringValue(tt.typ)
+			data.Value = tt.value
+
+			resp := validateResourceConfig(t, NewConstraintResource(), data)
+			if tt.summary == "" {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("Expected no error, got %v", resp.Diagnostics)
+				}
+				return
+			}
+
+			if !resp.Diagnostics.HasError() {
+				t.Fatalf("Expected %s error, got none", tt.summary)
+			}
+			err := resp.Diagnostics.Errors()[0]
+			if err.Summary() != tt.summary {
+				t.Errorf("Expected %s error, got %v", tt.summary, resp.Diagnostics)
+			}
+			if withPath, ok := err.(interface{ Path() path.Path }); !ok || !withPath.Path().Equal(tt.path) {
+				t.Errorf("Expected error at %s, got %v", tt.path, err)
+			}
+		})
+	}
+}
+
+func TestSegmentConstraintsResourceValidateConfig(t *testing.T) {
+	constraints, diags := constraintsToModel(context.Background(), []client.Constraint{
+		{Property: "age", Type: "NUMBER_COMPARISON_TYPE", Operator: "gte", Value: "18"},
+		{Property: "age", Type: "NUMBER_COMPARISON_TYPE", Operator: "lte", Value: "sixty"},
+	})
+	if diags.HasError() {
+		t.Fatalf("Unable to build constraints: %v", diags)
+	}
+
+	resp := validateResourceConfig(t, NewSegmentConstraintsResource(), &SegmentConstraintsResourceModel{
+		NamespaceKey:   types.StringNull(),
+		EnvironmentKey: types.StringNull(),
+		SegmentKey:     types.StringValue("adults"),
+		Constraints:    constraints,
+		ID:             types.StringNull(),
+	})
+	if len(resp.Diagnostics.Errors()) != 1 || resp.Diagnostics.Errors()[0].Summary() != "Invalid Constraint Value" {
+		t.Fatalf("Expected a single invalid value error, got %v", resp.Diagnostics)
+	}
+
+	expected := path.Root("constraints").AtSetValue(constraints.Elements()[1]).AtName("value")
+	if err, ok := resp.Diagnostics.Errors()[0].(interface{ Path() path.Path }); !ok || !err.Path().Equal(expected) {
+		t.Errorf("Expected error at %s, got %v", expected, resp.Diagnostics.Errors()[0])
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// planDefaultKeys sets environment_key, and namespace_key when withNamespace
+// is true, to the provider defaults in the plan when the resource does not
+// configure them, so that the plan shows the keys that will be used instead
+// of unknown values. A resource whose inherited key changes is replaced.
+func planDefaultKeys(ctx context.Context, config *FliptProviderConfig, withNamespace bool, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to do on destroy, or when the provider is not configured yet
+	// because its configuration depends on unknown values.
+	if req.Plan.Raw.IsNull() || config == nil {
+		return
+	}
+
+	planDefaultKey(ctx, path.Root("environment_key"), config.EnvironmentKey, req, resp)
+	if withNamespace {
+		planDefaultKey(ctx, path.Root("namespace_key"), config.NamespaceKey, req, resp)
+	}
+}
+
+func planDefaultKey(ctx context.Context, attr path.Path, resolve func(types.String) string, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var configured types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, attr, &configured)...)
+	if resp.Diagnostics.HasError() || !configured.IsNull() {
+		return
+	}
+
+	key := resolve(configured)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, attr, types.StringValue(key))...)
+
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var current types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, attr, &current)...)
+	if !current.IsNull() && current.ValueString() != key {
+		resp.RequiresReplace.Append(attr)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestFliptProviderConfig_Keys(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *FliptProviderConfig
+		value    types.String
+		expected string
+	}{
+		{name: "set on resource", config: &FliptProviderConfig{DefaultEnvironmentKey: "staging"}, value: types.StringValue("production"), expected: "production"},
+		{name: "provider default", config: &FliptProviderConfig{DefaultEnvironmentKey: "staging"}, value: types.StringNull(), expected: "staging"},
+		{name: "unknown uses provider default", config: &FliptProviderConfig{DefaultEnvironmentKey: "staging"}, value: types.StringUnknown(), expected: "staging"},
+		{name: "fallback", config: &FliptProviderConfig{}, value: types.StringNull(), expected: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.EnvironmentKey(tt.value); got != tt.expected {
+				t.Errorf("Expected environment key %q, got %q", tt.expected, got)
+			}
+
+			// Namespace keys resolve the same way from their own default.
+			nsConfig := &FliptProviderConfig{DefaultNamespaceKey: tt.config.DefaultEnvironmentKey}
+			if got := nsConfig.NamespaceKey(tt.value); got != tt.expected {
+				t.Errorf("Expected namespace key %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFlagResourceModifyPlan_DefaultKeys(t *testing.T) {
+	providerConfig := &FliptProviderConfig{DefaultEnvironmentKey: "staging", DefaultNamespaceKey: "team-a"}
+
+	tests := []struct {
+		name            string
+		config          *FliptProviderConfig
+		configured      FlagResourceModel
+		state           *FlagResourceModel
+		expectedEnv     types.String
+		expectedNS      types.String
+		expectedReplace []string
+	}{
+		{
+			name:        "inherits provider defaults",
+			config:      providerConfig,
+			configured:  flagModel(types.StringNull(), types.StringNull()),
+			expectedEnv: types.StringValue("staging"),
+			expectedNS:  types.StringValue("team-a"),
+		},
+		{
+			name:        "keys set on resource",
+			config:      providerConfig,
+			configured:  flagModel(types.StringValue("production"), types.StringValue("team-b")),
+			expectedEnv: types.StringValue("production"),
+			expectedNS:  types.StringValue("team-b"),
+		},
+		{
+			name:        "fallback without provider defaults",
+			config:      &FliptProviderConfig{},
+			configured:  flagModel(types.StringNull(), types.StringNull()),
+			expectedEnv: types.StringValue("default"),
+			expectedNS:  types.StringValue("default"),
+		},
+		{
+			name:        "unconfigured provider",
+			configured:  flagModel(types.StringNull(), types.StringNull()),
+			expectedEnv: types.StringUnknown(),
+			expectedNS:  types.StringUnknown(),
+		},
+		{
+			name:        "unchanged default",
+			config:      providerConfig,
+			configured:  flagModel(types.StringNull(), types.StringNull()),
+			state:       ptr(flagModel(types.StringValue("staging"), types.StringValue("team-a"))),
+			expectedEnv: types.StringValue("staging"),
+			expectedNS:  types.StringValue("team-a"),
+		},
+		{
+			name:            "changed default replaces",
+			config:          providerConfig,
+			configured:      flagModel(types.StringNull(), types.StringNull()),
+			state:           ptr(flagModel(types.StringValue("staging"), types.StringValue("team-b"))),
+			expectedEnv:     types.StringValue("staging"),
+			expectedNS:      types.StringValue("team-a"),
+			expectedReplace: []string{"namespace_key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			r := &FlagResource{config: tt.config}
+
+			schemaResp := &resource.SchemaResponse{}
+			r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+			s := schemaResp.Schema
+
+			req := resource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: s},
+				Plan:   tfsdk.Plan{Schema: s},
+				State:  tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(ctx), nil)},
+			}
+			req.Config.Raw = mustModelValue(t, ctx, req.Plan, tt.configured)
+
+			// Keys not set in the configuration are unknown in the proposed plan.
+			planned := tt.configured
+			if planned.EnvironmentKey.IsNull() {
+				planned.EnvironmentKey = types.StringUnknown()
+			}
+			if planned.NamespaceKey.IsNull() {
+				planned.NamespaceKey = types.StringUnknown()
+			}
+			req.Plan.Raw = mustModelValue(t, ctx, req.Plan, planned)
+			if tt.state != nil {
+				req.State.Raw = mustModelValue(t, ctx, req.Plan, *tt.state)
+			}
+
+			resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+			r.ModifyPlan(ctx, req, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Unexpected error: %v", resp.Diagnostics)
+			}
+
+			var result FlagResourceModel
+			resp.Diagnostics.Append(resp.Plan.Get(ctx, &result)...)
+			if !result.EnvironmentKey.Equal(tt.expectedEnv) || !result.NamespaceKey.Equal(tt.expectedNS) {
+				t.Errorf("Expected keys %s/%s, got %s/%s", tt.expectedEnv, tt.expectedNS, result.EnvironmentKey, result.NamespaceKey)
+			}
+
+			if len(resp.RequiresReplace) != len(tt.expectedReplace) {
+				t.Fatalf("Expected replacement of %v, got %v", tt.expectedReplace, resp.RequiresReplace)
+			}
+			for i, attr := range tt.expectedReplace {
+				if !resp.RequiresReplace[i].Equal(path.Root(attr)) {
+					t.Errorf("Expected replacement of %s, got %s", attr, resp.RequiresReplace[i])
+				}
+			}
+		})
+	}
+}
+
+func flagModel(envKey, namespaceKey types.String) FlagResourceModel {
+	return FlagResourceModel{
+		EnvironmentKey: envKey,
+		NamespaceKey:   namespaceKey,
+		Key:            types.StringValue("my-flag"),
+		Name:           types.StringValue("My Flag"),
+		Description:    types.StringNull(),
+		Enabled:        types.BoolValue(true),
+		Type:           types.StringValue("VARIANT_FLAG_TYPE"),
+		Metadata:       types.DynamicNull(),
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// mustModelValue converts a model into a raw value of the plan's schema.
+func mustModelValue(t *testing.T, ctx context.Context, plan tfsdk.Plan, model interface{}) tftypes.Value {
+	t.Helper()
+
+	if diags := plan.Set(ctx, model); diags.HasError() {
+		t.Fatalf("Unable to convert model: %v", diags)
+	}
+	return plan.Raw
+}
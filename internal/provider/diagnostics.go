@@ -0,0 +1,78 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
+)
+
+// errNotInParent is returned from a modify function passed to
+// client.UpdateFlag or client.UpdateSegment when the variant, rule or
+// constraint to change is missing from its parent.
+var errNotInParent = errors.New("not found in parent")
+
+// errRankOutOfRange is returned from a modify function passed to
+// client.UpdateFlag when the configured rank of a rollout is not a position
+// in the flag's rollouts.
+var errRankOutOfRange = errors.New("rank out of range")
+
+// checkRank returns an errRankOutOfRange error unless rank is a position to
+// insert a rollout at among the n others of its flag.
+func checkRank(rank int64, n int) error {
+	if rank < 0 || rank > int64(n) {
+		return fmt.Errorf("%w: rank %d is not between 0 and %d, the number of other rollouts in the flag", errRankOutOfRange, rank, n)
+	}
+	return nil
+}
+
+// addParentWriteError adds the diagnostic for a failed read-modify-write of
+// the flag or segment a resource belongs to. A conflict that persisted
+// through the client's retries names the parent that kept changing.
+func addParentWriteError(diags *diag.Diagnostics, action, parentType, parentKey string, err error) {
+	if errors.Is(err, errRankOutOfRange) {
+		diags.AddAttributeError(path.Root("rank"), "Invalid Rank", fmt.Sprintf("Unable to %s: %s", action, err))
+		return
+	}
+
+	if errors.Is(err, client.ErrConflict) {
+		diags.AddError("Concurrent Modification",
+			fmt.Sprintf("Unable to %s: %s '%s' was modified outside of Terraform while it was being updated. "+
+				"Run the apply again to work on the latest version.\n\nError: %s", action, parentType, parentKey, err))
+		return
+	}
+
+	diags.AddError("Client Error", fmt.Sprintf("Unable to %s, got error: %s", action, err))
+}
+
+// handleReadError handles a failed read of the namespace, flag or segment a
+// resource is stored in. Only a 404 from Flipt confirms that it is gone, and
+// removes the resource from state. Any other error, such as a connection
+// failure, a timeout, a 5xx or a 401/403 response, is reported, so that a
+// failed refresh does not plan to recreate the resource.
+func handleReadError(ctx context.Context, resp *resource.ReadResponse, objectType, key string, err error) {
+	if errors.Is(err, client.ErrNotFound) {
+		tflog.Warn(ctx, fmt.Sprintf("%s not found, removing from state", objectType), map[string]interface{}{
+			"key":   key,
+			"error": err.Error(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	if errors.Is(err, client.ErrUnauthorized) || errors.Is(err, client.ErrForbidden) {
+		resp.Diagnostics.AddError("Client Error",
+			fmt.Sprintf("Unable to read %s '%s', the request was rejected. Check the credentials configured on the provider.\n\nError: %s", objectType, key, err))
+		return
+	}
+
+	resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read %s '%s', got error: %s", objectType, key, err))
+}
@@ -0,0 +1,189 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"terraform-provider-flipt/internal/client"
+)
+
+func TestResourceReadErrors(t *testing.T) {
+	str := func(v string) tftypes.Value { return tftypes.NewValue(tftypes.String, v) }
+	num := func(v int64) tftypes.Value { return tftypes.NewValue(tftypes.Number, v) }
+
+	keys := map[string]tftypes.Value{
+		"namespace_key":   str("default"),
+		"environment_key": str("default"),
+	}
+	with := func(values map[string]tftypes.Value) map[string]tftypes.Value {
+		for k, v := range keys {
+			values[k] = v
+		}
+		return values
+	}
+
+	resources := []struct {
+		name     string
+		resource func() resource.Resource
+		state    map[string]tftypes.Value
+	}{
+		{name: "namespace", resource: NewNamespaceResource, state: map[string]tftypes.Value{"environment_key": str("default"), "key": str("team-a"), "name": str("Team A")}},
+		{name: "flag", resource: NewFlagResource, state: with(map[string]tftypes.Value{"key": str("checkout"), "name": str("Checkout")})},
+		{name: "variant", resource: NewVariantResource, state: with(map[string]tftypes.Value{"flag_key": str("checkout"), "key": str("blue")})},
+		{name: "rule", resource: NewRuleResource, state: with(map[string]tftypes.Value{"flag_key": str("checkout"), "id": str("checkout/0"), "rank": num(0)})},
+		{name: "distribution", resource: NewDistributionResource, state: with(map[string]tftypes.Value{"flag_key": str("checkout"), "rule_id": str("checkout/0"), "variant_key": str("blue")})},
+		{name: "rollout", resource: NewRolloutResource, state: with(map[string]tftypes.Value{"flag_key": str("checkout"), "rank": num(0)})},
+		{name: "flag default variant", resource: NewFlagDefaultVariantResource, state: with(map[string]tftypes.Value{"flag_key": str("checkout"), "variant_key": str("blue")})},
+		{name: "flag rules", resource: NewFlagRulesResource, state: with(map[string]tftypes.Value{"flag_key": str("checkout")})},
+		{name: "segment", resource: NewSegmentResource, state: with(map[string]tftypes.Value{"key": str("beta"), "name": str("Beta")})},
+		{name: "constraint", resource: NewConstraintResource, state: with(map[string]tftypes.Value{"segment_key": str("beta"), "property": str("email")})},
+		{name: "segment constraints", resource: NewSegmentConstraintsResource, state: with(map[string]tftypes.Value{"segment_key": str("beta")})},
+	}
+
+	failures := []struct {
+		name string
+		// handler answers every request, nil for a server that is not
+		// reachable
+		handler      http.HandlerFunc
+		httpClient   func(*httptest.Server) *http.Client
+		expectRemove bool
+		expectDetail string
+	}{
+		{
+			name: "not found",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"code":5,"message":"not found"}`, http.StatusNotFound)
+			},
+			expectRemove: true,
+		},
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "internal error", http.StatusInternalServerError)
+			},
+			expectDetail: "status: 500",
+		},
+		{
+			name: "bad gateway",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "bad gateway", http.StatusBadGateway)
+			},
+			expectDetail: "status: 502",
+		},
+		{
+			name: "unauthorized",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+			},
+			expectDetail: "Check the credentials",
+		},
+		{
+			name: "forbidden",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+			},
+			expectDetail: "Check the credentials",
+		},
+		{
+			name:         "connection refused",
+			expectDetail: "Unable to read",
+		},
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+			httpClient: func(s *httptest.Server) *http.Client {
+				httpClient := s.Client()
+				httpClient.Timeout = 20 * time.Millisecond
+				return httpClient
+			},
+			expectDetail: "Client.Timeout",
+		},
+	}
+
+	for _, failure := range failures {
+		for _, tt := range resources {
+			t.Run(failure.name+"/"+tt.name, func(t *testing.T) {
+				server := httptest.NewServer(failure.handler)
+				defer server.Close()
+
+				httpClient := server.Client()
+				if failure.httpClient != nil {
+					httpClient = failure.httpClient(server)
+				}
+				endpoint := server.URL
+				if failure.handler == nil {
+					// Nothing listens on the address once the server is closed
+					server.Close()
+				}
+
+				r := tt.resource()
+				configureResource(t, r, &FliptProviderConfig{
+					Client: client.New(client.Config{Endpoint: endpoint, HTTPClient: httpClient}),
+				})
+
+				resp := readResourceRaw(t, r, tt.state)
+
+				if failure.expectRemove {
+					if resp.Diagnostics.HasError() {
+						t.Fatalf("Expected no error, got %v", resp.Diagnostics)
+					}
+					if !resp.State.Raw.IsNull() {
+						t.Error("Expected the resource to be removed from state")
+					}
+					return
+				}
+
+				if resp.State.Raw.IsNull() {
+					t.Fatal("Expected the resource to be kept in state")
+				}
+				if !resp.Diagnostics.HasError() {
+					t.Fatal("Expected an error")
+				}
+				if d := resp.Diagnostics.Errors()[0]; !strings.Contains(d.Detail(), failure.expectDetail) {
+					t.Errorf("Expected error detail containing %q, got %s: %s", failure.expectDetail, d.Summary(), d.Detail())
+				}
+			})
+		}
+	}
+}
+
+// readResourceRaw calls Read on a resource with a state built from the given
+// attribute values, leaving all other attributes null.
+func readResourceRaw(t *testing.T, r resource.Resource, values map[string]tftypes.Value) *resource.ReadResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	attrs := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, typ := range objectType.AttributeTypes {
+		attrs[name] = tftypes.NewValue(typ, nil)
+		if v, ok := values[name]; ok {
+			attrs[name] = v
+		}
+	}
+
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, attrs)}
+	req := resource.ReadRequest{State: state}
+	resp := &resource.ReadResponse{State: state}
+	r.Read(ctx, req, resp)
+
+	return resp
+}
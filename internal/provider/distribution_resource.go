@@ -0,0 +1,456 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
+)
+
+var _ resource.Resource = &DistributionResource{}
+var _ resource.ResourceWithImportState = &DistributionResource{}
+var _ resource.ResourceWithModifyPlan = &DistributionResource{}
+var _ resource.ResourceWithValidateConfig = &DistributionResource{}
+
+// maxRollout is the upper bound for a single rollout and for the sum of all
+// rollouts of a rule, in percent.
+const maxRollout = 100.0
+
+// errRolloutExceeded is returned from the modify function when the rollouts
+// of a rule would add up to more than maxRollout.
+var errRolloutExceeded = errors.New("rollouts exceed 100%")
+
+// errVariantNotFound is returned from the modify function when the variant
+// to distribute to does not exist on the flag.
+var errVariantNotFound = errors.New("variant not found")
+
+// errDistributionExists is returned from the modify function of Create when
+// the rule already distributes to the variant.
+var errDistributionExists = errors.New("distribution already exists")
+
+func NewDistributionResource() resource.Resource {
+	return &DistributionResource{}
+}
+
+type DistributionResource struct {
+	config *FliptProviderConfig
+}
+
+type DistributionResourceModel struct {
+	NamespaceKey   types.String  `tfsdk:"namespace_key"`
+	EnvironmentKey types.String  `tfsdk:"environment_key"`
+	FlagKey        types.String  `tfsdk:"flag_key"`
+	RuleID         types.String  `tfsdk:"rule_id"`
+	VariantKey     types.String  `tfsdk:"variant_key"`
+	Rollout        types.Float64 `tfsdk:"rollout"`
+	ID             types.String  `tfsdk:"id"`
+}
+
+func (r *DistributionResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_distribution"
+}
+
+func (r *DistributionResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Flipt distribution resource (the share of a rule's matches served a variant)",
+
+		Attributes: map[string]schema.Attribute{
+			"namespace_key": schema.StringAttribute{
+				MarkdownDescription: "Namespace key where the flag belongs. Defaults to the provider's `default_namespace_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"environment_key": schema.StringAttribute{
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"flag_key": schema.StringAttribute{
+				MarkdownDescription: "Flag key that the rule belongs to",
+				Required:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"rule_id": schema.StringAttribute{
+				MarkdownDescription: "ID of the rule (`flipt_rule.id`) whose matches are distributed",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"variant_key": schema.StringAttribute{
+				MarkdownDescription: "Key of the variant served to this share of the rule's matches",
+				Required:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"rollout": schema.Float64Attribute{
+				MarkdownDescription: "Percentage of the rule's matches served the variant, between 0 and 100. The rollouts of all distributions of a rule must not exceed 100",
+				Required:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Identifier of the distribution, composed of the rule ID and the variant key",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *DistributionResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerConfig, ok := req.ProviderData.(*FliptProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *FliptProviderConfig, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.config = providerConfig
+}
+
+func (r *DistributionResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var rollout types.Float64
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("rollout"), &rollout)...)
+	if resp.Diagnostics.HasError() || rollout.IsNull() || rollout.IsUnknown() {
+		return
+	}
+
+	if v := rollout.ValueFloat64(); v < 0 || v > maxRollout {
+		resp.Diagnostics.AddAttributeError(path.Root("rollout"), "Invalid Rollout",
+			fmt.Sprintf("Rollout must be between 0 and 100, got: %g", v))
+	}
+}
+
+func (r *DistributionResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultKeys(ctx, r.config, true, req, resp)
+}
+
+func (r *DistributionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data DistributionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Creating distribution", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+		"rule_id":         data.RuleID.ValueString(),
+		"variant_key":     data.VariantKey.ValueString(),
+	})
+
+	// Serialize with other changes to the flag's rules
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
+
+	err := r.writeDistribution(ctx, envKey, data, true)
+	if r.addWriteError(&resp.Diagnostics, "create distribution", data, err) {
+		return
+	}
+
+	data.ID = types.StringValue(distributionID(data.RuleID.ValueString(), data.VariantKey.ValueString()))
+
+	tflog.Trace(ctx, "created a distribution resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DistributionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data DistributionResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Reading distribution", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+		"rule_id":         data.RuleID.ValueString(),
+		"variant_key":     data.VariantKey.ValueString(),
+	})
+
+	// Get the flag to read the distributions of its rules
+	flag, err := r.config.Client.GetFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
+	if err != nil {
+		handleReadError(ctx, resp, "flag", data.FlagKey.ValueString(), err)
+		return
+	}
+
+	i := findRuleByID(flag.Rules, data.FlagKey.ValueString(), data.RuleID.ValueString())
+	if i < 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	j := findDistribution(flag.Rules[i].Distributions, data.VariantKey.ValueString())
+	if j < 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Imported distributions and older states reference the rule by its
+	// rank, switch to the ID stored with the rule like flipt_rule does
+	if id := flag.Rules[i].ID; id != "" {
+		data.RuleID = types.StringValue(id)
+	}
+
+	data.Rollout = types.Float64Value(flag.Rules[i].Distributions[j].Rollout)
+	data.ID = types.StringValue(distributionID(data.RuleID.ValueString(), data.VariantKey.ValueString()))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DistributionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data DistributionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Updating distribution", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+		"rule_id":         data.RuleID.ValueString(),
+		"variant_key":     data.VariantKey.ValueString(),
+		"rollout":         data.Rollout.ValueFloat64(),
+	})
+
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
+
+	err := r.writeDistribution(ctx, envKey, data, false)
+	if r.addWriteError(&resp.Diagnostics, "update distribution", data, err) {
+		return
+	}
+
+	data.ID = types.StringValue(distributionID(data.RuleID.ValueString(), data.VariantKey.ValueString()))
+
+	tflog.Trace(ctx, "updated a distribution resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DistributionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data DistributionResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Deleting distribution", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+		"rule_id":         data.RuleID.ValueString(),
+		"variant_key":     data.VariantKey.ValueString(),
+	})
+
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
+
+	_, err := r.config.Client.UpdateFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString(), func(flag *client.Flag) error {
+		i := findRuleByID(flag.Rules, data.FlagKey.ValueString(), data.RuleID.ValueString())
+		if i < 0 {
+			return errNotInParent
+		}
+
+		j := findDistribution(flag.Rules[i].Distributions, data.VariantKey.ValueString())
+		if j < 0 {
+			return errNotInParent
+		}
+
+		// Update the rule without the deleted distribution
+		flag.Rules[i].Distributions = append(flag.Rules[i].Distributions[:j], flag.Rules[i].Distributions[j+1:]...)
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) || errors.Is(err, errNotInParent) {
+			// Flag, rule or distribution doesn't exist, distribution is already gone
+			return
+		}
+		addParentWriteError(&resp.Diagnostics, "delete distribution", "flag", data.FlagKey.ValueString(), err)
+		return
+	}
+
+	tflog.Trace(ctx, "deleted a distribution resource")
+}
+
+// ImportState imports a distribution by an ID of the form
+// environment_key/namespace_key/flag_key/rule_index/variant_key.
+func (r *DistributionResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts, ok := parseImportID(req, resp, "environment_key", "namespace_key", "flag_key", "rule_index", "variant_key")
+	if !ok {
+		return
+	}
+	rank, ok := parseImportIndex(resp, "rule_index", parts[3])
+	if !ok {
+		return
+	}
+
+	ruleID := fmt.Sprintf("%s/%d", parts[2], rank)
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("environment_key"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("namespace_key"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("flag_key"), parts[2])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("rule_id"), ruleID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("variant_key"), parts[4])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), distributionID(ruleID, parts[4]))...)
+}
+
+// writeDistribution adds the distribution to its rule, or updates the
+// rollout of an existing distribution to the same variant. On create an
+// existing distribution is not taken over and errDistributionExists is
+// returned instead.
+func (r *DistributionResource) writeDistribution(ctx context.Context, envKey string, data DistributionResourceModel, create bool) error {
+	variantKey := data.VariantKey.ValueString()
+	rollout := data.Rollout.ValueFloat64()
+
+	_, err := r.config.Client.UpdateFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString(), func(flag *client.Flag) error {
+		i := findRuleByID(flag.Rules, data.FlagKey.ValueString(), data.RuleID.ValueString())
+		if i < 0 {
+			return errNotInParent
+		}
+
+		if !hasVariant(flag.Variants, variantKey) {
+			return errVariantNotFound
+		}
+
+		rule := &flag.Rules[i]
+		j := findDistribution(rule.Distributions, variantKey)
+		if create && j >= 0 {
+			return errDistributionExists
+		}
+
+		total := rollout
+		for _, d := range rule.Distributions {
+			if d.Variant != variantKey {
+				total += d.Rollout
+			}
+		}
+		if total > maxRollout {
+			return fmt.Errorf("%w: the rollouts of rule %s would add up to %g%%", errRolloutExceeded, data.RuleID.ValueString(), total)
+		}
+
+		if j >= 0 {
+			rule.Distributions[j].Rollout = rollout
+			return nil
+		}
+		rule.Distributions = append(rule.Distributions, client.Distribution{Variant: variantKey, Rollout: rollout})
+		return nil
+	})
+
+	return err
+}
+
+// addWriteError adds the diagnostic for a failed write of a distribution and
+// reports whether there was one.
+func (r *DistributionResource) addWriteError(diags *diag.Diagnostics, action string, data DistributionResourceModel, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, errNotInParent):
+		diags.AddAttributeError(path.Root("rule_id"), "Rule Not Found",
+			fmt.Sprintf("Unable to %s: rule '%s' not found in flag '%s'", action, data.RuleID.ValueString(), data.FlagKey.ValueString()))
+	case errors.Is(err, errVariantNotFound):
+		diags.AddAttributeError(path.Root("variant_key"), "Variant Not Found",
+			fmt.Sprintf("Unable to %s: variant '%s' not found in flag '%s'", action, data.VariantKey.ValueString(), data.FlagKey.ValueString()))
+	case errors.Is(err, errDistributionExists):
+		diags.AddAttributeError(path.Root("variant_key"), "Distribution Already Exists",
+			fmt.Sprintf("Unable to %s: rule '%s' of flag '%s' already distributes to variant '%s'. "+
+				"Import the existing distribution with terraform import to manage it.", action, data.RuleID.ValueString(), data.FlagKey.ValueString(), data.VariantKey.ValueString()))
+	case errors.Is(err, errRolloutExceeded):
+		diags.AddAttributeError(path.Root("rollout"), "Invalid Rollout",
+			fmt.Sprintf("Unable to %s: %s. The rollouts of all distributions of a rule must not exceed 100.", action, err))
+	default:
+		addParentWriteError(diags, action, "flag", data.FlagKey.ValueString(), err)
+	}
+	return true
+}
+
+// distributionID returns the identifier of the distribution of a rule to a
+// variant.
+func distributionID(ruleID, variantKey string) string {
+	return ruleID + "/" + variantKey
+}
+
+// findDistribution returns the index of the distribution to the given
+// variant, or -1 if there is none.
+func findDistribution(distributions []client.Distribution, variantKey string) int {
+	for i, d := range distributions {
+		if d.Variant == variantKey {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasVariant reports whether a variant with the given key exists.
+func hasVariant(variants []client.Variant, key string) bool {
+	for _, v := range variants {
+		if v.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"terraform-provider-flipt/internal/client"
+)
+
+func TestAccDistributionResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccDistributionResourceConfig("test-distribution-ns", 50),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_distribution.test", "environment_key", "default"),
+					resource.TestCheckResourceAttr("flipt_distribution.test", "namespace_key", "test-distribution-ns"),
+					resource.TestCheckResourceAttr("flipt_distribution.test", "flag_key", "test-flag"),
+					resource.TestCheckResourceAttrPair("flipt_distribution.test", "rule_id", "flipt_rule.test", "id"),
+					resource.TestCheckResourceAttr("flipt_distribution.test", "variant_key", "blue"),
+					resource.TestCheckResourceAttr("flipt_distribution.test", "rollout", "50"),
+					testAccCheckDistributionID("flipt_distribution.test", "flipt_rule.test", "blue"),
+				),
+			},
+			// ImportState testing, the rank in the import ID is read back as
+			// the ID of the rule
+			{
+				ResourceName:      "flipt_distribution.test",
+				ImportState:       true,
+				ImportStateId:     "default/test-distribution-ns/test-flag/0/blue",
+				ImportStateVerify: true,
+			},
+			// Update and Read testing
+			{
+				Config: testAccDistributionResourceConfig("test-distribution-ns", 75),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_distribution.test", "rollout", "75"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckDistributionID checks that the id of a distribution is built
+// from the ID of its rule, which flipt_distribution reads back after import.
+func testAccCheckDistributionID(name, ruleName, variantKey string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rule, ok := s.RootModule().Resources[ruleName]
+		if !ok {
+			return fmt.Errorf("resource %s not found in state", ruleName)
+		}
+		return resource.TestCheckResourceAttr(name, "id", distributionID(rule.Primary.Attributes["id"], variantKey))(s)
+	}
+}
+
+func testAccDistributionResourceConfig(namespaceKey string, rollout float64) string {
+	return fmt.Sprintf(`
+provider "flipt" {
+  endpoint = %[1]q
+}
+
+resource "flipt_namespace" "test" {
+  key  = %[2]q
+  name = "Test Namespace"
+}
+
+resource "flipt_flag" "test" {
+  namespace_key = flipt_namespace.test.key
+  key           = "test-flag"
+  name          = "Test Flag"
+  type          = "VARIANT_FLAG_TYPE"
+}
+
+resource "flipt_variant" "test" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.test.key
+  key           = "blue"
+}
+
+resource "flipt_segment" "test" {
+  namespace_key = flipt_namespace.test.key
+  key           = "test-segment"
+  name          = "Test Segment"
+  match_type    = "ALL_MATCH_TYPE"
+}
+
+resource "flipt_rule" "test" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.test.key
+  segment_keys  = [flipt_segment.test.key]
+  rank          = 0
+}
+
+resource "flipt_distribution" "test" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.test.key
+  rule_id       = flipt_rule.test.id
+  variant_key   = flipt_variant.test.key
+  rollout       = %[3]g
+}
+`, getTestFliptEndpoint(), namespaceKey, rollout)
+}
+
+func TestDistributionResourceCreate(t *testing.T) {
+	tests := []struct {
+		name        string
+		variantKey  string
+		ruleID      string
+		rollout     float64
+		expectErr   string
+		expectTotal float64
+	}{
+		{name: "Added to rule", variantKey: "green", ruleID: "checkout/0", rollout: 40, expectTotal: 100},
+		{name: "Matched by rule ID", variantKey: "green", ruleID: "rule-1", rollout: 40, expectTotal: 100},
+		{name: "Already exists", variantKey: "blue", ruleID: "checkout/0", rollout: 20, expectErr: "Distribution Already Exists"},
+		{name: "Total exceeds 100", variantKey: "green", ruleID: "checkout/0", rollout: 50, expectErr: "Invalid Rollout"},
+		{name: "Unknown variant", variantKey: "red", ruleID: "checkout/0", rollout: 10, expectErr: "Variant Not Found"},
+		{name: "Unknown rule", variantKey: "green", ruleID: "checkout/3", rollout: 10, expectErr: "Rule Not Found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeFlipt(t)
+			fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+				"name":     "Checkout",
+				"type":     "VARIANT_FLAG_TYPE",
+				"enabled":  true,
+				"variants": []interface{}{map[string]interface{}{"key": "blue"}, map[string]interface{}{"key": "green"}},
+				"rules": []interface{}{map[string]interface{}{
+					"id":              "rule-1",
+					"segments":        []interface{}{"everyone"},
+					"segmentOperator": "OR_SEGMENT_OPERATOR",
+					"rank":            0,
+					"distributions":   []interface{}{map[string]interface{}{"variant": "blue", "rollout": 60}},
+				}},
+			})
+
+			r := NewDistributionResource()
+			configureResource(t, r, fake.config())
+
+			resp, _ := createResource(t, r, &DistributionResourceModel{
+				NamespaceKey:   types.StringValue("default"),
+				EnvironmentKey: types.StringValue("default"),
+				FlagKey:        types.StringValue("checkout"),
+				RuleID:         types.StringValue(tt.ruleID),
+				VariantKey:     types.StringValue(tt.variantKey),
+				Rollout:        types.Float64Value(tt.rollout),
+				ID:             types.StringUnknown(),
+			})
+
+			if tt.expectErr != "" {
+				if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != tt.expectErr {
+					t.Fatalf("Expected %q error, got %v", tt.expectErr, resp.Diagnostics)
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Create failed: %v", resp.Diagnostics)
+			}
+
+			rules := fake.get("default", "default", client.TypeFlag, "checkout")["rules"].([]interface{})
+			distributions := rules[0].(map[string]interface{})["distributions"].([]interface{})
+			var total float64
+			for _, d := range distributions {
+				total += d.(map[string]interface{})["rollout"].(float64)
+			}
+			if total != tt.expectTotal {
+				t.Errorf("Expected rollouts to total %g, got %g in %v", tt.expectTotal, total, distributions)
+			}
+		})
+	}
+}
+
+func TestDistributionResourceReadResolvesRuleID(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+		"name":     "Checkout",
+		"type":     "VARIANT_FLAG_TYPE",
+		"enabled":  true,
+		"variants": []interface{}{map[string]interface{}{"key": "blue"}},
+		"rules": []interface{}{map[string]interface{}{
+			"id":              "rule-1",
+			"segments":        []interface{}{"everyone"},
+			"segmentOperator": "OR_SEGMENT_OPERATOR",
+			"rank":            0,
+			"distributions":   []interface{}{map[string]interface{}{"variant": "blue", "rollout": 60}},
+		}},
+	})
+
+	r := NewDistributionResource()
+	configureResource(t, r, fake.config())
+
+	// State as written by ImportState, referencing the rule by its rank
+	resp, state := readResource(t, r, &DistributionResourceModel{
+		NamespaceKey:   types.StringValue("default"),
+		EnvironmentKey: types.StringValue("default"),
+		FlagKey:        types.StringValue("checkout"),
+		RuleID:         types.StringValue("checkout/0"),
+		VariantKey:     types.StringValue("blue"),
+		Rollout:        types.Float64Null(),
+		ID:             types.StringValue("checkout/0/blue"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read failed: %v", resp.Diagnostics)
+	}
+
+	var data DistributionResourceModel
+	if diags := state.Get(context.Background(), &data); diags.HasError() {
+		t.Fatalf("Unable to read state: %v", diags)
+	}
+	if data.RuleID.ValueString() != "rule-1" || data.ID.ValueString() != "rule-1/blue" {
+		t.Errorf("Expected the rule to be resolved to rule-1, got rule_id %s and id %s", data.RuleID, data.ID)
+	}
+	if data.Rollout.ValueFloat64() != 60 {
+		t.Errorf("Expected rollout 60, got %s", data.Rollout)
+	}
+}
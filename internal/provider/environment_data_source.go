@@ -5,15 +5,14 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
 )
 
 var _ datasource.DataSource = &EnvironmentDataSource{}
@@ -87,62 +86,19 @@ func (d *EnvironmentDataSource) Read(ctx context.Context, req datasource.ReadReq
 		"key": data.Key.ValueString(),
 	})
 
-	// List all environments and find the requested one
-	url := fmt.Sprintf("%s/api/v2/environments", d.config.Endpoint)
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-
-	d.config.AddAuthHeader(httpReq)
-	httpResp, err := d.config.HTTPClient.Do(httpReq)
+	env, err := d.config.Client.GetEnvironment(ctx, data.Key.ValueString())
 	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			resp.Diagnostics.AddError("Not Found", fmt.Sprintf("Environment with key '%s' not found", data.Key.ValueString()))
+			return
+		}
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read environments, got error: %s", err))
 		return
 	}
-	defer httpResp.Body.Close()
-
-	body, err := io.ReadAll(httpResp.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Read Error", fmt.Sprintf("Unable to read response: %s", err))
-		return
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read environments, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var response struct {
-		Environments []struct {
-			Key     string `json:"key"`
-			Name    string `json:"name"`
-			Default bool   `json:"default"`
-		} `json:"environments"`
-	}
-
-	if err := json.Unmarshal(body, &response); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s", err))
-		return
-	}
 
-	// Find the requested environment by key
-	var found bool
-	for _, env := range response.Environments {
-		if env.Key == data.Key.ValueString() {
-			data.Key = types.StringValue(env.Key)
-			data.Name = types.StringValue(env.Name)
-			data.Default = types.BoolValue(env.Default)
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		resp.Diagnostics.AddError("Not Found", fmt.Sprintf("Environment with key '%s' not found", data.Key.ValueString()))
-		return
-	}
+	data.Key = types.StringValue(env.Key)
+	data.Name = types.StringValue(env.Name)
+	data.Default = types.BoolValue(env.Default)
 
 	tflog.Trace(ctx, "read an environment data source")
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
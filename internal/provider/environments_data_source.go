@@ -0,0 +1,117 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ datasource.DataSource = &EnvironmentsDataSource{}
+
+func NewEnvironmentsDataSource() datasource.DataSource {
+	return &EnvironmentsDataSource{}
+}
+
+type EnvironmentsDataSource struct {
+	config *FliptProviderConfig
+}
+
+type EnvironmentsDataSourceModel struct {
+	KeyPrefix    types.String                 `tfsdk:"key_prefix"`
+	Environments []EnvironmentDataSourceModel `tfsdk:"environments"`
+}
+
+func (d *EnvironmentsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_environments"
+}
+
+func (d *EnvironmentsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the environments configured on the Flipt server, optionally filtered",
+		Description:         "Lists the environments configured on the Flipt server, optionally filtered",
+
+		Attributes: map[string]schema.Attribute{
+			"key_prefix": schema.StringAttribute{
+				MarkdownDescription: "Only list environments whose key starts with this prefix",
+				Optional:            true,
+			},
+			"environments": schema.ListNestedAttribute{
+				MarkdownDescription: "Environments matching the filters, in the order configured on the server",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"key": schema.StringAttribute{
+							MarkdownDescription: "Unique key of the environment",
+							Computed:            true,
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Display name of the environment",
+							Computed:            true,
+						},
+						"default": schema.BoolAttribute{
+							MarkdownDescription: "Whether this is the default environment",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *EnvironmentsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerConfig, ok := req.ProviderData.(*FliptProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *FliptProviderConfig, got: %T", req.ProviderData),
+		)
+		return
+	}
+
+	d.config = providerConfig
+}
+
+func (d *EnvironmentsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data EnvironmentsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Listing environments")
+
+	environments, err := d.config.Client.ListEnvironments(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list environments, got error: %s", err))
+		return
+	}
+
+	data.Environments = []EnvironmentDataSourceModel{}
+	for _, env := range environments {
+		if !strings.HasPrefix(env.Key, data.KeyPrefix.ValueString()) {
+			continue
+		}
+
+		data.Environments = append(data.Environments, EnvironmentDataSourceModel{
+			Key:     types.StringValue(env.Key),
+			Name:    types.StringValue(env.Name),
+			Default: types.BoolValue(env.Default),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
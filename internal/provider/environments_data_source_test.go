@@ -0,0 +1,73 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"terraform-provider-flipt/internal/client"
+)
+
+func TestAccEnvironmentsDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "flipt" {
+  endpoint = "` + getTestFliptEndpoint() + `"
+}
+
+data "flipt_environments" "all" {}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.flipt_environments.all", "environments.#", "1"),
+					resource.TestCheckResourceAttr("data.flipt_environments.all", "environments.0.key", "default"),
+					resource.TestCheckResourceAttr("data.flipt_environments.all", "environments.0.default", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestEnvironmentsDataSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"environments": []interface{}{
+				map[string]interface{}{"key": "production", "name": "Production", "default": true},
+				map[string]interface{}{"key": "staging", "name": "Staging"},
+				map[string]interface{}{"key": "staging-eu", "name": "Staging EU"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	d := NewEnvironmentsDataSource()
+	configureDataSource(t, d, &FliptProviderConfig{
+		Client: client.New(client.Config{Endpoint: server.URL, HTTPClient: server.Client()}),
+	})
+
+	resp, state := readDataSource(t, d, map[string]tftypes.Value{"key_prefix": tftypes.NewValue(tftypes.String, "staging")})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read failed: %v", resp.Diagnostics)
+	}
+
+	var data EnvironmentsDataSourceModel
+	if diags := state.Get(context.Background(), &data); diags.HasError() {
+		t.Fatalf("Unable to read state: %v", diags)
+	}
+
+	if len(data.Environments) != 2 {
+		t.Fatalf("Expected 2 environments, got %+v", data.Environments)
+	}
+	if data.Environments[0].Key.ValueString() != "staging" || data.Environments[1].Name.ValueString() != "Staging EU" || data.Environments[0].Default.ValueBool() {
+		t.Errorf("Unexpected environments %+v", data.Environments)
+	}
+}
@@ -0,0 +1,511 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"terraform-provider-flipt/internal/client"
+)
+
+// fakeFlipt is an in-memory implementation of the parts of the Flipt v2 API
+// used by the provider. Payloads are stored as raw JSON objects keyed by
+// environment, namespace, type and key.
+type fakeFlipt struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	namespaces map[string]map[string]interface{}
+	resources  map[string]map[string]interface{}
+	revision   int
+
+	// readDelay is applied to every resource GET to widen the window between
+	// reading and writing a parent.
+	readDelay time.Duration
+	// externalWrites is the number of upcoming resource PUTs that are
+	// preceded by a simulated change from another client.
+	externalWrites int
+	// writes counts the resource POSTs and PUTs received.
+	writes int
+	// deletes records the type and key of every deleted resource in order.
+	deletes []string
+	// pageSize limits the resources returned per list call, 0 returns all
+	// of them at once.
+	pageSize int
+}
+
+func newFakeFlipt(t *testing.T) *fakeFlipt {
+	t.Helper()
+
+	f := &fakeFlipt{
+		namespaces: map[string]map[string]interface{}{
+			"default/default": {"key": "default", "name": "Default", "protected": true},
+		},
+		resources: map[string]map[string]interface{}{},
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+// config returns a provider configuration talking to the fake server.
+func (f *fakeFlipt) config() *FliptProviderConfig {
+	return &FliptProviderConfig{
+		Client: client.New(client.Config{Endpoint: f.URL, HTTPClient: f.Client()}),
+	}
+}
+
+// put stores a payload directly, bypassing the API.
+func (f *fakeFlipt) put(envKey, nsKey, typeURL, key string, payload map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payload["@type"] = typeURL
+	payload["key"] = key
+	f.resources[strings.Join([]string{envKey, nsKey, typeURL, key}, "/")] = payload
+	f.revision++
+}
+
+// get returns a stored payload, or nil when it does not exist.
+func (f *fakeFlipt) get(envKey, nsKey, typeURL, key string) map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.resources[strings.Join([]string{envKey, nsKey, typeURL, key}, "/")]
+}
+
+func (f *fakeFlipt) handle(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v2/"), "/")
+
+	if len(parts) == 1 && parts[0] == "environments" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"environments": []interface{}{map[string]interface{}{"key": "default", "name": "Default", "default": true}},
+		})
+		return
+	}
+
+	if len(parts) < 3 || parts[0] != "environments" || parts[2] != "namespaces" {
+		http.NotFound(w, r)
+		return
+	}
+	envKey := parts[1]
+
+	switch {
+	case len(parts) <= 4:
+		f.handleNamespace(w, r, envKey, parts[3:])
+	case parts[4] == "resources":
+		if r.Method == http.MethodGet && f.readDelay > 0 {
+			time.Sleep(f.readDelay)
+		}
+		f.handleResource(w, r, envKey, parts[3], parts[5:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeFlipt) handleNamespace(w http.ResponseWriter, r *http.Request, envKey string, rest []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		if len(rest) == 0 {
+			var keys []string
+			for id := range f.namespaces {
+				if key, ok := strings.CutPrefix(id, envKey+"/"); ok {
+					keys = append(keys, key)
+				}
+			}
+			slices.Sort(keys)
+
+			items := []interface{}{}
+			for _, key := range keys {
+				items = append(items, f.namespaces[envKey+"/"+key])
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"items": items, "revision": f.rev()})
+			return
+		}
+		ns, ok := f.namespaces[envKey+"/"+rest[0]]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "namespace not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"namespace": ns, "revision": f.rev()})
+	case http.MethodPost, http.MethodPut:
+		var ns map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&ns); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error()})
+			return
+		}
+		if _, ok := ns["protected"]; !ok {
+			ns["protected"] = false
+		}
+		f.namespaces[envKey+"/"+ns["key"].(string)] = ns
+		f.revision++
+		writeJSON(w, http.StatusOK, map[string]interface{}{"namespace": ns, "revision": f.rev()})
+	case http.MethodDelete:
+		ns, ok := f.namespaces[envKey+"/"+rest[0]]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "namespace not found"})
+			return
+		}
+		if protected, _ := ns["protected"].(bool); protected {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{"message": "namespace is protected"})
+			return
+		}
+		if len(f.list(envKey, rest[0], "")) > 0 {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{"message": "namespace contains resources"})
+			return
+		}
+		delete(f.namespaces, envKey+"/"+rest[0])
+		f.revision++
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (f *fakeFlipt) handleResource(w http.ResponseWriter, r *http.Request, envKey, nsKey string, rest []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		if len(rest) == 1 {
+			f.listResources(w, r, envKey, nsKey, rest[0])
+			return
+		}
+		payload, ok := f.resources[strings.Join([]string{envKey, nsKey, rest[0], rest[1]}, "/")]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "resource not found"})
+			return
+		}
+		f.writeResource(w, nsKey, rest[1], payload)
+	case http.MethodPost, http.MethodPut:
+		var req struct {
+			Key      string                 `json:"key"`
+			Payload  map[string]interface{} `json:"payload"`
+			Revision string                 `json:"revision"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error()})
+			return
+		}
+
+		id := strings.Join([]string{envKey, nsKey, req.Payload["@type"].(string), req.Key}, "/")
+		_, exists := f.resources[id]
+		if r.Method == http.MethodPost && exists {
+			writeJSON(w, http.StatusConflict, map[string]interface{}{"message": "resource already exists"})
+			return
+		}
+		if r.Method == http.MethodPut && !exists {
+			writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "resource not found"})
+			return
+		}
+
+		f.writes++
+		if f.externalWrites > 0 {
+			f.externalWrites--
+			f.revision++
+		}
+		if req.Revision != "" && req.Revision != f.rev() {
+			writeJSON(w, http.StatusConflict, map[string]interface{}{"message": "revision mismatch"})
+			return
+		}
+
+		f.resources[id] = req.Payload
+		f.revision++
+		f.writeResource(w, nsKey, req.Key, req.Payload)
+	case http.MethodDelete:
+		id := strings.Join([]string{envKey, nsKey, rest[0], rest[1]}, "/")
+		if _, ok := f.resources[id]; !ok {
+			writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "resource not found"})
+			return
+		}
+		delete(f.resources, id)
+		f.deletes = append(f.deletes, rest[0]+"/"+rest[1])
+		f.revision++
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// listResources writes a page of the resources of a type in a namespace,
+// using the offset of the next resource as page token.
+func (f *fakeFlipt) listResources(w http.ResponseWriter, r *http.Request, envKey, nsKey, typeURL string) {
+	keys := f.list(envKey, nsKey, typeURL)
+
+	start := 0
+	if token := r.URL.Query().Get("pageToken"); token != "" {
+		var err error
+		if start, err = strconv.Atoi(token); err != nil || start > len(keys) {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": "invalid page token"})
+			return
+		}
+	}
+	end := len(keys)
+	if f.pageSize > 0 && start+f.pageSize < end {
+		end = start + f.pageSize
+	}
+
+	resources := []interface{}{}
+	for _, key := range keys[start:end] {
+		resources = append(resources, map[string]interface{}{
+			"namespaceKey": nsKey,
+			"key":          key,
+			"payload":      f.resources[strings.Join([]string{envKey, nsKey, typeURL, key}, "/")],
+		})
+	}
+	body := map[string]interface{}{"resources": resources, "revision": f.rev()}
+	if end < len(keys) {
+		body["nextPageToken"] = strconv.Itoa(end)
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+// list returns the sorted keys of the resources of a type in a namespace, or
+// of all types when typeURL is empty. The caller must hold f.mu.
+func (f *fakeFlipt) list(envKey, nsKey, typeURL string) []string {
+	prefix := envKey + "/" + nsKey + "/"
+	var keys []string
+	for id := range f.resources {
+		rest, ok := strings.CutPrefix(id, prefix)
+		if !ok {
+			continue
+		}
+		typ, key, _ := strings.Cut(rest, "/")
+		if typeURL == "" || typ == typeURL {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (f *fakeFlipt) writeResource(w http.ResponseWriter, nsKey, key string, payload map[string]interface{}) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"resource": map[string]interface{}{
+			"namespaceKey": nsKey,
+			"key":          key,
+			"payload":      payload,
+		},
+		"revision": f.rev(),
+	})
+}
+
+func (f *fakeFlipt) rev() string {
+	return fmt.Sprintf("rev-%d", f.revision)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// configureResource passes the provider configuration to a resource.
+func configureResource(t *testing.T, r resource.Resource, config *FliptProviderConfig) {
+	t.Helper()
+
+	resp := &resource.ConfigureResponse{}
+	r.(resource.ResourceWithConfigure).Configure(context.Background(), resource.ConfigureRequest{ProviderData: config}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure failed: %v", resp.Diagnostics)
+	}
+}
+
+// validateResourceConfig calls ValidateConfig on a resource with the given
+// model as configuration.
+func validateResourceConfig(t *testing.T, r resource.Resource, config interface{}) *resource.ValidateConfigResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	req := resource.ValidateConfigRequest{Config: tfsdk.Config{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+	}}
+	state := tfsdk.State(req.Config)
+	if diags := state.Set(ctx, config); diags.HasError() {
+		t.Fatalf("Unable to build config: %v", diags)
+	}
+	req.Config.Raw = state.Raw
+
+	resp := &resource.ValidateConfigResponse{}
+	r.(resource.ResourceWithValidateConfig).ValidateConfig(ctx, req, resp)
+
+	return resp
+}
+
+// createResource calls Create on a resource with the given model as plan and
+// returns the diagnostics together with the resulting state.
+func createResource(t *testing.T, r resource.Resource, plan interface{}) (*resource.CreateResponse, tfsdk.State) {
+	t.Helper()
+	ctx := context.Background()
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	req := resource.CreateRequest{Plan: tfsdk.Plan{Schema: schemaResp.Schema}}
+	if diags := req.Plan.Set(ctx, plan); diags.HasError() {
+		t.Fatalf("Unable to build plan: %v", diags)
+	}
+
+	resp := &resource.CreateResponse{
+		State: tfsdk.State{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+		},
+	}
+	r.Create(ctx, req, resp)
+
+	return resp, resp.State
+}
+
+// updateResource calls Update on a resource with the given models as plan and
+// prior state and returns the diagnostics together with the resulting state.
+func updateResource(t *testing.T, r resource.Resource, plan, state interface{}) (*resource.UpdateResponse, tfsdk.State) {
+	t.Helper()
+	ctx := context.Background()
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	req := resource.UpdateRequest{
+		Plan:  tfsdk.Plan{Schema: schemaResp.Schema},
+		State: tfsdk.State{Schema: schemaResp.Schema},
+	}
+	if diags := req.Plan.Set(ctx, plan); diags.HasError() {
+		t.Fatalf("Unable to build plan: %v", diags)
+	}
+	if diags := req.State.Set(ctx, state); diags.HasError() {
+		t.Fatalf("Unable to build state: %v", diags)
+	}
+
+	resp := &resource.UpdateResponse{State: req.State}
+	r.Update(ctx, req, resp)
+
+	return resp, resp.State
+}
+
+// deleteResource calls Delete on a resource with the given model as state.
+func deleteResource(t *testing.T, r resource.Resource, state interface{}) *resource.DeleteResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	req := resource.DeleteRequest{State: tfsdk.State{Schema: schemaResp.Schema}}
+	if diags := req.State.Set(ctx, state); diags.HasError() {
+		t.Fatalf("Unable to build state: %v", diags)
+	}
+
+	resp := &resource.DeleteResponse{State: req.State}
+	r.Delete(ctx, req, resp)
+
+	return resp
+}
+
+// readResource calls Read on a resource with the given model as state and
+// returns the diagnostics together with the refreshed state.
+func readResource(t *testing.T, r resource.Resource, state interface{}) (*resource.ReadResponse, tfsdk.State) {
+	t.Helper()
+	ctx := context.Background()
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	req := resource.ReadRequest{State: tfsdk.State{Schema: schemaResp.Schema}}
+	if diags := req.State.Set(ctx, state); diags.HasError() {
+		t.Fatalf("Unable to build state: %v", diags)
+	}
+
+	resp := &resource.ReadResponse{State: req.State}
+	r.Read(ctx, req, resp)
+
+	return resp, resp.State
+}
+
+// upgradeResourceState runs the state upgrader of a resource for the given
+// prior schema version on a state built from the given attribute values.
+func upgradeResourceState(t *testing.T, r resource.Resource, version int64, prior map[string]tftypes.Value) (*resource.UpgradeStateResponse, tfsdk.State) {
+	t.Helper()
+	ctx := context.Background()
+
+	upgrader, ok := r.(resource.ResourceWithUpgradeState).UpgradeState(ctx)[version]
+	if !ok {
+		t.Fatalf("No state upgrader for version %d", version)
+	}
+
+	req := resource.UpgradeStateRequest{
+		State: &tfsdk.State{
+			Schema: *upgrader.PriorSchema,
+			Raw:    tftypes.NewValue(upgrader.PriorSchema.Type().TerraformType(ctx), prior),
+		},
+	}
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	resp := &resource.UpgradeStateResponse{
+		State: tfsdk.State{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+		},
+	}
+	upgrader.StateUpgrader(ctx, req, resp)
+
+	return resp, resp.State
+}
+
+// configureDataSource passes the provider configuration to a data source.
+func configureDataSource(t *testing.T, d datasource.DataSource, config *FliptProviderConfig) {
+	t.Helper()
+
+	resp := &datasource.ConfigureResponse{}
+	d.(datasource.DataSourceWithConfigure).Configure(context.Background(), datasource.ConfigureRequest{ProviderData: config}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure failed: %v", resp.Diagnostics)
+	}
+}
+
+// readDataSource calls Read on a data source with a configuration built from
+// the given attribute values, leaving all other attributes null.
+func readDataSource(t *testing.T, d datasource.DataSource, values map[string]tftypes.Value) (*datasource.ReadResponse, tfsdk.State) {
+	t.Helper()
+	ctx := context.Background()
+
+	schemaResp := &datasource.SchemaResponse{}
+	d.Schema(ctx, datasource.SchemaRequest{}, schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	attrs := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, typ := range objectType.AttributeTypes {
+		attrs[name] = tftypes.NewValue(typ, nil)
+		if v, ok := values[name]; ok {
+			attrs[name] = v
+		}
+	}
+
+	config := tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, attrs)}
+	resp := &datasource.ReadResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: config.Raw}}
+	d.Read(ctx, datasource.ReadRequest{Config: config}, resp)
+
+	return resp, resp.State
+}
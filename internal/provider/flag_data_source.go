@@ -5,15 +5,14 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
 )
 
 var _ datasource.DataSource = &FlagDataSource{}
@@ -27,14 +26,14 @@ type FlagDataSource struct {
 }
 
 type FlagDataSourceModel struct {
-	NamespaceKey   types.String `tfsdk:"namespace_key"`
-	EnvironmentKey types.String `tfsdk:"environment_key"`
-	Key            types.String `tfsdk:"key"`
-	Name           types.String `tfsdk:"name"`
-	Description    types.String `tfsdk:"description"`
-	Enabled        types.Bool   `tfsdk:"enabled"`
-	Type           types.String `tfsdk:"type"`
-	Metadata       types.Map    `tfsdk:"metadata"`
+	NamespaceKey   types.String  `tfsdk:"namespace_key"`
+	EnvironmentKey types.String  `tfsdk:"environment_key"`
+	Key            types.String  `tfsdk:"key"`
+	Name           types.String  `tfsdk:"name"`
+	Description    types.String  `tfsdk:"description"`
+	Enabled        types.Bool    `tfsdk:"enabled"`
+	Type           types.String  `tfsdk:"type"`
+	Metadata       types.Dynamic `tfsdk:"metadata"`
 }
 
 func (d *FlagDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -48,12 +47,14 @@ func (d *FlagDataSource) Schema(ctx context.Context, req datasource.SchemaReques
 
 		Attributes: map[string]schema.Attribute{
 			"namespace_key": schema.StringAttribute{
-				MarkdownDescription: "Namespace key where the flag belongs",
-				Required:            true,
+				MarkdownDescription: "Namespace key where the flag belongs. Defaults to the provider's `default_namespace_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
 			},
 			"environment_key": schema.StringAttribute{
-				MarkdownDescription: "Environment key (defaults to 'default' if not specified)",
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
 				Optional:            true,
+				Computed:            true,
 			},
 			"key": schema.StringAttribute{
 				MarkdownDescription: "Unique key for the flag",
@@ -75,10 +76,9 @@ func (d *FlagDataSource) Schema(ctx context.Context, req datasource.SchemaReques
 				MarkdownDescription: "Type of the flag (VARIANT_FLAG_TYPE or BOOLEAN_FLAG_TYPE)",
 				Computed:            true,
 			},
-			"metadata": schema.MapAttribute{
-				MarkdownDescription: "Metadata key-value pairs for the flag",
+			"metadata": schema.DynamicAttribute{
+				MarkdownDescription: "Metadata of the flag as an object. Values keep their JSON type",
 				Computed:            true,
-				ElementType:         types.StringType,
 			},
 		},
 	}
@@ -109,11 +109,10 @@ func (d *FlagDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the data source
+	envKey := d.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(d.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Reading flag", map[string]interface{}{
 		"environment_key": envKey,
@@ -121,61 +120,15 @@ func (d *FlagDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 		"key":             data.Key.ValueString(),
 	})
 
-	// GET URL includes flipt.core.Flag prefix
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Flag/%s", d.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.Key.ValueString())
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-
-	d.config.AddAuthHeader(httpReq)
-	httpResp, err := d.config.HTTPClient.Do(httpReq)
+	flag, err := d.config.Client.GetFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.Key.ValueString())
 	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			resp.Diagnostics.AddError("Not Found", fmt.Sprintf("Flag with key '%s' not found in namespace '%s'", data.Key.ValueString(), data.NamespaceKey.ValueString()))
+			return
+		}
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read flag, got error: %s", err))
 		return
 	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode == http.StatusNotFound {
-		resp.Diagnostics.AddError("Not Found", fmt.Sprintf("Flag with key '%s' not found in namespace '%s'", data.Key.ValueString(), data.NamespaceKey.ValueString()))
-		return
-	}
-
-	body, err := io.ReadAll(httpResp.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Read Error", fmt.Sprintf("Unable to read response: %s", err))
-		return
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read flag, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	// Parse response with correct structure
-	var response struct {
-		Resource struct {
-			NamespaceKey string `json:"namespaceKey"`
-			Key          string `json:"key"`
-			Payload      struct {
-				Type        string                 `json:"type"`
-				Key         string                 `json:"key"`
-				Name        string                 `json:"name"`
-				Description string                 `json:"description"`
-				Enabled     bool                   `json:"enabled"`
-				Metadata    map[string]interface{} `json:"metadata"`
-			} `json:"payload"`
-		} `json:"resource"`
-		Revision string `json:"revision"`
-	}
-
-	if err := json.Unmarshal(body, &response); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s", err))
-		return
-	}
-
-	flag := response.Resource.Payload
 
 	data.Key = types.StringValue(flag.Key)
 	data.Name = types.StringValue(flag.Name)
@@ -188,21 +141,12 @@ func (d *FlagDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 		data.Description = types.StringNull()
 	}
 
-	// Set metadata if present
-	if len(flag.Metadata) > 0 {
-		metadataMap := make(map[string]string)
-		for k, v := range flag.Metadata {
-			metadataMap[k] = fmt.Sprintf("%v", v)
-		}
-		metadataValue, diags := types.MapValueFrom(ctx, types.StringType, metadataMap)
-		if diags.HasError() {
-			resp.Diagnostics.Append(diags...)
-			return
-		}
-		data.Metadata = metadataValue
-	} else {
-		data.Metadata = types.MapNull(types.StringType)
+	metadataValue, diags := flagMetadataToModel(ctx, flag.Metadata)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	data.Metadata = metadataValue
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -0,0 +1,305 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
+)
+
+var _ resource.Resource = &FlagDefaultVariantResource{}
+var _ resource.ResourceWithImportState = &FlagDefaultVariantResource{}
+var _ resource.ResourceWithModifyPlan = &FlagDefaultVariantResource{}
+
+func NewFlagDefaultVariantResource() resource.Resource {
+	return &FlagDefaultVariantResource{}
+}
+
+type FlagDefaultVariantResource struct {
+	config *FliptProviderConfig
+}
+
+type FlagDefaultVariantResourceModel struct {
+	NamespaceKey   types.String `tfsdk:"namespace_key"`
+	EnvironmentKey types.String `tfsdk:"environment_key"`
+	FlagKey        types.String `tfsdk:"flag_key"`
+	VariantKey     types.String `tfsdk:"variant_key"`
+	ID             types.String `tfsdk:"id"`
+}
+
+func (r *FlagDefaultVariantResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flag_default_variant"
+}
+
+func (r *FlagDefaultVariantResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Flipt flag default variant resource (the variant served when no rule matches)",
+
+		Attributes: map[string]schema.Attribute{
+			"namespace_key": schema.StringAttribute{
+				MarkdownDescription: "Namespace key where the flag belongs. Defaults to the provider's `default_namespace_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"environment_key": schema.StringAttribute{
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"flag_key": schema.StringAttribute{
+				MarkdownDescription: "Key of the flag whose default variant is managed",
+				Required:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"variant_key": schema.StringAttribute{
+				MarkdownDescription: "Key of the variant served when no rule matches. The variant must exist on the flag, and cannot be deleted while it is the default. Set it from the `flipt_variant` resource, e.g. `flipt_variant.example.key`, so that Terraform changes the default before deleting the variant",
+				Required:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Identifier of the default variant, the flag key",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *FlagDefaultVariantResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerConfig, ok := req.ProviderData.(*FliptProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *FliptProviderConfig, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.config = providerConfig
+}
+
+func (r *FlagDefaultVariantResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultKeys(ctx, r.config, true, req, resp)
+}
+
+func (r *FlagDefaultVariantResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data FlagDefaultVariantResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Setting flag default variant", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+		"variant_key":     data.VariantKey.ValueString(),
+	})
+
+	// Serialize with other changes to the flag's variants
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
+
+	if r.setDefaultVariant(ctx, &resp.Diagnostics, "set default variant", envKey, data) {
+		return
+	}
+
+	data.ID = types.StringValue(data.FlagKey.ValueString())
+
+	tflog.Trace(ctx, "created a flag default variant resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FlagDefaultVariantResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data FlagDefaultVariantResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Reading flag default variant", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+	})
+
+	flag, err := r.config.Client.GetFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
+	if err != nil {
+		handleReadError(ctx, resp, "flag", data.FlagKey.ValueString(), err)
+		return
+	}
+
+	// A default variant cleared outside of Terraform is recreated
+	if flag.DefaultVariant == "" {
+		tflog.Warn(ctx, "Flag has no default variant, removing from state", map[string]interface{}{
+			"flag_key": data.FlagKey.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	data.VariantKey = types.StringValue(flag.DefaultVariant)
+	data.ID = types.StringValue(data.FlagKey.ValueString())
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FlagDefaultVariantResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data FlagDefaultVariantResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Updating flag default variant", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+		"variant_key":     data.VariantKey.ValueString(),
+	})
+
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
+
+	if r.setDefaultVariant(ctx, &resp.Diagnostics, "update default variant", envKey, data) {
+		return
+	}
+
+	data.ID = types.StringValue(data.FlagKey.ValueString())
+
+	tflog.Trace(ctx, "updated a flag default variant resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FlagDefaultVariantResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data FlagDefaultVariantResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Clearing flag default variant", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+	})
+
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
+
+	_, err := r.config.Client.UpdateFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString(), func(flag *client.Flag) error {
+		if flag.DefaultVariant == "" {
+			return errNotInParent
+		}
+
+		flag.DefaultVariant = ""
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) || errors.Is(err, errNotInParent) {
+			// Flag doesn't exist or has no default variant, nothing to clear
+			return
+		}
+		addParentWriteError(&resp.Diagnostics, "clear default variant", "flag", data.FlagKey.ValueString(), err)
+		return
+	}
+
+	tflog.Trace(ctx, "deleted a flag default variant resource")
+}
+
+// ImportState imports the default variant of a flag by an ID of the form
+// environment_key/namespace_key/flag_key.
+func (r *FlagDefaultVariantResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts, ok := parseImportID(req, resp, "environment_key", "namespace_key", "flag_key")
+	if !ok {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("environment_key"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("namespace_key"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("flag_key"), parts[2])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), parts[2])...)
+}
+
+// setDefaultVariant sets the default variant of the flag after checking
+// that the variant exists, and reports whether it failed.
+func (r *FlagDefaultVariantResource) setDefaultVariant(ctx context.Context, diags *diag.Diagnostics, action, envKey string, data FlagDefaultVariantResourceModel) bool {
+	variantKey := data.VariantKey.ValueString()
+
+	_, err := r.config.Client.UpdateFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString(), func(flag *client.Flag) error {
+		if !hasVariant(flag.Variants, variantKey) {
+			return errVariantNotFound
+		}
+
+		flag.DefaultVariant = variantKey
+		return nil
+	})
+
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, errVariantNotFound):
+		diags.AddAttributeError(path.Root("variant_key"), "Variant Not Found",
+			fmt.Sprintf("Unable to %s: variant '%s' not found in flag '%s'", action, variantKey, data.FlagKey.ValueString()))
+	default:
+		addParentWriteError(diags, action, "flag", data.FlagKey.ValueString(), err)
+	}
+	return true
+}
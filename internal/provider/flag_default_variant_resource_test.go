@@ -0,0 +1,160 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"terraform-provider-flipt/internal/client"
+)
+
+func TestAccFlagDefaultVariantResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccFlagDefaultVariantResourceConfig("flipt_variant.blue.key", "blue", "green"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_flag_default_variant.test", "flag_key", "test-flag"),
+					resource.TestCheckResourceAttr("flipt_flag_default_variant.test", "variant_key", "blue"),
+					resource.TestCheckResourceAttr("flipt_flag_default_variant.test", "id", "test-flag"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "flipt_flag_default_variant.test",
+				ImportState:       true,
+				ImportStateId:     "default/test-default-variant-ns/test-flag",
+				ImportStateVerify: true,
+			},
+			// Update and Read testing
+			{
+				Config: testAccFlagDefaultVariantResourceConfig("flipt_variant.green.key", "blue", "green"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_flag_default_variant.test", "variant_key", "green"),
+				),
+			},
+			// Deleting another variant keeps the default variant
+			{
+				Config: testAccFlagDefaultVariantResourceConfig("flipt_variant.green.key", "green"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PostApplyPostRefresh: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_flag_default_variant.test", "variant_key", "green"),
+				),
+			},
+			// The default variant is not deleted behind the resource's back
+			{
+				Config:      testAccFlagDefaultVariantResourceConfig(`"green"`),
+				ExpectError: regexp.MustCompile("Variant Is Default"),
+			},
+		},
+	})
+}
+
+// testAccFlagDefaultVariantResourceConfig returns a flag with the given
+// variants and a default variant set to the variantKey expression.
+func testAccFlagDefaultVariantResourceConfig(variantKey string, variants ...string) string {
+	config := fmt.Sprintf(`
+provider "flipt" {
+  endpoint = %[1]q
+}
+
+resource "flipt_namespace" "test" {
+  key  = "test-default-variant-ns"
+  name = "Test Namespace"
+}
+
+resource "flipt_flag" "test" {
+  namespace_key = flipt_namespace.test.key
+  key           = "test-flag"
+  name          = "Test Flag"
+  type          = "VARIANT_FLAG_TYPE"
+}
+
+resource "flipt_flag_default_variant" "test" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.test.key
+  variant_key   = %[2]s
+}
+`, getTestFliptEndpoint(), variantKey)
+
+	for _, key := range variants {
+		config += fmt.Sprintf(`
+resource "flipt_variant" %[1]q {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.test.key
+  key           = %[1]q
+}
+`, key)
+	}
+	return config
+}
+
+func TestFlagDefaultVariantResource(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+		"name":     "Checkout",
+		"type":     "VARIANT_FLAG_TYPE",
+		"enabled":  true,
+		"variants": []interface{}{map[string]interface{}{"key": "blue"}, map[string]interface{}{"key": "green"}},
+	})
+
+	r := NewFlagDefaultVariantResource()
+	configureResource(t, r, fake.config())
+
+	model := func(variantKey string) *FlagDefaultVariantResourceModel {
+		return &FlagDefaultVariantResourceModel{
+			NamespaceKey:   types.StringValue("default"),
+			EnvironmentKey: types.StringValue("default"),
+			FlagKey:        types.StringValue("checkout"),
+			VariantKey:     types.StringValue(variantKey),
+			ID:             types.StringValue("checkout"),
+		}
+	}
+
+	// Unknown variants are rejected
+	resp, _ := createResource(t, r, model("red"))
+	if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != "Variant Not Found" {
+		t.Fatalf("Expected variant not found error, got %v", resp.Diagnostics)
+	}
+
+	resp, _ = createResource(t, r, model("blue"))
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Create failed: %v", resp.Diagnostics)
+	}
+	if got := fake.get("default", "default", client.TypeFlag, "checkout")["defaultVariant"]; got != "blue" {
+		t.Fatalf("Expected default variant blue, got %v", got)
+	}
+
+	// A default variant changed outside of Terraform is detected
+	fake.get("default", "default", client.TypeFlag, "checkout")["defaultVariant"] = "green"
+	readResp, state := readResource(t, r, model("blue"))
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("Read failed: %v", readResp.Diagnostics)
+	}
+	var data FlagDefaultVariantResourceModel
+	state.Get(context.Background(), &data)
+	if data.VariantKey.ValueString() != "green" {
+		t.Errorf("Expected drifted variant green in state, got %s", data.VariantKey)
+	}
+
+	if resp := deleteResource(t, r, model("green")); resp.Diagnostics.HasError() {
+		t.Fatalf("Delete failed: %v", resp.Diagnostics)
+	}
+	if got := fake.get("default", "default", client.TypeFlag, "checkout")["defaultVariant"]; got != nil {
+		t.Errorf("Expected default variant to be cleared, got %v", got)
+	}
+}
@@ -4,13 +4,14 @@
 package provider
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"reflect"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -18,12 +19,17 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
 )
 
 var _ resource.Resource = &FlagResource{}
 var _ resource.ResourceWithImportState = &FlagResource{}
+var _ resource.ResourceWithModifyPlan = &FlagResource{}
+var _ resource.ResourceWithValidateConfig = &FlagResource{}
+var _ resource.ResourceWithUpgradeState = &FlagResource{}
 
 func NewFlagResource() resource.Resource {
 	return &FlagResource{}
@@ -34,14 +40,14 @@ type FlagResource struct {
 }
 
 type FlagResourceModel struct {
-	NamespaceKey   types.String `tfsdk:"namespace_key"`
-	EnvironmentKey types.String `tfsdk:"environment_key"`
-	Key            types.String `tfsdk:"key"`
-	Name           types.String `tfsdk:"name"`
-	Description    types.String `tfsdk:"description"`
-	Enabled        types.Bool   `tfsdk:"enabled"`
-	Type           types.String `tfsdk:"type"`
-	Metadata       types.Map    `tfsdk:"metadata"`
+	NamespaceKey   types.String  `tfsdk:"namespace_key"`
+	EnvironmentKey types.String  `tfsdk:"environment_key"`
+	Key            types.String  `tfsdk:"key"`
+	Name           types.String  `tfsdk:"name"`
+	Description    types.String  `tfsdk:"description"`
+	Enabled        types.Bool    `tfsdk:"enabled"`
+	Type           types.String  `tfsdk:"type"`
+	Metadata       types.Dynamic `tfsdk:"metadata"`
 }
 
 func (r *FlagResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -51,17 +57,23 @@ func (r *FlagResource) Metadata(ctx context.Context, req resource.MetadataReques
 func (r *FlagResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "Flipt flag resource",
+		Version:             1,
 
 		Attributes: map[string]schema.Attribute{
 			"namespace_key": schema.StringAttribute{
-				MarkdownDescription: "Namespace key where the flag belongs",
-				Required:            true,
+				MarkdownDescription: "Namespace key where the flag belongs. Defaults to the provider's `default_namespace_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
 				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
 			"environment_key": schema.StringAttribute{
-				MarkdownDescription: "Environment key (defaults to 'default')",
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
 				Optional:            true,
 				Computed:            true,
 				PlanModifiers: []planmodifier.String{
@@ -70,8 +82,11 @@ func (r *FlagResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				},
 			},
 			"key": schema.StringAttribute{
-				MarkdownDescription: "Unique key for the flag",
+				MarkdownDescription: "Unique key for the flag. Letters, numbers, `-`, `_` and `,` only",
 				Required:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
@@ -91,15 +106,17 @@ func (r *FlagResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				Default:             booldefault.StaticBool(false),
 			},
 			"type": schema.StringAttribute{
-				MarkdownDescription: "Type of the flag (VARIANT_FLAG_TYPE or BOOLEAN_FLAG_TYPE)",
+				MarkdownDescription: "Type of the flag (VARIANT_FLAG_TYPE or BOOLEAN_FLAG_TYPE). The type can only change while the flag has no variants, rules or rollouts",
 				Optional:            true,
 				Computed:            true,
 				Default:             stringdefault.StaticString("VARIANT_FLAG_TYPE"),
+				Validators: []validator.String{
+					stringvalidator.OneOf("VARIANT_FLAG_TYPE", "BOOLEAN_FLAG_TYPE"),
+				},
 			},
-			"metadata": schema.MapAttribute{
-				MarkdownDescription: "Metadata key-value pairs for the flag",
+			"metadata": schema.DynamicAttribute{
+				MarkdownDescription: "Metadata of the flag as an object, such as `{ owner = \"team-a\", priority = 1 }`. Values keep their JSON type, so numbers, booleans, lists and nested objects are stored as such",
 				Optional:            true,
-				ElementType:         types.StringType,
 			},
 		},
 	}
@@ -122,6 +139,43 @@ func (r *FlagResource) Configure(ctx context.Context, req resource.ConfigureRequ
 	r.config = providerConfig
 }
 
+func (r *FlagResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultKeys(ctx, r.config, true, req, resp)
+	if resp.Diagnostics.HasError() || req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || r.config == nil {
+		return
+	}
+
+	var state, plan FlagResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A replaced flag starts without children, only a type changed in place
+	// needs checking
+	if plan.Type.IsUnknown() || plan.Type.Equal(state.Type) || len(resp.RequiresReplace) > 0 || !plan.Key.Equal(state.Key) {
+		return
+	}
+
+	// Variant and boolean flags evaluate differently, the variants, rules and
+	// rollouts of one don't carry over to the other
+	flag, err := r.config.Client.GetFlag(ctx, state.EnvironmentKey.ValueString(), state.NamespaceKey.ValueString(), state.Key.ValueString())
+	if errors.Is(err, client.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read flag %s, got error: %s", state.Key.ValueString(), err))
+		return
+	}
+	if len(flag.Variants) > 0 || len(flag.Rules) > 0 || len(flag.Rollouts) > 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("type"), "Flag Type Change",
+			fmt.Sprintf("Unable to change the type of flag %s from %s to %s while it has %d variants, %d rules and %d rollouts. "+
+				"Remove them in an earlier apply, or replace the flag with terraform apply -replace.",
+				state.Key.ValueString(), state.Type.ValueString(), plan.Type.ValueString(), len(flag.Variants), len(flag.Rules), len(flag.Rollouts)))
+	}
+}
+
 func (r *FlagResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data FlagResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -129,12 +183,10 @@ func (r *FlagResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	// Default to "default" environment if not specified
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
 	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Creating flag", map[string]interface{}{
 		"environment_key": envKey,
@@ -143,99 +195,27 @@ func (r *FlagResource) Create(ctx context.Context, req resource.CreateRequest, r
 		"name":            data.Name.ValueString(),
 	})
 
-	// Build flag payload
-	flagPayload := map[string]interface{}{
-		"@type":   "flipt.core.Flag",
-		"key":     data.Key.ValueString(),
-		"name":    data.Name.ValueString(),
-		"type":    data.Type.ValueString(),
-		"enabled": data.Enabled.ValueBool(),
+	flag := &client.Flag{
+		Key:     data.Key.ValueString(),
+		Name:    data.Name.ValueString(),
+		Type:    data.Type.ValueString(),
+		Enabled: data.Enabled.ValueBool(),
 	}
 
 	if !data.Description.IsNull() && !data.Description.IsUnknown() {
-		flagPayload["description"] = data.Description.ValueString()
-	}
-
-	// Add metadata if provided
-	if !data.Metadata.IsNull() && !data.Metadata.IsUnknown() {
-		metadataMap := make(map[string]string)
-		diags := data.Metadata.ElementsAs(ctx, &metadataMap, false)
-		if diags.HasError() {
-			resp.Diagnostics.Append(diags...)
-			return
-		}
-		if len(metadataMap) > 0 {
-			// Convert to map[string]interface{} for JSON marshaling
-			metadata := make(map[string]interface{})
-			for k, v := range metadataMap {
-				metadata[k] = v
-			}
-			flagPayload["metadata"] = metadata
-		}
-	}
-
-	// Wrap in resources API format
-	createReq := map[string]interface{}{
-		"key":     data.Key.ValueString(),
-		"payload": flagPayload,
-	}
-
-	reqBody, err := json.Marshal(createReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Serialization Error", fmt.Sprintf("Unable to marshal request: %s", err))
-		return
+		flag.Description = data.Description.ValueString()
 	}
 
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources", r.config.Endpoint, envKey, data.NamespaceKey.ValueString())
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
+	resp.Diagnostics.Append(flagMetadataFromModel(ctx, data.Metadata, flag)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	r.config.AddAuthHeader(httpReq)
 
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
+	flag, err := r.config.Client.CreateFlag(ctx, envKey, data.NamespaceKey.ValueString(), flag)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create flag, got error: %s", err))
 		return
 	}
-	defer httpResp.Body.Close()
-
-	body, err := io.ReadAll(httpResp.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Read Error", fmt.Sprintf("Unable to read response: %s", err))
-		return
-	}
-
-	if httpResp.StatusCode != http.StatusOK && httpResp.StatusCode != http.StatusCreated {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to create flag, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	// Parse response with correct structure: {"resource": {"namespaceKey": "...", "key": "...", "payload": {...}}}
-	var response struct {
-		Resource struct {
-			NamespaceKey string `json:"namespaceKey"`
-			Key          string `json:"key"`
-			Payload      struct {
-				Type        string                 `json:"type"`
-				Key         string                 `json:"key"`
-				Name        string                 `json:"name"`
-				Description string                 `json:"description"`
-				Enabled     bool                   `json:"enabled"`
-				Metadata    map[string]interface{} `json:"metadata"`
-			} `json:"payload"`
-		} `json:"resource"`
-		Revision string `json:"revision"`
-	}
-
-	if err := json.Unmarshal(body, &response); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s, body: %s", err, string(body)))
-		return
-	}
-
-	flag := response.Resource.Payload
 
 	// Set optional and computed fields from response
 	if flag.Description != "" {
@@ -245,20 +225,8 @@ func (r *FlagResource) Create(ctx context.Context, req resource.CreateRequest, r
 	data.Enabled = types.BoolValue(flag.Enabled)
 	data.Type = types.StringValue(flag.Type)
 
-	// Set metadata if present in response
-	if len(flag.Metadata) > 0 {
-		metadataMap := make(map[string]string)
-		for k, v := range flag.Metadata {
-			// Convert interface{} to string for storage
-			metadataMap[k] = fmt.Sprintf("%v", v)
-		}
-		metadataValue, diags := types.MapValueFrom(ctx, types.StringType, metadataMap)
-		if diags.HasError() {
-			resp.Diagnostics.Append(diags...)
-			return
-		}
-		data.Metadata = metadataValue
-	}
+	// Metadata is stored as sent, the planned value is kept so that its type
+	// matches the configuration
 
 	tflog.Trace(ctx, "created a flag resource")
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -271,11 +239,10 @@ func (r *FlagResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	// Default to "default" environment if not specified
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Reading flag", map[string]interface{}{
 		"environment_key": envKey,
@@ -283,64 +250,16 @@ func (r *FlagResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		"key":             data.Key.ValueString(),
 	})
 
-	// GET URL includes flipt.core.Flag prefix
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Flag/%s", r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.Key.ValueString())
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-	r.config.AddAuthHeader(httpReq)
-
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
+	flag, err := r.config.Client.GetFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.Key.ValueString())
 	if err != nil {
-		resp.State.RemoveResource(ctx)
-		return
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode == http.StatusNotFound {
-		resp.State.RemoveResource(ctx)
-		return
-	}
-
-	body, err := io.ReadAll(httpResp.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Read Error", fmt.Sprintf("Unable to read response: %s", err))
-		return
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read flag, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	// Parse response with correct structure
-	var response struct {
-		Resource struct {
-			NamespaceKey string `json:"namespaceKey"`
-			Key          string `json:"key"`
-			Payload      struct {
-				Type        string                 `json:"type"`
-				Key         string                 `json:"key"`
-				Name        string                 `json:"name"`
-				Description string                 `json:"description"`
-				Enabled     bool                   `json:"enabled"`
-				Metadata    map[string]interface{} `json:"metadata"`
-			} `json:"payload"`
-		} `json:"resource"`
-		Revision string `json:"revision"`
-	}
-
-	if err := json.Unmarshal(body, &response); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s", err))
+		handleReadError(ctx, resp, "flag", data.Key.ValueString(), err)
 		return
 	}
 
-	flag := response.Resource.Payload
+	// The name is read as well so that imported flags and changes made
+	// outside of Terraform show up in the state
+	data.Name = types.StringValue(flag.Name)
 
-	// Don't overwrite Required fields (namespace_key, key, name) - preserve from state
-	// Only update Optional and Computed fields
 	if flag.Description != "" {
 		data.Description = types.StringValue(flag.Description)
 	} else {
@@ -350,20 +269,15 @@ func (r *FlagResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	data.Enabled = types.BoolValue(flag.Enabled)
 	data.Type = types.StringValue(flag.Type)
 
-	// Update metadata
-	if len(flag.Metadata) > 0 {
-		metadataMap := make(map[string]string)
-		for k, v := range flag.Metadata {
-			metadataMap[k] = fmt.Sprintf("%v", v)
-		}
-		metadataValue, diags := types.MapValueFrom(ctx, types.StringType, metadataMap)
-		if diags.HasError() {
-			resp.Diagnostics.Append(diags...)
+	// Keep the metadata in state when it holds the same JSON as the server,
+	// so that a map written as an object literal is not reported as a change
+	if !flagMetadataEqual(ctx, data.Metadata, flag.Metadata) {
+		metadataValue, diags := flagMetadataToModel(ctx, flag.Metadata)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
 			return
 		}
 		data.Metadata = metadataValue
-	} else {
-		data.Metadata = types.MapNull(types.StringType)
 	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -376,12 +290,10 @@ func (r *FlagResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
-	// Default to "default" environment if not specified
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
 	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Updating flag", map[string]interface{}{
 		"environment_key": envKey,
@@ -390,100 +302,35 @@ func (r *FlagResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		"name":            data.Name.ValueString(),
 	})
 
-	// Build flag payload
-	flagPayload := map[string]interface{}{
-		"@type":   "flipt.core.Flag",
-		"key":     data.Key.ValueString(),
-		"name":    data.Name.ValueString(),
-		"type":    data.Type.ValueString(),
-		"enabled": data.Enabled.ValueBool(),
-	}
-
-	if !data.Description.IsNull() && !data.Description.IsUnknown() {
-		flagPayload["description"] = data.Description.ValueString()
-	}
-
-	// Add metadata if provided
-	if !data.Metadata.IsNull() && !data.Metadata.IsUnknown() {
-		metadataMap := make(map[string]string)
-		diags := data.Metadata.ElementsAs(ctx, &metadataMap, false)
-		if diags.HasError() {
-			resp.Diagnostics.Append(diags...)
-			return
-		}
-		if len(metadataMap) > 0 {
-			metadata := make(map[string]interface{})
-			for k, v := range metadataMap {
-				metadata[k] = v
-			}
-			flagPayload["metadata"] = metadata
-		}
-	}
-
-	// Wrap in resources API format
-	updateReq := map[string]interface{}{
-		"key":     data.Key.ValueString(),
-		"payload": flagPayload,
-	}
-
-	reqBody, err := json.Marshal(updateReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Serialization Error", fmt.Sprintf("Unable to marshal request: %s", err))
-		return
-	}
-
-	// PUT URL doesn't include the flipt.core.Flag prefix
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources", r.config.Endpoint, envKey, data.NamespaceKey.ValueString())
-	httpReq, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewReader(reqBody))
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	r.config.AddAuthHeader(httpReq)
-
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update flag, got error: %s", err))
+	var metadata client.Flag
+	resp.Diagnostics.Append(flagMetadataFromModel(ctx, data.Metadata, &metadata)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	defer httpResp.Body.Close()
 
-	body, err := io.ReadAll(httpResp.Body)
+	// Hold the flag while merging so variant, rule and rollout changes are
+	// not lost
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.Key.ValueString())
+	defer unlock()
+
+	// Replace the flag's own fields, preserving variants, rules, rollouts and
+	// the default variant
+	flag, err := r.config.Client.UpdateFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.Key.ValueString(), func(flag *client.Flag) error {
+		flag.Name = data.Name.ValueString()
+		flag.Type = data.Type.ValueString()
+		flag.Enabled = data.Enabled.ValueBool()
+		flag.Description = ""
+		if !data.Description.IsNull() && !data.Description.IsUnknown() {
+			flag.Description = data.Description.ValueString()
+		}
+		flag.Metadata = metadata.Metadata
+		return nil
+	})
 	if err != nil {
-		resp.Diagnostics.AddError("Read Error", fmt.Sprintf("Unable to read response: %s", err))
-		return
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to update flag, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	// Parse response
-	var response struct {
-		Resource struct {
-			NamespaceKey string `json:"namespaceKey"`
-			Key          string `json:"key"`
-			Payload      struct {
-				Type        string                 `json:"type"`
-				Key         string                 `json:"key"`
-				Name        string                 `json:"name"`
-				Description string                 `json:"description"`
-				Enabled     bool                   `json:"enabled"`
-				Metadata    map[string]interface{} `json:"metadata"`
-			} `json:"payload"`
-		} `json:"resource"`
-		Revision string `json:"revision"`
-	}
-
-	if err := json.Unmarshal(body, &response); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s", err))
+		addParentWriteError(&resp.Diagnostics, "update flag", "flag", data.Key.ValueString(), err)
 		return
 	}
 
-	flag := response.Resource.Payload
-
 	// Update optional and computed fields
 	if flag.Description != "" {
 		data.Description = types.StringValue(flag.Description)
@@ -494,21 +341,8 @@ func (r *FlagResource) Update(ctx context.Context, req resource.UpdateRequest, r
 	data.Enabled = types.BoolValue(flag.Enabled)
 	data.Type = types.StringValue(flag.Type)
 
-	// Update metadata
-	if len(flag.Metadata) > 0 {
-		metadataMap := make(map[string]string)
-		for k, v := range flag.Metadata {
-			metadataMap[k] = fmt.Sprintf("%v", v)
-		}
-		metadataValue, diags := types.MapValueFrom(ctx, types.StringType, metadataMap)
-		if diags.HasError() {
-			resp.Diagnostics.Append(diags...)
-			return
-		}
-		data.Metadata = metadataValue
-	} else {
-		data.Metadata = types.MapNull(types.StringType)
-	}
+	// Metadata is stored as sent, the planned value is kept so that its type
+	// matches the configuration
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -526,11 +360,10 @@ func (r *FlagResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
-	// Default to "default" environment if not specified
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Deleting flag", map[string]interface{}{
 		"environment_key": envKey,
@@ -538,35 +371,166 @@ func (r *FlagResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		"key":             data.Key.ValueString(),
 	})
 
-	// DELETE URL includes flipt.core.Flag prefix
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Flag/%s", r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.Key.ValueString())
-	httpReq, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
+	err := r.config.Client.DeleteResource(ctx, envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.Key.ValueString())
+	if err != nil && !errors.Is(err, client.ErrNotFound) {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete flag, got error: %s", err))
 		return
 	}
-	r.config.AddAuthHeader(httpReq)
 
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete flag, got error: %s", err))
+	tflog.Trace(ctx, "deleted a flag resource")
+}
+
+// ImportState imports a flag by an ID of the form
+// environment_key/namespace_key/flag_key.
+func (r *FlagResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts, ok := parseImportID(req, resp, "environment_key", "namespace_key", "flag_key")
+	if !ok {
 		return
 	}
-	defer httpResp.Body.Close()
 
-	if httpResp.StatusCode == http.StatusNotFound {
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("environment_key"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("namespace_key"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("key"), parts[2])...)
+}
+
+// ValidateConfig checks that the metadata is an object.
+func (r *FlagResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var metadata types.Dynamic
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("metadata"), &metadata)...)
+	if resp.Diagnostics.HasError() || metadata.IsNull() || metadata.IsUnknown() || metadata.IsUnderlyingValueNull() || metadata.IsUnderlyingValueUnknown() {
 		return
 	}
 
-	if httpResp.StatusCode != http.StatusOK && httpResp.StatusCode != http.StatusNoContent {
-		body, _ := io.ReadAll(httpResp.Body)
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to delete flag, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
+	switch metadata.UnderlyingValue().(type) {
+	case types.Object, types.Map:
+	default:
+		resp.Diagnostics.AddAttributeError(path.Root("metadata"), "Invalid Flag Metadata",
+			fmt.Sprintf("Metadata must be an object, such as { owner = \"team-a\" }, got: %s", metadata.UnderlyingValue().Type(ctx)))
 	}
+}
 
-	tflog.Trace(ctx, "deleted a flag resource")
+// UpgradeState migrates flags stored while metadata was a map of strings.
+func (r *FlagResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: &schema.Schema{
+				Attributes: map[string]schema.Attribute{
+					"namespace_key":   schema.StringAttribute{Optional: true, Computed: true},
+					"environment_key": schema.StringAttribute{Optional: true, Computed: true},
+					"key":             schema.StringAttribute{Required: true},
+					"name":            schema.StringAttribute{Required: true},
+					"description":     schema.StringAttribute{Optional: true},
+					"enabled":         schema.BoolAttribute{Optional: true, Computed: true},
+					"type":            schema.StringAttribute{Optional: true, Computed: true},
+					"metadata":        schema.MapAttribute{Optional: true, ElementType: types.StringType},
+				},
+			},
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var prior flagResourceModelV0
+				resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				data := FlagResourceModel{
+					NamespaceKey:   prior.NamespaceKey,
+					EnvironmentKey: prior.EnvironmentKey,
+					Key:            prior.Key,
+					Name:           prior.Name,
+					Description:    prior.Description,
+					Enabled:        prior.Enabled,
+					Type:           prior.Type,
+					Metadata:       types.DynamicNull(),
+				}
+
+				// A map literal in the configuration is an object, upgrade to
+				// an object of strings so that the plan shows no change
+				if !prior.Metadata.IsNull() {
+					attrTypes := make(map[string]attr.Type, len(prior.Metadata.Elements()))
+					for k := range prior.Metadata.Elements() {
+						attrTypes[k] = types.StringType
+					}
+					metadata, diags := types.ObjectValue(attrTypes, prior.Metadata.Elements())
+					resp.Diagnostics.Append(diags...)
+					if resp.Diagnostics.HasError() {
+						return
+					}
+					data.Metadata = types.DynamicValue(metadata)
+				}
+
+				resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+			},
+		},
+	}
 }
 
-func (r *FlagResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("key"), req, resp)
+// flagResourceModelV0 is the state of a flag in schema version 0.
+type flagResourceModelV0 struct {
+	NamespaceKey   types.String `tfsdk:"namespace_key"`
+	EnvironmentKey types.String `tfsdk:"environment_key"`
+	Key            types.String `tfsdk:"key"`
+	Name           types.String `tfsdk:"name"`
+	Description    types.String `tfsdk:"description"`
+	Enabled        types.Bool   `tfsdk:"enabled"`
+	Type           types.String `tfsdk:"type"`
+	Metadata       types.Map    `tfsdk:"metadata"`
+}
+
+// flagMetadataFromModel copies the metadata object of the model onto the flag
+// payload, keeping the JSON type of each value.
+func flagMetadataFromModel(ctx context.Context, metadata types.Dynamic, flag *client.Flag) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if metadata.IsNull() || metadata.IsUnknown() || metadata.IsUnderlyingValueNull() || metadata.IsUnderlyingValueUnknown() {
+		return diags
+	}
+
+	value, err := metadataFromValue(ctx, metadata)
+	if err != nil {
+		diags.AddAttributeError(path.Root("metadata"), "Invalid Flag Metadata", fmt.Sprintf("Unable to convert the flag metadata: %s", err))
+		return diags
+	}
+	metadataMap, ok := value.(map[string]interface{})
+	if !ok {
+		diags.AddAttributeError(path.Root("metadata"), "Invalid Flag Metadata", "Metadata must be an object")
+		return diags
+	}
+
+	if len(metadataMap) > 0 {
+		flag.Metadata = metadataMap
+	}
+
+	return diags
+}
+
+// flagMetadataToModel converts flag metadata returned by the server into a
+// dynamic object, or null when there is none.
+func flagMetadataToModel(ctx context.Context, metadata map[string]interface{}) (types.Dynamic, diag.Diagnostics) {
+	if len(metadata) == 0 {
+		return types.DynamicNull(), nil
+	}
+
+	value, diags := metadataToValue(ctx, metadata)
+	return types.DynamicValue(value), diags
+}
+
+// flagMetadataEqual reports whether the metadata of the model holds the same
+// JSON as the metadata returned by the server.
+func flagMetadataEqual(ctx context.Context, metadata types.Dynamic, server map[string]interface{}) bool {
+	if metadata.IsNull() || metadata.IsUnderlyingValueNull() {
+		return false
+	}
+
+	value, err := metadataFromValue(ctx, metadata)
+	if err != nil {
+		return false
+	}
+	metadataMap, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	if len(metadataMap) == 0 && len(server) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(metadataMap, server)
 }
@@ -4,12 +4,26 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"regexp"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"terraform-provider-flipt/internal/client"
 )
 
 func TestAccFlagResource(t *testing.T) {
@@ -29,6 +43,13 @@ func TestAccFlagResource(t *testing.T) {
 					resource.TestCheckResourceAttr("flipt_flag.test", "type", "VARIANT_FLAG_TYPE"),
 				),
 			},
+			// ImportState testing
+			{
+				ResourceName:      "flipt_flag.test",
+				ImportState:       true,
+				ImportStateId:     "default/test-namespace/test-flag",
+				ImportStateVerify: true,
+			},
 			// Update and Read testing
 			{
 				Config: testAccFlagResourceConfig("default", "test-namespace", "test-flag", "Updated Flag", false, "VARIANT_FLAG_TYPE"),
@@ -64,6 +85,649 @@ resource "flipt_flag" "test" {
 `
 }
 
+func TestAccFlagResourceProviderDefaults(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFlagResourceProviderDefaultsConfig("defaults-namespace"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						// The inherited keys are known at plan time.
+						plancheck.ExpectKnownValue("flipt_flag.test", tfjsonpath.New("environment_key"), knownvalue.StringExact("default")),
+						plancheck.ExpectKnownValue("flipt_flag.test", tfjsonpath.New("namespace_key"), knownvalue.StringExact("defaults-namespace")),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_flag.test", "environment_key", "default"),
+					resource.TestCheckResourceAttr("flipt_flag.test", "namespace_key", "defaults-namespace"),
+				),
+			},
+			// Changing the provider default moves the flag to the new namespace
+			{
+				Config: testAccFlagResourceProviderDefaultsConfig("defaults-namespace-2"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("flipt_flag.test", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("flipt_flag.test", "namespace_key", "defaults-namespace-2"),
+			},
+		},
+	})
+}
+
+func testAccFlagResourceProviderDefaultsConfig(namespaceKey string) string {
+	return `
+provider "flipt" {
+  endpoint              = "` + getTestFliptEndpoint() + `"
+  default_namespace_key = "` + namespaceKey + `"
+}
+
+resource "flipt_namespace" "test" {
+  key  = "` + namespaceKey + `"
+  name = "Defaults Namespace"
+}
+
+resource "flipt_flag" "test" {
+  key  = "defaults-flag"
+  name = "Defaults Flag"
+
+  depends_on = [flipt_namespace.test]
+}
+`
+}
+
+func TestAccFlagResourceUpdateKeepsChildren(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFlagResourceUpdateKeepsChildrenConfig("Children Flag", true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_flag.variant", "enabled", "true"),
+					resource.TestCheckResourceAttr("flipt_flag.boolean", "enabled", "true"),
+				),
+			},
+			// Renaming and toggling the flags must leave their variants,
+			// rules, distributions, default variant and rollouts in place.
+			// Anything dropped shows up as a non-empty plan after the apply.
+			{
+				Config: testAccFlagResourceUpdateKeepsChildrenConfig("Renamed Children Flag", false),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("flipt_flag.variant", plancheck.ResourceActionUpdate),
+						plancheck.ExpectResourceAction("flipt_flag.boolean", plancheck.ResourceActionUpdate),
+						plancheck.ExpectResourceAction("flipt_variant.blue", plancheck.ResourceActionNoop),
+						plancheck.ExpectResourceAction("flipt_rule.beta", plancheck.ResourceActionNoop),
+						plancheck.ExpectResourceAction("flipt_rollout.threshold", plancheck.ResourceActionNoop),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_flag.variant", "name", "Renamed Children Flag"),
+					resource.TestCheckResourceAttr("flipt_flag.variant", "enabled", "false"),
+					resource.TestCheckResourceAttr("flipt_flag.boolean", "enabled", "false"),
+					resource.TestCheckResourceAttr("flipt_variant.blue", "key", "blue"),
+					resource.TestCheckResourceAttr("flipt_distribution.blue", "rollout", "100"),
+					resource.TestCheckResourceAttr("flipt_flag_default_variant.variant", "variant_key", "blue"),
+					resource.TestCheckResourceAttr("flipt_rollout.threshold", "percentage", "25"),
+				),
+			},
+			// And toggling back
+			{
+				Config: testAccFlagResourceUpdateKeepsChildrenConfig("Children Flag", true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_flag.variant", "enabled", "true"),
+					resource.TestCheckResourceAttr("flipt_rule.beta", "rank", "0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccFlagResourceUpdateKeepsChildrenConfig(name string, enabled bool) string {
+	return `
+provider "flipt" {
+  endpoint = "` + getTestFliptEndpoint() + `"
+}
+
+resource "flipt_namespace" "test" {
+  key  = "children-namespace"
+  name = "Children Namespace"
+}
+
+resource "flipt_segment" "beta" {
+  namespace_key = flipt_namespace.test.key
+  key           = "beta"
+  name          = "Beta"
+  match_type    = "ANY_MATCH_TYPE"
+}
+
+resource "flipt_flag" "variant" {
+  namespace_key = flipt_namespace.test.key
+  key           = "children-flag"
+  name          = "` + name + `"
+  enabled       = ` + boolToString(enabled) + `
+  type          = "VARIANT_FLAG_TYPE"
+}
+
+resource "flipt_variant" "blue" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.variant.key
+  key           = "blue"
+  name          = "Blue"
+}
+
+resource "flipt_rule" "beta" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.variant.key
+  segment_keys  = [flipt_segment.beta.key]
+}
+
+resource "flipt_distribution" "blue" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.variant.key
+  rule_id       = flipt_rule.beta.id
+  variant_key   = flipt_variant.blue.key
+  rollout       = 100
+}
+
+resource "flipt_flag_default_variant" "variant" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.variant.key
+  variant_key   = flipt_variant.blue.key
+}
+
+resource "flipt_flag" "boolean" {
+  namespace_key = flipt_namespace.test.key
+  key           = "children-boolean-flag"
+  name          = "Children Boolean Flag"
+  enabled       = ` + boolToString(enabled) + `
+  type          = "BOOLEAN_FLAG_TYPE"
+}
+
+resource "flipt_rollout" "threshold" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.boolean.key
+  rank          = 0
+  type          = "THRESHOLD_ROLLOUT_TYPE"
+  percentage    = 25
+  value         = true
+}
+`
+}
+
+func TestFlagResourceUpdateKeepsChildren(t *testing.T) {
+	fake := newFakeFlipt(t)
+	variants := []interface{}{map[string]interface{}{
+		"key":         "blue",
+		"name":        "Blue",
+		"description": "",
+		"attachment":  map[string]interface{}{"color": "blue"},
+	}}
+	rules := []interface{}{map[string]interface{}{
+		"id":              "5c9d4e8a-1a2b-4c3d-8e9f-0a1b2c3d4e5f",
+		"segments":        []interface{}{"beta"},
+		"segmentOperator": "OR_SEGMENT_OPERATOR",
+		"rank":            float64(0),
+		"distributions":   []interface{}{map[string]interface{}{"variant": "blue", "rollout": float64(100)}},
+	}}
+	rollouts := []interface{}{map[string]interface{}{
+		"type":      "THRESHOLD_ROLLOUT_TYPE",
+		"threshold": map[string]interface{}{"percentage": float64(25), "value": true},
+	}}
+	fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+		"name":           "Checkout",
+		"type":           "VARIANT_FLAG_TYPE",
+		"enabled":        true,
+		"variants":       variants,
+		"rules":          rules,
+		"rollouts":       rollouts,
+		"defaultVariant": "blue",
+		"metadata":       map[string]interface{}{"owner": "team-a"},
+	})
+
+	r := NewFlagResource()
+	configureResource(t, r, fake.config())
+
+	state := &FlagResourceModel{
+		NamespaceKey:   types.StringValue("default"),
+		EnvironmentKey: types.StringValue("default"),
+		Key:            types.StringValue("checkout"),
+		Name:           types.StringValue("Checkout"),
+		Description:    types.StringNull(),
+		Enabled:        types.BoolValue(true),
+		Type:           types.StringValue("VARIANT_FLAG_TYPE"),
+		Metadata:       types.DynamicNull(),
+	}
+	plan := *state
+	plan.Name = types.StringValue("Renamed Checkout")
+	plan.Description = types.StringValue("Checkout flow")
+	plan.Enabled = types.BoolValue(false)
+
+	resp, _ := updateResource(t, r, &plan, state)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Update failed: %v", resp.Diagnostics)
+	}
+
+	stored := fake.get("default", "default", client.TypeFlag, "checkout")
+	if stored["name"] != "Renamed Checkout" || stored["description"] != "Checkout flow" || stored["enabled"] != false {
+		t.Errorf("Expected the flag's own fields to be updated, got %v", stored)
+	}
+	if _, ok := stored["metadata"]; ok {
+		t.Errorf("Expected metadata removed from the configuration to be cleared, got %v", stored["metadata"])
+	}
+	for field, expected := range map[string]interface{}{
+		"variants":       variants,
+		"rules":          rules,
+		"rollouts":       rollouts,
+		"defaultVariant": "blue",
+	} {
+		if !reflect.DeepEqual(stored[field], expected) {
+			t.Errorf("Expected %s to be kept as %v, got %v", field, expected, stored[field])
+		}
+	}
+}
+
+func TestAccFlagResourceTypeChange(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFlagResourceTypeChangeConfig("VARIANT_FLAG_TYPE", true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_flag.test", "type", "VARIANT_FLAG_TYPE"),
+					resource.TestCheckResourceAttr("flipt_variant.test", "key", "blue"),
+				),
+			},
+			// The type cannot change while the flag has a variant
+			{
+				Config:      testAccFlagResourceTypeChangeConfig("BOOLEAN_FLAG_TYPE", true),
+				ExpectError: regexp.MustCompile("Flag Type Change"),
+			},
+			// The refused change left the flag and its variant alone
+			{
+				Config: testAccFlagResourceTypeChangeConfig("VARIANT_FLAG_TYPE", true),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+			{
+				Config: testAccFlagResourceTypeChangeConfig("VARIANT_FLAG_TYPE", false),
+			},
+			// Without children the type changes in place
+			{
+				Config: testAccFlagResourceTypeChangeConfig("BOOLEAN_FLAG_TYPE", false),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("flipt_flag.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("flipt_flag.test", "type", "BOOLEAN_FLAG_TYPE"),
+			},
+			{
+				Config: testAccFlagResourceTypeChangeConfig("BOOLEAN_FLAG_TYPE", false),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+		},
+	})
+}
+
+func testAccFlagResourceTypeChangeConfig(flagType string, withVariant bool) string {
+	config := `
+provider "flipt" {
+  endpoint = "` + getTestFliptEndpoint() + `"
+}
+
+resource "flipt_namespace" "test" {
+  key  = "type-change-namespace"
+  name = "Type Change Namespace"
+}
+
+resource "flipt_flag" "test" {
+  namespace_key = flipt_namespace.test.key
+  key           = "type-change-flag"
+  name          = "Type Change Flag"
+  type          = "` + flagType + `"
+}
+`
+	if withVariant {
+		config += `
+resource "flipt_variant" "test" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.test.key
+  key           = "blue"
+  name          = "Blue"
+}
+`
+	}
+	return config
+}
+
+func TestFlagResourceModifyPlanTypeChange(t *testing.T) {
+	tests := []struct {
+		name      string
+		flag      map[string]interface{}
+		flagType  string
+		expectErr bool
+	}{
+		{
+			name:     "without children",
+			flag:     map[string]interface{}{},
+			flagType: "BOOLEAN_FLAG_TYPE",
+		},
+		{
+			name: "with variants",
+			flag: map[string]interface{}{
+				"variants": []interface{}{map[string]interface{}{"key": "blue"}},
+			},
+			flagType:  "BOOLEAN_FLAG_TYPE",
+			expectErr: true,
+		},
+		{
+			name: "with rules",
+			flag: map[string]interface{}{
+				"rules": []interface{}{map[string]interface{}{"segments": []interface{}{"beta"}, "segmentOperator": "OR_SEGMENT_OPERATOR"}},
+			},
+			flagType:  "BOOLEAN_FLAG_TYPE",
+			expectErr: true,
+		},
+		{
+			name: "unchanged type with children",
+			flag: map[string]interface{}{
+				"variants": []interface{}{map[string]interface{}{"key": "blue"}},
+			},
+			flagType: "VARIANT_FLAG_TYPE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			fake := newFakeFlipt(t)
+			flag := map[string]interface{}{"name": "My Flag", "type": "VARIANT_FLAG_TYPE", "enabled": true}
+			for k, v := range tt.flag {
+				flag[k] = v
+			}
+			fake.put("default", "default", client.TypeFlag, "my-flag", flag)
+
+			r := &FlagResource{}
+			configureResource(t, r, fake.config())
+
+			schemaResp := &fwresource.SchemaResponse{}
+			r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+			s := schemaResp.Schema
+
+			state := flagModel(types.StringValue("default"), types.StringValue("default"))
+			planned := state
+			planned.Type = types.StringValue(tt.flagType)
+
+			req := fwresource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: s},
+				Plan:   tfsdk.Plan{Schema: s},
+				State:  tfsdk.State{Schema: s},
+			}
+			req.Config.Raw = mustModelValue(t, ctx, req.Plan, planned)
+			req.Plan.Raw = req.Config.Raw
+			req.State.Raw = mustModelValue(t, ctx, req.Plan, state)
+
+			resp := &fwresource.ModifyPlanResponse{Plan: req.Plan}
+			r.ModifyPlan(ctx, req, resp)
+			if resp.Diagnostics.HasError() != tt.expectErr {
+				t.Fatalf("Expected error: %v, got: %v", tt.expectErr, resp.Diagnostics)
+			}
+			if tt.expectErr && resp.Diagnostics.Errors()[0].Summary() != "Flag Type Change" {
+				t.Errorf("Expected a Flag Type Change error, got %v", resp.Diagnostics)
+			}
+			if len(resp.RequiresReplace) > 0 {
+				t.Errorf("Expected the flag to be updated in place, got replacement of %v", resp.RequiresReplace)
+			}
+		})
+	}
+}
+
+func TestAccFlagResourceMetadata(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFlagResourceMetadataConfig(`{
+    owner    = "team-a"
+    priority = 1
+    beta     = true
+    tags     = ["checkout", "payments"]
+    limits   = { max = 10 }
+  }`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("flipt_flag.test", tfjsonpath.New("metadata").AtMapKey("priority"), knownvalue.Int64Exact(1)),
+					statecheck.ExpectKnownValue("flipt_flag.test", tfjsonpath.New("metadata").AtMapKey("beta"), knownvalue.Bool(true)),
+					statecheck.ExpectKnownValue("flipt_flag.test", tfjsonpath.New("metadata").AtMapKey("limits").AtMapKey("max"), knownvalue.Int64Exact(10)),
+				},
+			},
+			// ImportState testing
+			{
+				ResourceName:      "flipt_flag.test",
+				ImportState:       true,
+				ImportStateId:     "default/metadata-namespace/metadata-flag",
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccFlagResourceMetadataConfig(`{ owner = "team-b" }`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("flipt_flag.test", tfjsonpath.New("metadata"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"owner": knownvalue.StringExact("team-b"),
+					})),
+				},
+			},
+		},
+	})
+}
+
+func testAccFlagResourceMetadataConfig(metadata string) string {
+	return `
+provider "flipt" {
+  endpoint = "` + getTestFliptEndpoint() + `"
+}
+
+resource "flipt_namespace" "test" {
+  key  = "metadata-namespace"
+  name = "Metadata Namespace"
+}
+
+resource "flipt_flag" "test" {
+  namespace_key = flipt_namespace.test.key
+  key           = "metadata-flag"
+  name          = "Metadata Flag"
+  metadata      = ` + metadata + `
+}
+`
+}
+
+func TestFlagResourceMetadata(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeFlipt(t)
+
+	r := NewFlagResource()
+	configureResource(t, r, fake.config())
+
+	limits, _ := types.ObjectValue(map[string]attr.Type{"max": types.NumberType}, map[string]attr.Value{"max": types.NumberValue(big.NewFloat(10))})
+	tags, _ := types.TupleValue([]attr.Type{types.StringType, types.StringType}, []attr.Value{types.StringValue("checkout"), types.StringValue("payments")})
+	metadata, _ := types.ObjectValue(map[string]attr.Type{
+		"owner":    types.StringType,
+		"priority": types.NumberType,
+		"beta":     types.BoolType,
+		"tags":     tags.Type(ctx),
+		"limits":   limits.Type(ctx),
+	}, map[string]attr.Value{
+		"owner":    types.StringValue("team-a"),
+		"priority": types.NumberValue(big.NewFloat(1)),
+		"beta":     types.BoolValue(true),
+		"tags":     tags,
+		"limits":   limits,
+	})
+
+	model := &FlagResourceModel{
+		NamespaceKey:   types.StringValue("default"),
+		EnvironmentKey: types.StringValue("default"),
+		Key:            types.StringValue("checkout"),
+		Name:           types.StringValue("Checkout"),
+		Description:    types.StringNull(),
+		Enabled:        types.BoolValue(true),
+		Type:           types.StringValue("VARIANT_FLAG_TYPE"),
+		Metadata:       types.DynamicValue(metadata),
+	}
+	resp, _ := createResource(t, r, model)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Create failed: %v", resp.Diagnostics)
+	}
+
+	// Values are stored with their JSON type
+	stored := fake.get("default", "default", client.TypeFlag, "checkout")["metadata"]
+	expected := map[string]interface{}{
+		"owner":    "team-a",
+		"priority": float64(1),
+		"beta":     true,
+		"tags":     []interface{}{"checkout", "payments"},
+		"limits":   map[string]interface{}{"max": float64(10)},
+	}
+	if !reflect.DeepEqual(stored, expected) {
+		t.Fatalf("Expected stored metadata %#v, got %#v", expected, stored)
+	}
+
+	readResp, state := readResource(t, r, model)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("Read failed: %v", readResp.Diagnostics)
+	}
+	var read FlagResourceModel
+	state.Get(ctx, &read)
+	if !read.Metadata.Equal(model.Metadata) {
+		t.Errorf("Expected metadata %s, got %s", model.Metadata, read.Metadata)
+	}
+
+	// A change made outside of Terraform is read with its type
+	fake.get("default", "default", client.TypeFlag, "checkout")["metadata"].(map[string]interface{})["priority"] = float64(2)
+	_, state = readResource(t, r, model)
+	state.Get(ctx, &read)
+	object, ok := read.Metadata.UnderlyingValue().(types.Object)
+	if !ok {
+		t.Fatalf("Expected metadata to be an object, got %s", read.Metadata)
+	}
+	priority, _ := object.Attributes()["priority"].(types.Number)
+	if f, _ := priority.ValueBigFloat().Float64(); f != 2 {
+		t.Errorf("Expected priority 2, got %s", priority)
+	}
+}
+
+func TestFlagResourceMetadataMap(t *testing.T) {
+	fake := newFakeFlipt(t)
+
+	r := NewFlagResource()
+	configureResource(t, r, fake.config())
+
+	// A map typed value, for example from tomap(), is kept as a map
+	metadata, _ := types.MapValue(types.StringType, map[string]attr.Value{"owner": types.StringValue("team-a")})
+	model := &FlagResourceModel{
+		NamespaceKey:   types.StringValue("default"),
+		EnvironmentKey: types.StringValue("default"),
+		Key:            types.StringValue("checkout"),
+		Name:           types.StringValue("Checkout"),
+		Description:    types.StringNull(),
+		Enabled:        types.BoolValue(true),
+		Type:           types.StringValue("VARIANT_FLAG_TYPE"),
+		Metadata:       types.DynamicValue(metadata),
+	}
+	if resp, _ := createResource(t, r, model); resp.Diagnostics.HasError() {
+		t.Fatalf("Create failed: %v", resp.Diagnostics)
+	}
+
+	_, state := readResource(t, r, model)
+	var read FlagResourceModel
+	state.Get(context.Background(), &read)
+	if !read.Metadata.Equal(model.Metadata) {
+		t.Errorf("Expected metadata %s, got %s", model.Metadata, read.Metadata)
+	}
+}
+
+func TestFlagResourceMetadataValidateConfig(t *testing.T) {
+	model := func(metadata types.Dynamic) *FlagResourceModel {
+		return &FlagResourceModel{
+			NamespaceKey:   types.StringNull(),
+			EnvironmentKey: types.StringNull(),
+			Key:            types.StringValue("checkout"),
+			Name:           types.StringValue("Checkout"),
+			Description:    types.StringNull(),
+			Enabled:        types.BoolNull(),
+			Type:           types.StringNull(),
+			Metadata:       metadata,
+		}
+	}
+	object, _ := types.ObjectValue(map[string]attr.Type{"owner": types.StringType}, map[string]attr.Value{"owner": types.StringValue("team-a")})
+
+	tests := []struct {
+		name        string
+		metadata    types.Dynamic
+		expectError bool
+	}{
+		{name: "object", metadata: types.DynamicValue(object)},
+		{name: "null", metadata: types.DynamicNull()},
+		{name: "string", metadata: types.DynamicValue(types.StringValue("team-a")), expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := validateResourceConfig(t, NewFlagResource(), model(tt.metadata))
+			if resp.Diagnostics.HasError() != tt.expectError {
+				t.Errorf("Expected error %t, got %v", tt.expectError, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestFlagResourceUpgradeState(t *testing.T) {
+	str := func(v string) tftypes.Value { return tftypes.NewValue(tftypes.String, v) }
+
+	resp, state := upgradeResourceState(t, NewFlagResource(), 0, map[string]tftypes.Value{
+		"namespace_key":   str("default"),
+		"environment_key": str("default"),
+		"key":             str("checkout"),
+		"name":            str("Checkout"),
+		"description":     tftypes.NewValue(tftypes.String, nil),
+		"enabled":         tftypes.NewValue(tftypes.Bool, true),
+		"type":            str("VARIANT_FLAG_TYPE"),
+		"metadata": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{
+			"owner":    str("team-a"),
+			"priority": str("1"),
+		}),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Upgrade failed: %v", resp.Diagnostics)
+	}
+
+	var data FlagResourceModel
+	state.Get(context.Background(), &data)
+
+	expected, _ := types.ObjectValue(
+		map[string]attr.Type{"owner": types.StringType, "priority": types.StringType},
+		map[string]attr.Value{"owner": types.StringValue("team-a"), "priority": types.StringValue("1")},
+	)
+	if !data.Metadata.Equal(types.DynamicValue(expected)) {
+		t.Errorf("Expected metadata %s, got %s", expected, data.Metadata)
+	}
+	if data.Key.ValueString() != "checkout" || !data.Enabled.ValueBool() {
+		t.Errorf("Unexpected upgraded state: %+v", data)
+	}
+}
+
 func boolToString(b bool) string {
 	if b {
 		return "true"
@@ -0,0 +1,189 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
+)
+
+var _ datasource.DataSource = &FlagRulesDataSource{}
+
+func NewFlagRulesDataSource() datasource.DataSource {
+	return &FlagRulesDataSource{}
+}
+
+type FlagRulesDataSource struct {
+	config *FliptProviderConfig
+}
+
+type FlagRulesDataSourceModel struct {
+	NamespaceKey   types.String                   `tfsdk:"namespace_key"`
+	EnvironmentKey types.String                   `tfsdk:"environment_key"`
+	FlagKey        types.String                   `tfsdk:"flag_key"`
+	Rules          []FlagRulesDataSourceRuleModel `tfsdk:"rules"`
+}
+
+type FlagRulesDataSourceRuleModel struct {
+	ID              types.String `tfsdk:"id"`
+	Rank            types.Int64  `tfsdk:"rank"`
+	SegmentKeys     types.List   `tfsdk:"segment_keys"`
+	SegmentOperator types.String `tfsdk:"segment_operator"`
+	Distributions   types.List   `tfsdk:"distributions"`
+}
+
+func (d *FlagRulesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flag_rules"
+}
+
+func (d *FlagRulesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reads the ordered rules of a flag. Besides their `id` and `rank`, the rules have the shape taken by the `rules` of `flipt_flag_rules`",
+		Description:         "Reads the ordered rules of a flag",
+
+		Attributes: map[string]schema.Attribute{
+			"namespace_key": schema.StringAttribute{
+				MarkdownDescription: "Namespace key where the flag belongs. Defaults to the provider's `default_namespace_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+			},
+			"environment_key": schema.StringAttribute{
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+			},
+			"flag_key": schema.StringAttribute{
+				MarkdownDescription: "Key of the flag whose rules are read",
+				Required:            true,
+			},
+			"rules": schema.ListNestedAttribute{
+				MarkdownDescription: "Rules of the flag in evaluation order",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Identifier of the rule, as used by the `id` of `flipt_rule` and the `rule_id` of `flipt_distribution`",
+							Computed:            true,
+						},
+						"rank": schema.Int64Attribute{
+							MarkdownDescription: "Position of the rule in the flag's rules, starting at 0. It is also the index of the rule in the list",
+							Computed:            true,
+						},
+						"segment_keys": schema.ListAttribute{
+							ElementType:         types.StringType,
+							MarkdownDescription: "Keys of the segments evaluated by the rule",
+							Computed:            true,
+						},
+						"segment_operator": schema.StringAttribute{
+							MarkdownDescription: "Operator combining the segments (OR_SEGMENT_OPERATOR or AND_SEGMENT_OPERATOR)",
+							Computed:            true,
+						},
+						"distributions": schema.ListNestedAttribute{
+							MarkdownDescription: "Variants served to the rule's matches, null when the rule has none",
+							Computed:            true,
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"variant_key": schema.StringAttribute{
+										MarkdownDescription: "Key of the variant served to this share of the rule's matches",
+										Computed:            true,
+									},
+									"rollout": schema.Float64Attribute{
+										MarkdownDescription: "Percentage of the rule's matches served the variant",
+										Computed:            true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *FlagRulesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerConfig, ok := req.ProviderData.(*FliptProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *FliptProviderConfig, got: %T", req.ProviderData),
+		)
+		return
+	}
+
+	d.config = providerConfig
+}
+
+func (d *FlagRulesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FlagRulesDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the data source
+	envKey := d.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(d.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Reading flag rules", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+	})
+
+	flag, err := d.config.Client.GetFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			resp.Diagnostics.AddError("Not Found", fmt.Sprintf("Flag with key '%s' not found in namespace '%s'", data.FlagKey.ValueString(), data.NamespaceKey.ValueString()))
+			return
+		}
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read flag, got error: %s", err))
+		return
+	}
+
+	rules, diags := rulesToModel(ctx, flag.Rules, nil)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var ruleModels []flagRuleModel
+	resp.Diagnostics.Append(rules.ElementsAs(ctx, &ruleModels, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Rules = make([]FlagRulesDataSourceRuleModel, 0, len(ruleModels))
+	for i, rule := range ruleModels {
+		// Rules without a stored ID are referenced by their rank, as
+		// flipt_rule does
+		id := flag.Rules[i].ID
+		if id == "" {
+			id = fmt.Sprintf("%s/%d", data.FlagKey.ValueString(), i)
+		}
+
+		data.Rules = append(data.Rules, FlagRulesDataSourceRuleModel{
+			ID:              types.StringValue(id),
+			Rank:            types.Int64Value(int64(i)),
+			SegmentKeys:     rule.SegmentKeys,
+			SegmentOperator: rule.SegmentOperator,
+			Distributions:   rule.Distributions,
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
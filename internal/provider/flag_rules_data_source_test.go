@@ -0,0 +1,192 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"terraform-provider-flipt/internal/client"
+)
+
+func TestAccFlagRulesDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFlagRulesDataSourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.flipt_flag_rules.test", "rules.#", "2"),
+					resource.TestCheckResourceAttr("data.flipt_flag_rules.test", "rules.0.rank", "0"),
+					resource.TestCheckResourceAttrSet("data.flipt_flag_rules.test", "rules.0.id"),
+					resource.TestCheckResourceAttr("data.flipt_flag_rules.test", "rules.1.rank", "1"),
+					resource.TestCheckResourceAttr("data.flipt_flag_rules.test", "rules.0.segment_keys.#", "2"),
+					resource.TestCheckResourceAttr("data.flipt_flag_rules.test", "rules.0.segment_operator", "AND_SEGMENT_OPERATOR"),
+					resource.TestCheckResourceAttr("data.flipt_flag_rules.test", "rules.0.distributions.0.variant_key", "blue"),
+					resource.TestCheckResourceAttr("data.flipt_flag_rules.test", "rules.0.distributions.0.rollout", "100"),
+					resource.TestCheckResourceAttr("data.flipt_flag_rules.test", "rules.1.segment_keys.0", "internal"),
+					resource.TestCheckNoResourceAttr("data.flipt_flag_rules.test", "rules.1.distributions"),
+				),
+			},
+		},
+	})
+}
+
+func testAccFlagRulesDataSourceConfig() string {
+	return `
+provider "flipt" {
+  endpoint = "` + getTestFliptEndpoint() + `"
+}
+
+resource "flipt_namespace" "test" {
+  key           = "flag-rules-data-source"
+  name          = "Flag Rules Data Source"
+  force_destroy = true
+}
+
+resource "flipt_segment" "beta" {
+  namespace_key = flipt_namespace.test.key
+  key           = "beta"
+  name          = "Beta"
+}
+
+resource "flipt_segment" "internal" {
+  namespace_key = flipt_namespace.test.key
+  key           = "internal"
+  name          = "Internal"
+}
+
+resource "flipt_flag" "test" {
+  namespace_key = flipt_namespace.test.key
+  key           = "checkout"
+  name          = "Checkout"
+  type          = "VARIANT_FLAG_TYPE"
+}
+
+resource "flipt_variant" "blue" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.test.key
+  key           = "blue"
+  name          = "Blue"
+}
+
+resource "flipt_flag_rules" "test" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.test.key
+  rules = [
+    {
+      segment_keys     = [flipt_segment.beta.key, flipt_segment.internal.key]
+      segment_operator = "AND_SEGMENT_OPERATOR"
+      distributions    = [{ variant_key = flipt_variant.blue.key, rollout = 100 }]
+    },
+    {
+      segment_keys = [flipt_segment.internal.key]
+    },
+  ]
+}
+
+data "flipt_flag_rules" "test" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.test.key
+  depends_on    = [flipt_flag_rules.test]
+}
+`
+}
+
+func TestFlagRulesDataSource(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+		"name": "Checkout", "type": "VARIANT_FLAG_TYPE",
+		"variants": []interface{}{map[string]interface{}{"key": "blue"}, map[string]interface{}{"key": "green"}},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"id": "first", "segments": []interface{}{"beta", "internal"}, "segmentOperator": "AND_SEGMENT_OPERATOR", "rank": 0,
+				"distributions": []interface{}{
+					map[string]interface{}{"variant": "blue", "rollout": 25},
+					map[string]interface{}{"variant": "green", "rollout": 75},
+				},
+			},
+			map[string]interface{}{"id": "second", "segments": []interface{}{"internal"}, "segmentOperator": "OR_SEGMENT_OPERATOR", "rank": 1},
+		},
+	})
+	fake.put("default", "default", client.TypeFlag, "empty", map[string]interface{}{"name": "Empty", "type": "VARIANT_FLAG_TYPE"})
+
+	d := NewFlagRulesDataSource()
+	configureDataSource(t, d, fake.config())
+
+	resp, state := readDataSource(t, d, map[string]tftypes.Value{"flag_key": tftypes.NewValue(tftypes.String, "checkout")})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read failed: %v", resp.Diagnostics)
+	}
+
+	var data FlagRulesDataSourceModel
+	if diags := state.Get(context.Background(), &data); diags.HasError() {
+		t.Fatalf("Unable to read state: %v", diags)
+	}
+	if data.NamespaceKey.ValueString() != "default" || data.EnvironmentKey.ValueString() != "default" {
+		t.Errorf("Expected the default keys, got %s/%s", data.EnvironmentKey, data.NamespaceKey)
+	}
+
+	rules := data.Rules
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 rules, got %d", len(rules))
+	}
+	for i, id := range []string{"first", "second"} {
+		if rules[i].ID.ValueString() != id || rules[i].Rank.ValueInt64() != int64(i) {
+			t.Errorf("Expected rule %d to have id %s, got id %s and rank %s", i, id, rules[i].ID, rules[i].Rank)
+		}
+	}
+
+	var segmentKeys []string
+	rules[0].SegmentKeys.ElementsAs(context.Background(), &segmentKeys, false)
+	if !reflect.DeepEqual(segmentKeys, []string{"beta", "internal"}) || rules[0].SegmentOperator.ValueString() != "AND_SEGMENT_OPERATOR" {
+		t.Errorf("Unexpected first rule %+v", rules[0])
+	}
+	var distributions []flagRuleDistributionModel
+	rules[0].Distributions.ElementsAs(context.Background(), &distributions, false)
+	if len(distributions) != 2 || distributions[1].VariantKey.ValueString() != "green" || distributions[1].Rollout.ValueFloat64() != 75 {
+		t.Errorf("Unexpected distributions %+v", distributions)
+	}
+	if !rules[1].Distributions.IsNull() {
+		t.Errorf("Expected no distributions on the second rule, got %s", rules[1].Distributions)
+	}
+
+	resp, state = readDataSource(t, d, map[string]tftypes.Value{"flag_key": tftypes.NewValue(tftypes.String, "empty")})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read failed: %v", resp.Diagnostics)
+	}
+	if diags := state.Get(context.Background(), &data); diags.HasError() {
+		t.Fatalf("Unable to read state: %v", diags)
+	}
+	if len(data.Rules) != 0 {
+		t.Errorf("Expected no rules, got %+v", data.Rules)
+	}
+
+	// Rules without a stored ID get the id flipt_rule gives them
+	fake.put("default", "default", client.TypeFlag, "legacy", map[string]interface{}{
+		"name": "Legacy", "type": "VARIANT_FLAG_TYPE",
+		"rules": []interface{}{
+			map[string]interface{}{"segments": []interface{}{"beta"}, "segmentOperator": "OR_SEGMENT_OPERATOR", "rank": 0},
+		},
+	})
+	resp, state = readDataSource(t, d, map[string]tftypes.Value{"flag_key": tftypes.NewValue(tftypes.String, "legacy")})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read failed: %v", resp.Diagnostics)
+	}
+	if diags := state.Get(context.Background(), &data); diags.HasError() {
+		t.Fatalf("Unable to read state: %v", diags)
+	}
+	if len(data.Rules) != 1 || data.Rules[0].ID.ValueString() != "legacy/0" {
+		t.Errorf("Expected rule legacy/0, got %+v", data.Rules)
+	}
+
+	resp, _ = readDataSource(t, d, map[string]tftypes.Value{"flag_key": tftypes.NewValue(tftypes.String, "missing")})
+	if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != "Not Found" {
+		t.Errorf("Expected a Not Found error, got %v", resp.Diagnostics)
+	}
+}
@@ -0,0 +1,520 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
+)
+
+var _ resource.Resource = &FlagRulesResource{}
+var _ resource.ResourceWithImportState = &FlagRulesResource{}
+var _ resource.ResourceWithModifyPlan = &FlagRulesResource{}
+var _ resource.ResourceWithValidateConfig = &FlagRulesResource{}
+
+func NewFlagRulesResource() resource.Resource {
+	return &FlagRulesResource{}
+}
+
+// FlagRulesResource manages the complete, ordered list of rules of a flag.
+type FlagRulesResource struct {
+	config *FliptProviderConfig
+}
+
+type FlagRulesResourceModel struct {
+	NamespaceKey   types.String `tfsdk:"namespace_key"`
+	EnvironmentKey types.String `tfsdk:"environment_key"`
+	FlagKey        types.String `tfsdk:"flag_key"`
+	Rules          types.List   `tfsdk:"rules"`
+	ID             types.String `tfsdk:"id"`
+}
+
+// flagRuleModel describes a rule in the rules list of flipt_flag_rules.
+type flagRuleModel struct {
+	SegmentKeys     types.List   `tfsdk:"segment_keys"`
+	SegmentOperator types.String `tfsdk:"segment_operator"`
+	Distributions   types.List   `tfsdk:"distributions"`
+}
+
+// flagRuleDistributionModel describes a distribution of a rule in the rules
+// list of flipt_flag_rules.
+type flagRuleDistributionModel struct {
+	VariantKey types.String  `tfsdk:"variant_key"`
+	Rollout    types.Float64 `tfsdk:"rollout"`
+}
+
+var flagRuleDistributionAttrTypes = map[string]attr.Type{
+	"variant_key": types.StringType,
+	"rollout":     types.Float64Type,
+}
+
+var flagRuleAttrTypes = map[string]attr.Type{
+	"segment_keys":     types.ListType{ElemType: types.StringType},
+	"segment_operator": types.StringType,
+	"distributions":    types.ListType{ElemType: types.ObjectType{AttrTypes: flagRuleDistributionAttrTypes}},
+}
+
+func (r *FlagRulesResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flag_rules"
+}
+
+func (r *FlagRulesResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Flipt flag rules resource (the complete, ordered list of rules of a flag). " +
+			"Rules added outside of this resource are removed on the next apply, so it must not be combined with `flipt_rule` or `flipt_distribution` for the same flag",
+
+		Attributes: map[string]schema.Attribute{
+			"namespace_key": schema.StringAttribute{
+				MarkdownDescription: "Namespace key where the flag belongs. Defaults to the provider's `default_namespace_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"environment_key": schema.StringAttribute{
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"flag_key": schema.StringAttribute{
+				MarkdownDescription: "Key of the flag whose rules are managed",
+				Required:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"rules": schema.ListNestedAttribute{
+				MarkdownDescription: "Rules of the flag in evaluation order. The rank of each rule is its position in the list",
+				Required:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"segment_keys": schema.ListAttribute{
+							ElementType:         types.StringType,
+							MarkdownDescription: "List of segment keys to evaluate for this rule",
+							Required:            true,
+							Validators: []validator.List{
+								segmentKeysValidator(),
+							},
+						},
+						"segment_operator": schema.StringAttribute{
+							MarkdownDescription: "Operator for combining segments (OR_SEGMENT_OPERATOR or AND_SEGMENT_OPERATOR). AND_SEGMENT_OPERATOR needs at least two segment keys. Defaults to `OR_SEGMENT_OPERATOR`",
+							Optional:            true,
+							Computed:            true,
+							Default:             stringdefault.StaticString("OR_SEGMENT_OPERATOR"),
+							Validators: []validator.String{
+								segmentOperatorValidator(),
+							},
+						},
+						"distributions": schema.ListNestedAttribute{
+							MarkdownDescription: "Variants served to the rule's matches. The rollouts of a rule must not exceed 100",
+							Optional:            true,
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"variant_key": schema.StringAttribute{
+										MarkdownDescription: "Key of the variant served to this share of the rule's matches",
+										Required:            true,
+										Validators: []validator.String{
+											keyValidator(),
+										},
+									},
+									"rollout": schema.Float64Attribute{
+										MarkdownDescription: "Percentage of the rule's matches served the variant, between 0 and 100",
+										Required:            true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Identifier of the rules, the flag key",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *FlagRulesResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerConfig, ok := req.ProviderData.(*FliptProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *FliptProviderConfig, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.config = providerConfig
+}
+
+func (r *FlagRulesResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var rules types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("rules"), &rules)...)
+	if resp.Diagnostics.HasError() || rules.IsNull() || rules.IsUnknown() {
+		return
+	}
+
+	var ruleModels []flagRuleModel
+	resp.Diagnostics.Append(rules.ElementsAs(ctx, &ruleModels, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for i, rule := range ruleModels {
+		validateSegmentOperator(&resp.Diagnostics, path.Root("rules").AtListIndex(i), rule.SegmentOperator, rule.SegmentKeys)
+
+		if rule.Distributions.IsNull() || rule.Distributions.IsUnknown() {
+			continue
+		}
+
+		var distributions []flagRuleDistributionModel
+		resp.Diagnostics.Append(rule.Distributions.ElementsAs(ctx, &distributions, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		distributionsPath := path.Root("rules").AtListIndex(i).AtName("distributions")
+		var total float64
+		for j, d := range distributions {
+			if d.Rollout.IsNull() || d.Rollout.IsUnknown() {
+				continue
+			}
+			v := d.Rollout.ValueFloat64()
+			if v < 0 || v > maxRollout {
+				resp.Diagnostics.AddAttributeError(distributionsPath.AtListIndex(j).AtName("rollout"), "Invalid Rollout",
+					fmt.Sprintf("Rollout must be between 0 and 100, got: %g", v))
+			}
+			total += v
+		}
+		if total > maxRollout {
+			resp.Diagnostics.AddAttributeError(distributionsPath, "Invalid Rollout",
+				fmt.Sprintf("The rollouts of rule %d add up to %g%%, but must not exceed 100", i, total))
+		}
+	}
+}
+
+func (r *FlagRulesResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultKeys(ctx, r.config, true, req, resp)
+}
+
+func (r *FlagRulesResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data FlagRulesResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Creating flag rules", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+		"rules_count":     len(data.Rules.Elements()),
+	})
+
+	// Serialize with other changes to the flag's rules
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
+
+	if r.writeRules(ctx, &resp.Diagnostics, "create flag rules", envKey, data) {
+		return
+	}
+
+	data.ID = types.StringValue(data.FlagKey.ValueString())
+
+	tflog.Trace(ctx, "created a flag rules resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FlagRulesResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data FlagRulesResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Reading flag rules", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+	})
+
+	flag, err := r.config.Client.GetFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
+	if err != nil {
+		handleReadError(ctx, resp, "flag", data.FlagKey.ValueString(), err)
+		return
+	}
+
+	// Rules configured with an empty distributions list keep it, Flipt
+	// does not tell it apart from no distributions
+	var prior []flagRuleModel
+	if !data.Rules.IsNull() && !data.Rules.IsUnknown() {
+		resp.Diagnostics.Append(data.Rules.ElementsAs(ctx, &prior, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// All rules of the flag are read, so that rules added, changed or
+	// reordered outside of Terraform show up as drift
+	rules, diags := rulesToModel(ctx, flag.Rules, prior)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Rules = rules
+	data.ID = types.StringValue(data.FlagKey.ValueString())
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FlagRulesResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data FlagRulesResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Updating flag rules", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+		"rules_count":     len(data.Rules.Elements()),
+	})
+
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
+
+	if r.writeRules(ctx, &resp.Diagnostics, "update flag rules", envKey, data) {
+		return
+	}
+
+	data.ID = types.StringValue(data.FlagKey.ValueString())
+
+	tflog.Trace(ctx, "updated a flag rules resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FlagRulesResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data FlagRulesResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Deleting flag rules", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+	})
+
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
+
+	_, err := r.config.Client.UpdateFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString(), func(flag *client.Flag) error {
+		flag.Rules = nil
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			// Flag doesn't exist, its rules are already gone
+			return
+		}
+		addParentWriteError(&resp.Diagnostics, "delete flag rules", "flag", data.FlagKey.ValueString(), err)
+		return
+	}
+
+	tflog.Trace(ctx, "deleted a flag rules resource")
+}
+
+// ImportState imports the rules of a flag by an ID of the form
+// environment_key/namespace_key/flag_key.
+func (r *FlagRulesResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts, ok := parseImportID(req, resp, "environment_key", "namespace_key", "flag_key")
+	if !ok {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("environment_key"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("namespace_key"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("flag_key"), parts[2])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), parts[2])...)
+}
+
+// writeRules replaces the rules of the flag with the planned rules after
+// checking that their variants exist, and reports whether it failed.
+func (r *FlagRulesResource) writeRules(ctx context.Context, diags *diag.Diagnostics, action, envKey string, data FlagRulesResourceModel) bool {
+	rules, d := rulesFromModel(ctx, data.Rules)
+	diags.Append(d...)
+	if diags.HasError() {
+		return true
+	}
+
+	_, err := r.config.Client.UpdateFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString(), func(flag *client.Flag) error {
+		for i := range rules {
+			for _, d := range rules[i].Distributions {
+				if !hasVariant(flag.Variants, d.Variant) {
+					return fmt.Errorf("%w: variant '%s' of rule %d not found in flag '%s'", errVariantNotFound, d.Variant, i, flag.Key)
+				}
+			}
+		}
+
+		flag.Rules = keepRuleIDs(flag.Rules, rules)
+		return nil
+	})
+
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, errVariantNotFound):
+		diags.AddAttributeError(path.Root("rules"), "Variant Not Found", fmt.Sprintf("Unable to %s: %s", action, err))
+	default:
+		addParentWriteError(diags, action, "flag", data.FlagKey.ValueString(), err)
+	}
+	return true
+}
+
+// keepRuleIDs returns the new rules, each with the ID of an unchanged rule
+// among the current ones, or a new ID. Rules only keep their ID when their
+// segments and operator are unchanged.
+func keepRuleIDs(current, rules []client.Rule) []client.Rule {
+	claimed := make(map[int]bool)
+	result := make([]client.Rule, len(rules))
+	for i, rule := range rules {
+		rule.ID = uuid.New().String()
+		if j := findRule(current, "", rule.Segments, rule.SegmentOperator, int64(i)); j >= 0 && !claimed[j] && current[j].ID != "" {
+			claimed[j] = true
+			rule.ID = current[j].ID
+		}
+		rule.Rank = int64(i)
+		result[i] = rule
+	}
+	return result
+}
+
+// rulesFromModel builds the rules of a flag from the rules list of
+// flipt_flag_rules.
+func rulesFromModel(ctx context.Context, list types.List) ([]client.Rule, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var ruleModels []flagRuleModel
+	diags.Append(list.ElementsAs(ctx, &ruleModels, false)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	rules := make([]client.Rule, 0, len(ruleModels))
+	for i, m := range ruleModels {
+		rule := client.Rule{
+			SegmentOperator: m.SegmentOperator.ValueString(),
+			Rank:            int64(i),
+		}
+		diags.Append(m.SegmentKeys.ElementsAs(ctx, &rule.Segments, false)...)
+
+		var distributions []flagRuleDistributionModel
+		diags.Append(m.Distributions.ElementsAs(ctx, &distributions, false)...)
+		for _, d := range distributions {
+			rule.Distributions = append(rule.Distributions, client.Distribution{
+				Variant: d.VariantKey.ValueString(),
+				Rollout: d.Rollout.ValueFloat64(),
+			})
+		}
+
+		rules = append(rules, rule)
+	}
+	return rules, diags
+}
+
+// rulesToModel converts the rules of a flag to the rules list of
+// flipt_flag_rules. Rules without distributions have a null distributions
+// list, unless the rule at the same position in prior has an empty one.
+func rulesToModel(ctx context.Context, rules []client.Rule, prior []flagRuleModel) (types.List, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	ruleModels := make([]flagRuleModel, 0, len(rules))
+	for i, rule := range rules {
+		segmentKeys, d := types.ListValueFrom(ctx, types.StringType, rule.Segments)
+		diags.Append(d...)
+
+		distributions := types.ListNull(types.ObjectType{AttrTypes: flagRuleDistributionAttrTypes})
+		if i < len(prior) && !prior[i].Distributions.IsNull() && !prior[i].Distributions.IsUnknown() && len(prior[i].Distributions.Elements()) == 0 {
+			distributions = types.ListValueMust(types.ObjectType{AttrTypes: flagRuleDistributionAttrTypes}, []attr.Value{})
+		}
+		if len(rule.Distributions) > 0 {
+			distributionModels := make([]flagRuleDistributionModel, 0, len(rule.Distributions))
+			for _, dist := range rule.Distributions {
+				distributionModels = append(distributionModels, flagRuleDistributionModel{
+					VariantKey: types.StringValue(dist.Variant),
+					Rollout:    types.Float64Value(dist.Rollout),
+				})
+			}
+			distributions, d = types.ListValueFrom(ctx, types.ObjectType{AttrTypes: flagRuleDistributionAttrTypes}, distributionModels)
+			diags.Append(d...)
+		}
+
+		ruleModels = append(ruleModels, flagRuleModel{
+			SegmentKeys:     segmentKeys,
+			SegmentOperator: types.StringValue(rule.SegmentOperator),
+			Distributions:   distributions,
+		})
+	}
+
+	list, d := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: flagRuleAttrTypes}, ruleModels)
+	diags.Append(d...)
+	return list, diags
+}
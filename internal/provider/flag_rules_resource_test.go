@@ -0,0 +1,261 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"terraform-provider-flipt/internal/client"
+)
+
+func TestAccFlagRulesResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccFlagRulesResourceConfig(`["beta"]`, `["everyone"]`, ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_flag_rules.test", "id", "test-flag"),
+					resource.TestCheckResourceAttr("flipt_flag_rules.test", "rules.#", "2"),
+					resource.TestCheckResourceAttr("flipt_flag_rules.test", "rules.0.segment_keys.0", "beta"),
+					resource.TestCheckResourceAttr("flipt_flag_rules.test", "rules.0.segment_operator", "OR_SEGMENT_OPERATOR"),
+					resource.TestCheckResourceAttr("flipt_flag_rules.test", "rules.0.distributions.0.variant_key", "blue"),
+					resource.TestCheckResourceAttr("flipt_flag_rules.test", "rules.0.distributions.0.rollout", "100"),
+					resource.TestCheckResourceAttr("flipt_flag_rules.test", "rules.1.segment_keys.0", "everyone"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "flipt_flag_rules.test",
+				ImportState:       true,
+				ImportStateId:     "default/test-flag-rules-ns/test-flag",
+				ImportStateVerify: true,
+			},
+			// Reorder and Read testing
+			{
+				Config: testAccFlagRulesResourceConfig(`["everyone"]`, `["beta"]`, ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_flag_rules.test", "rules.#", "2"),
+					resource.TestCheckResourceAttr("flipt_flag_rules.test", "rules.0.segment_keys.0", "everyone"),
+					resource.TestCheckResourceAttr("flipt_flag_rules.test", "rules.1.segment_keys.0", "beta"),
+				),
+			},
+			// An empty distributions list is kept after refresh
+			{
+				Config: testAccFlagRulesResourceConfig(`["everyone"]`, `["beta"]`, "distributions = []"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PostApplyPostRefresh: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("flipt_flag_rules.test", "rules.1.distributions.#", "0"),
+			},
+		},
+	})
+}
+
+func testAccFlagRulesResourceConfig(firstSegments, secondSegments, secondDistributions string) string {
+	return fmt.Sprintf(`
+provider "flipt" {
+  endpoint = %[1]q
+}
+
+resource "flipt_namespace" "test" {
+  key  = "test-flag-rules-ns"
+  name = "Test Namespace"
+}
+
+resource "flipt_flag" "test" {
+  namespace_key = flipt_namespace.test.key
+  key           = "test-flag"
+  name          = "Test Flag"
+  type          = "VARIANT_FLAG_TYPE"
+}
+
+resource "flipt_variant" "blue" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.test.key
+  key           = "blue"
+}
+
+resource "flipt_segment" "beta" {
+  namespace_key = flipt_namespace.test.key
+  key           = "beta"
+  name          = "Beta"
+  match_type    = "ALL_MATCH_TYPE"
+}
+
+resource "flipt_segment" "everyone" {
+  namespace_key = flipt_namespace.test.key
+  key           = "everyone"
+  name          = "Everyone"
+  match_type    = "ANY_MATCH_TYPE"
+}
+
+resource "flipt_flag_rules" "test" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.test.key
+
+  rules = [
+    {
+      segment_keys  = %[2]s
+      distributions = [{ variant_key = flipt_variant.blue.key, rollout = 100 }]
+    },
+    {
+      segment_keys = %[3]s
+      %[4]s
+    },
+  ]
+
+  depends_on = [flipt_segment.beta, flipt_segment.everyone]
+}
+`, getTestFliptEndpoint(), firstSegments, secondSegments, secondDistributions)
+}
+
+func TestFlagRulesResource(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+		"name":     "Checkout",
+		"type":     "VARIANT_FLAG_TYPE",
+		"enabled":  true,
+		"variants": []interface{}{map[string]interface{}{"key": "blue"}},
+		"rules": []interface{}{
+			map[string]interface{}{"id": "existing", "segments": []interface{}{"beta"}, "segmentOperator": "OR_SEGMENT_OPERATOR", "rank": 0},
+			map[string]interface{}{"segments": []interface{}{"stale"}, "segmentOperator": "OR_SEGMENT_OPERATOR", "rank": 1},
+		},
+	})
+
+	r := NewFlagRulesResource()
+	configureResource(t, r, fake.config())
+
+	model := func(rules ...client.Rule) *FlagRulesResourceModel {
+		list, diags := rulesToModel(context.Background(), rules, nil)
+		if diags.HasError() {
+			t.Fatalf("Unable to build rules: %v", diags)
+		}
+		return &FlagRulesResourceModel{
+			NamespaceKey:   types.StringValue("default"),
+			EnvironmentKey: types.StringValue("default"),
+			FlagKey:        types.StringValue("checkout"),
+			Rules:          list,
+			ID:             types.StringUnknown(),
+		}
+	}
+	everyone := client.Rule{Segments: []string{"everyone"}, SegmentOperator: "OR_SEGMENT_OPERATOR"}
+	beta := client.Rule{
+		Segments:        []string{"beta"},
+		SegmentOperator: "OR_SEGMENT_OPERATOR",
+		Distributions:   []client.Distribution{{Variant: "blue", Rollout: 100}},
+	}
+
+	// Unknown variants are rejected
+	missing := beta
+	missing.Distributions = []client.Distribution{{Variant: "red", Rollout: 100}}
+	resp, _ := createResource(t, r, model(missing))
+	if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != "Variant Not Found" {
+		t.Fatalf("Expected variant not found error, got %v", resp.Diagnostics)
+	}
+
+	// The rules replace the existing ones in order, keeping the ID of an
+	// unchanged rule
+	resp, _ = createResource(t, r, model(everyone, beta))
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Create failed: %v", resp.Diagnostics)
+	}
+	rules := fake.get("default", "default", client.TypeFlag, "checkout")["rules"].([]interface{})
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 rules, got %v", rules)
+	}
+	first, second := rules[0].(map[string]interface{}), rules[1].(map[string]interface{})
+	if first["segments"].([]interface{})[0] != "everyone" || first["rank"] != float64(0) {
+		t.Errorf("Expected rule everyone first, got %v", first)
+	}
+	if second["id"] != "existing" || second["rank"] != float64(1) || second["distributions"] == nil {
+		t.Errorf("Expected rule beta second with its ID and distributions, got %v", second)
+	}
+
+	// A rule added outside of Terraform shows up as drift
+	fake.get("default", "default", client.TypeFlag, "checkout")["rules"] = append(rules,
+		map[string]interface{}{"segments": []interface{}{"internal"}, "segmentOperator": "OR_SEGMENT_OPERATOR", "rank": 2})
+	readResp, state := readResource(t, r, model(everyone, beta))
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("Read failed: %v", readResp.Diagnostics)
+	}
+	var data FlagRulesResourceModel
+	state.Get(context.Background(), &data)
+	if len(data.Rules.Elements()) != 3 {
+		t.Errorf("Expected 3 rules in state, got %d", len(data.Rules.Elements()))
+	}
+
+	if resp := deleteResource(t, r, model(everyone, beta)); resp.Diagnostics.HasError() {
+		t.Fatalf("Delete failed: %v", resp.Diagnostics)
+	}
+	if got := fake.get("default", "default", client.TypeFlag, "checkout")["rules"]; got != nil {
+		t.Errorf("Expected rules to be cleared, got %v", got)
+	}
+}
+
+func TestFlagRulesResourceEmptyDistributions(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+		"name":    "Checkout",
+		"type":    "VARIANT_FLAG_TYPE",
+		"enabled": true,
+	})
+
+	r := NewFlagRulesResource()
+	configureResource(t, r, fake.config())
+
+	segmentKeys := func(keys ...string) types.List {
+		list, _ := types.ListValueFrom(context.Background(), types.StringType, keys)
+		return list
+	}
+	distributionsType := types.ObjectType{AttrTypes: flagRuleDistributionAttrTypes}
+	rules, diags := types.ListValueFrom(context.Background(), types.ObjectType{AttrTypes: flagRuleAttrTypes}, []flagRuleModel{
+		{
+			SegmentKeys:     segmentKeys("beta"),
+			SegmentOperator: types.StringValue("OR_SEGMENT_OPERATOR"),
+			Distributions:   types.ListValueMust(distributionsType, []attr.Value{}),
+		},
+		{
+			SegmentKeys:     segmentKeys("everyone"),
+			SegmentOperator: types.StringValue("OR_SEGMENT_OPERATOR"),
+			Distributions:   types.ListNull(distributionsType),
+		},
+	})
+	if diags.HasError() {
+		t.Fatalf("Unable to build rules: %v", diags)
+	}
+	plan := &FlagRulesResourceModel{
+		NamespaceKey:   types.StringValue("default"),
+		EnvironmentKey: types.StringValue("default"),
+		FlagKey:        types.StringValue("checkout"),
+		Rules:          rules,
+		ID:             types.StringUnknown(),
+	}
+
+	resp, state := createResource(t, r, plan)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Create failed: %v", resp.Diagnostics)
+	}
+	var data FlagRulesResourceModel
+	state.Get(context.Background(), &data)
+
+	readResp, state := readResource(t, r, &data)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("Read failed: %v", readResp.Diagnostics)
+	}
+	state.Get(context.Background(), &data)
+	if !data.Rules.Equal(rules) {
+		t.Errorf("Expected the rules to be read as configured, got %s", data.Rules)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
+)
+
+var _ datasource.DataSource = &FlagsDataSource{}
+
+func NewFlagsDataSource() datasource.DataSource {
+	return &FlagsDataSource{}
+}
+
+type FlagsDataSource struct {
+	config *FliptProviderConfig
+}
+
+type FlagsDataSourceModel struct {
+	NamespaceKey   types.String      `tfsdk:"namespace_key"`
+	EnvironmentKey types.String      `tfsdk:"environment_key"`
+	KeyPrefix      types.String      `tfsdk:"key_prefix"`
+	Enabled        types.Bool        `tfsdk:"enabled"`
+	Type           types.String      `tfsdk:"type"`
+	MetadataMatch  map[string]string `tfsdk:"metadata_match"`
+	Flags          types.Dynamic     `tfsdk:"flags"`
+}
+
+func (d *FlagsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_flags"
+}
+
+func (d *FlagsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the flags of a namespace, optionally filtered",
+		Description:         "Lists the flags of a namespace, optionally filtered",
+
+		Attributes: map[string]schema.Attribute{
+			"namespace_key": schema.StringAttribute{
+				MarkdownDescription: "Namespace key to list the flags of. Defaults to the provider's `default_namespace_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+			},
+			"environment_key": schema.StringAttribute{
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+			},
+			"key_prefix": schema.StringAttribute{
+				MarkdownDescription: "Only list flags whose key starts with this prefix",
+				Optional:            true,
+			},
+			"enabled": schema.BoolAttribute{
+				MarkdownDescription: "Only list enabled (`true`) or disabled (`false`) flags",
+				Optional:            true,
+			},
+			"type": schema.StringAttribute{
+				MarkdownDescription: "Only list flags of this type (VARIANT_FLAG_TYPE or BOOLEAN_FLAG_TYPE)",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("VARIANT_FLAG_TYPE", "BOOLEAN_FLAG_TYPE"),
+				},
+			},
+			"metadata_match": schema.MapAttribute{
+				MarkdownDescription: "Only list flags whose metadata has all of these keys with these values. Values that are not strings are compared by their JSON encoding, such as `true` or `3`",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"flags": schema.DynamicAttribute{
+				MarkdownDescription: "Flags matching the filters, ordered by key. Each flag has its `key`, `name`, `description`, `enabled`, `type`, `default_variant` and `metadata`, an object whose values keep their JSON type as in the `flipt_flag` data source",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *FlagsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerConfig, ok := req.ProviderData.(*FliptProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *FliptProviderConfig, got: %T", req.ProviderData),
+		)
+		return
+	}
+
+	d.config = providerConfig
+}
+
+func (d *FlagsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FlagsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the data source
+	envKey := d.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(d.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Listing flags", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+	})
+
+	flags, err := d.config.Client.ListFlags(ctx, envKey, data.NamespaceKey.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list flags in namespace '%s', got error: %s", data.NamespaceKey.ValueString(), err))
+		return
+	}
+	slices.SortFunc(flags, func(a, b client.Flag) int { return strings.Compare(a.Key, b.Key) })
+
+	var flagTypes []attr.Type
+	var flagValues []attr.Value
+	for _, flag := range flags {
+		if !strings.HasPrefix(flag.Key, data.KeyPrefix.ValueString()) ||
+			(!data.Enabled.IsNull() && flag.Enabled != data.Enabled.ValueBool()) ||
+			(!data.Type.IsNull() && flag.Type != data.Type.ValueString()) ||
+			!flagMetadataMatches(flag.Metadata, data.MetadataMatch) {
+			continue
+		}
+
+		item, diags := flagsDataSourceFlagToValue(ctx, flag)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		flagTypes = append(flagTypes, item.Type(ctx))
+		flagValues = append(flagValues, item)
+	}
+
+	// The metadata of each flag has its own type, so the flags are a tuple
+	// rather than a list
+	tuple, diags := types.TupleValue(flagTypes, flagValues)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Flags = types.DynamicValue(tuple)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func flagsDataSourceFlagToValue(ctx context.Context, flag client.Flag) (types.Object, diag.Diagnostics) {
+	attrs := map[string]attr.Value{
+		"key":             types.StringValue(flag.Key),
+		"name":            types.StringValue(flag.Name),
+		"description":     types.StringNull(),
+		"enabled":         types.BoolValue(flag.Enabled),
+		"type":            types.StringValue(flag.Type),
+		"default_variant": types.StringNull(),
+		"metadata":        types.ObjectNull(map[string]attr.Type{}),
+	}
+	if flag.Description != "" {
+		attrs["description"] = types.StringValue(flag.Description)
+	}
+	if flag.DefaultVariant != "" {
+		attrs["default_variant"] = types.StringValue(flag.DefaultVariant)
+	}
+
+	metadata, diags := flagMetadataToModel(ctx, flag.Metadata)
+	if diags.HasError() {
+		return types.Object{}, diags
+	}
+	if !metadata.IsNull() {
+		attrs["metadata"] = metadata.UnderlyingValue()
+	}
+
+	attrTypes := make(map[string]attr.Type, len(attrs))
+	for k, v := range attrs {
+		attrTypes[k] = v.Type(ctx)
+	}
+	object, d := types.ObjectValue(attrTypes, attrs)
+	diags.Append(d...)
+	return object, diags
+}
+
+// flagMetadataMatches reports whether the metadata of a flag has every key of
+// match with the same value. Strings are compared as is, other values by their
+// JSON encoding.
+func flagMetadataMatches(metadata map[string]interface{}, match map[string]string) bool {
+	for k, want := range match {
+		v, ok := metadata[k]
+		if !ok {
+			return false
+		}
+
+		got, isString := v.(string)
+		if !isString {
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return false
+			}
+			got = string(encoded)
+		}
+		if got != want {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,283 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"terraform-provider-flipt/internal/client"
+)
+
+func TestAccFlagsDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFlagsDataSourceConfig(),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.flipt_flags.all", tfjsonpath.New("flags"), knownvalue.ListSizeExact(3)),
+					statecheck.ExpectKnownValue("data.flipt_flags.all", tfjsonpath.New("flags").AtSliceIndex(0).AtMapKey("key"), knownvalue.StringExact("checkout-button")),
+					statecheck.ExpectKnownValue("data.flipt_flags.all", tfjsonpath.New("flags").AtSliceIndex(0).AtMapKey("type"), knownvalue.StringExact("BOOLEAN_FLAG_TYPE")),
+					statecheck.ExpectKnownValue("data.flipt_flags.all", tfjsonpath.New("flags").AtSliceIndex(0).AtMapKey("metadata"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"team":    knownvalue.StringExact("payments"),
+						"release": knownvalue.Int64Exact(3),
+					})),
+					statecheck.ExpectKnownValue("data.flipt_flags.all", tfjsonpath.New("flags").AtSliceIndex(2).AtMapKey("metadata"), knownvalue.Null()),
+					statecheck.ExpectKnownValue("data.flipt_flags.checkout", tfjsonpath.New("flags"), knownvalue.ListSizeExact(2)),
+					statecheck.ExpectKnownValue("data.flipt_flags.enabled_payments", tfjsonpath.New("flags"), knownvalue.ListSizeExact(1)),
+					statecheck.ExpectKnownValue("data.flipt_flags.enabled_payments", tfjsonpath.New("flags").AtSliceIndex(0).AtMapKey("key"), knownvalue.StringExact("checkout-button")),
+					statecheck.ExpectKnownValue("data.flipt_flags.variants", tfjsonpath.New("flags"), knownvalue.ListSizeExact(1)),
+					statecheck.ExpectKnownValue("data.flipt_flags.variants", tfjsonpath.New("flags").AtSliceIndex(0).AtMapKey("key"), knownvalue.StringExact("search")),
+				},
+			},
+		},
+	})
+}
+
+func testAccFlagsDataSourceConfig() string {
+	return `
+provider "flipt" {
+  endpoint = "` + getTestFliptEndpoint() + `"
+}
+
+resource "flipt_namespace" "test" {
+  key           = "flags-data-source"
+  name          = "Flags Data Source"
+  force_destroy = true
+}
+
+resource "flipt_flag" "checkout_button" {
+  namespace_key = flipt_namespace.test.key
+  key           = "checkout-button"
+  name          = "Checkout Button"
+  type          = "BOOLEAN_FLAG_TYPE"
+  enabled       = true
+  metadata = {
+    team    = "payments"
+    release = 3
+  }
+}
+
+resource "flipt_flag" "checkout_flow" {
+  namespace_key = flipt_namespace.test.key
+  key           = "checkout-flow"
+  name          = "Checkout Flow"
+  type          = "BOOLEAN_FLAG_TYPE"
+  enabled       = false
+  metadata = {
+    team = "payments"
+  }
+}
+
+resource "flipt_flag" "search" {
+  namespace_key = flipt_namespace.test.key
+  key           = "search"
+  name          = "Search"
+  type          = "VARIANT_FLAG_TYPE"
+  enabled       = true
+}
+
+data "flipt_flags" "all" {
+  namespace_key = flipt_namespace.test.key
+  depends_on    = [flipt_flag.checkout_button, flipt_flag.checkout_flow, flipt_flag.search]
+}
+
+data "flipt_flags" "checkout" {
+  namespace_key = flipt_namespace.test.key
+  key_prefix    = "checkout-"
+  depends_on    = [flipt_flag.checkout_button, flipt_flag.checkout_flow, flipt_flag.search]
+}
+
+data "flipt_flags" "enabled_payments" {
+  namespace_key  = flipt_namespace.test.key
+  enabled        = true
+  metadata_match = { team = "payments" }
+  depends_on     = [flipt_flag.checkout_button, flipt_flag.checkout_flow, flipt_flag.search]
+}
+
+data "flipt_flags" "variants" {
+  namespace_key = flipt_namespace.test.key
+  type          = "VARIANT_FLAG_TYPE"
+  depends_on    = [flipt_flag.checkout_button, flipt_flag.checkout_flow, flipt_flag.search]
+}
+`
+}
+
+func TestFlagsDataSource(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.pageSize = 2
+	fake.put("default", "default", client.TypeFlag, "checkout-button", map[string]interface{}{
+		"name": "Checkout Button", "type": "BOOLEAN_FLAG_TYPE", "enabled": true,
+		"metadata": map[string]interface{}{"team": "payments", "release": 3, "beta": true},
+	})
+	fake.put("default", "default", client.TypeFlag, "checkout-flow", map[string]interface{}{
+		"name": "Checkout Flow", "type": "BOOLEAN_FLAG_TYPE", "enabled": false,
+		"metadata": map[string]interface{}{"team": "payments"},
+	})
+	fake.put("default", "default", client.TypeFlag, "search", map[string]interface{}{
+		"name": "Search", "description": "Search ranking", "type": "VARIANT_FLAG_TYPE", "enabled": true,
+		"defaultVariant": "fast",
+	})
+	fake.put("default", "default", client.TypeFlag, "search-v2", map[string]interface{}{
+		"name": "Search v2", "type": "VARIANT_FLAG_TYPE", "enabled": false,
+	})
+	fake.put("default", "other", client.TypeFlag, "checkout-other", map[string]interface{}{
+		"name": "Other", "type": "BOOLEAN_FLAG_TYPE",
+	})
+
+	str := func(v string) tftypes.Value { return tftypes.NewValue(tftypes.String, v) }
+	match := func(m map[string]string) tftypes.Value {
+		values := map[string]tftypes.Value{}
+		for k, v := range m {
+			values[k] = str(v)
+		}
+		return tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, values)
+	}
+
+	tests := []struct {
+		name   string
+		config map[string]tftypes.Value
+		want   []string
+	}{
+		{
+			name: "all",
+			want: []string{"checkout-button", "checkout-flow", "search", "search-v2"},
+		},
+		{
+			name:   "key prefix",
+			config: map[string]tftypes.Value{"key_prefix": str("search")},
+			want:   []string{"search", "search-v2"},
+		},
+		{
+			name:   "enabled",
+			config: map[string]tftypes.Value{"enabled": tftypes.NewValue(tftypes.Bool, true)},
+			want:   []string{"checkout-button", "search"},
+		},
+		{
+			name:   "disabled",
+			config: map[string]tftypes.Value{"enabled": tftypes.NewValue(tftypes.Bool, false)},
+			want:   []string{"checkout-flow", "search-v2"},
+		},
+		{
+			name:   "type",
+			config: map[string]tftypes.Value{"type": str("BOOLEAN_FLAG_TYPE")},
+			want:   []string{"checkout-button", "checkout-flow"},
+		},
+		{
+			name:   "metadata string",
+			config: map[string]tftypes.Value{"metadata_match": match(map[string]string{"team": "payments"})},
+			want:   []string{"checkout-button", "checkout-flow"},
+		},
+		{
+			name:   "metadata number and bool",
+			config: map[string]tftypes.Value{"metadata_match": match(map[string]string{"release": "3", "beta": "true"})},
+			want:   []string{"checkout-button"},
+		},
+		{
+			name:   "metadata mismatch",
+			config: map[string]tftypes.Value{"metadata_match": match(map[string]string{"team": "search"})},
+			want:   []string{},
+		},
+		{
+			name: "combined",
+			config: map[string]tftypes.Value{
+				"key_prefix": str("checkout"),
+				"enabled":    tftypes.NewValue(tftypes.Bool, false),
+			},
+			want: []string{"checkout-flow"},
+		},
+		{
+			name:   "other namespace",
+			config: map[string]tftypes.Value{"namespace_key": str("other")},
+			want:   []string{"checkout-other"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewFlagsDataSource()
+			configureDataSource(t, d, fake.config())
+
+			resp, state := readDataSource(t, d, tt.config)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Read failed: %v", resp.Diagnostics)
+			}
+
+			var data FlagsDataSourceModel
+			if diags := state.Get(context.Background(), &data); diags.HasError() {
+				t.Fatalf("Unable to read state: %v", diags)
+			}
+
+			keys := []string{}
+			for _, flag := range listedFlags(t, data) {
+				keys = append(keys, flag["key"].(types.String).ValueString())
+			}
+			if !reflect.DeepEqual(keys, tt.want) {
+				t.Errorf("Expected flags %v, got %v", tt.want, keys)
+			}
+		})
+	}
+
+	t.Run("attributes", func(t *testing.T) {
+		d := NewFlagsDataSource()
+		configureDataSource(t, d, fake.config())
+
+		_, state := readDataSource(t, d, nil)
+		var data FlagsDataSourceModel
+		if diags := state.Get(context.Background(), &data); diags.HasError() {
+			t.Fatalf("Unable to read state: %v", diags)
+		}
+
+		if data.NamespaceKey.ValueString() != "default" || data.EnvironmentKey.ValueString() != "default" {
+			t.Errorf("Expected the default keys, got %s/%s", data.EnvironmentKey, data.NamespaceKey)
+		}
+
+		flags := listedFlags(t, data)
+		button := flags[0]
+		if button["name"].(types.String).ValueString() != "Checkout Button" || !button["enabled"].(types.Bool).ValueBool() || !button["description"].IsNull() {
+			t.Errorf("Unexpected flag %v", button)
+		}
+		metadata, err := metadataFromValue(context.Background(), types.DynamicValue(button["metadata"]))
+		if err != nil {
+			t.Fatalf("Unable to read metadata: %v", err)
+		}
+		if expected := map[string]interface{}{"team": "payments", "release": float64(3), "beta": true}; !reflect.DeepEqual(metadata, expected) {
+			t.Errorf("Expected metadata %v, got %v", expected, metadata)
+		}
+
+		search := flags[2]
+		if search["description"].(types.String).ValueString() != "Search ranking" || search["default_variant"].(types.String).ValueString() != "fast" || !search["metadata"].IsNull() {
+			t.Errorf("Unexpected flag %v", search)
+		}
+	})
+}
+
+// listedFlags returns the attributes of the flags listed by the data source.
+func listedFlags(t *testing.T, data FlagsDataSourceModel) []map[string]attr.Value {
+	t.Helper()
+
+	tuple, ok := data.Flags.UnderlyingValue().(types.Tuple)
+	if !ok {
+		t.Fatalf("Expected the flags to be a tuple, got %s", data.Flags)
+	}
+	var flags []map[string]attr.Value
+	for _, elem := range tuple.Elements() {
+		flag, ok := elem.(types.Object)
+		if !ok {
+			t.Fatalf("Expected a flag object, got %s", elem)
+		}
+		flags = append(flags, flag.Attributes())
+	}
+	return flags
+}
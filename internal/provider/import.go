@@ -0,0 +1,62 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// parseImportID splits a composite import ID into its slash separated parts,
+// named by fields for the error message. Parsing is strict: the number of
+// parts must match and no part may be empty or padded with whitespace.
+func parseImportID(req resource.ImportStateRequest, resp *resource.ImportStateResponse, fields ...string) ([]string, bool) {
+	parts := strings.Split(req.ID, "/")
+
+	valid := len(parts) == len(fields)
+	for _, part := range parts {
+		if part == "" || strings.TrimSpace(part) != part {
+			valid = false
+		}
+	}
+	if !valid {
+		resp.Diagnostics.AddError("Invalid Import ID",
+			fmt.Sprintf("Expected an import ID of the form %s, got: %q", strings.Join(fields, "/"), req.ID))
+		return nil, false
+	}
+	return parts, true
+}
+
+// parseImportIDRest is like parseImportID, but the last part takes the
+// remainder of the ID, which may contain slashes or be empty.
+func parseImportIDRest(req resource.ImportStateRequest, resp *resource.ImportStateResponse, fields ...string) ([]string, bool) {
+	parts := strings.SplitN(req.ID, "/", len(fields))
+
+	valid := len(parts) == len(fields)
+	for _, part := range parts[:len(parts)-1] {
+		if part == "" || strings.TrimSpace(part) != part {
+			valid = false
+		}
+	}
+	if !valid {
+		resp.Diagnostics.AddError("Invalid Import ID",
+			fmt.Sprintf("Expected an import ID of the form %s, got: %q", strings.Join(fields, "/"), req.ID))
+		return nil, false
+	}
+	return parts, true
+}
+
+// parseImportIndex parses a rank or index part of a composite import ID.
+func parseImportIndex(resp *resource.ImportStateResponse, name, value string) (int64, bool) {
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || i < 0 || strconv.FormatInt(i, 10) != value {
+		resp.Diagnostics.AddError("Invalid Import ID",
+			fmt.Sprintf("Expected the %s in the import ID to be a non-negative number, got: %q", name, value))
+		return 0, false
+	}
+	return i, true
+}
@@ -0,0 +1,181 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestImportState(t *testing.T) {
+	str := func(v string) attr.Value { return types.StringValue(v) }
+
+	tests := []struct {
+		name     string
+		resource func() resource.Resource
+		id       string
+		expect   map[string]attr.Value
+	}{
+		{
+			name:     "namespace",
+			resource: NewNamespaceResource,
+			id:       "production/team-a",
+			expect:   map[string]attr.Value{"environment_key": str("production"), "key": str("team-a")},
+		},
+		{
+			name:     "flag",
+			resource: NewFlagResource,
+			id:       "production/team-a/checkout",
+			expect:   map[string]attr.Value{"environment_key": str("production"), "namespace_key": str("team-a"), "key": str("checkout")},
+		},
+		{
+			name:     "segment",
+			resource: NewSegmentResource,
+			id:       "production/team-a/beta-users",
+			expect:   map[string]attr.Value{"environment_key": str("production"), "namespace_key": str("team-a"), "key": str("beta-users")},
+		},
+		{
+			name:     "variant",
+			resource: NewVariantResource,
+			id:       "production/team-a/checkout/blue",
+			expect:   map[string]attr.Value{"environment_key": str("production"), "namespace_key": str("team-a"), "flag_key": str("checkout"), "key": str("blue")},
+		},
+		{
+			name:     "constraint",
+			resource: NewConstraintResource,
+			id:       "production/team-a/beta-users/email",
+			expect:   map[string]attr.Value{"environment_key": str("production"), "namespace_key": str("team-a"), "segment_key": str("beta-users"), "property": str("email")},
+		},
+		{
+			name:     "constraint by operator and value",
+			resource: NewConstraintResource,
+			id:       "production/team-a/beta-users/path/prefix//api/v2",
+			expect:   map[string]attr.Value{"property": str("path"), "operator": str("prefix"), "value": str("/api/v2"), "id": str("path/prefix/%2Fapi%2Fv2")},
+		},
+		{
+			name:     "constraint with escaped slashes",
+			resource: NewConstraintResource,
+			id:       "production/team-a/beta-users/http%2Fpath/prefix/%2Fapi%2Fv2",
+			expect:   map[string]attr.Value{"property": str("http/path"), "operator": str("prefix"), "value": str("/api/v2"), "id": str("http%2Fpath/prefix/%2Fapi%2Fv2")},
+		},
+		{
+			name:     "constraint with escaped percent",
+			resource: NewConstraintResource,
+			id:       "production/team-a/beta-users/discount/eq/100%25",
+			expect:   map[string]attr.Value{"property": str("discount"), "operator": str("eq"), "value": str("100%"), "id": str("discount/eq/100%25")},
+		},
+		{
+			name:     "constraint by escaped property",
+			resource: NewConstraintResource,
+			id:       "production/team-a/beta-users/http%2Fpath",
+			expect:   map[string]attr.Value{"segment_key": str("beta-users"), "property": str("http/path")},
+		},
+		{
+			name:     "rule",
+			resource: NewRuleResource,
+			id:       "production/team-a/checkout/2",
+			expect:   map[string]attr.Value{"environment_key": str("production"), "namespace_key": str("team-a"), "flag_key": str("checkout"), "rank": types.Int64Value(2), "id": str("checkout/2")},
+		},
+		{
+			name:     "distribution",
+			resource: NewDistributionResource,
+			id:       "production/team-a/checkout/2/blue",
+			expect:   map[string]attr.Value{"flag_key": str("checkout"), "rule_id": str("checkout/2"), "variant_key": str("blue"), "id": str("checkout/2/blue")},
+		},
+		{
+			name:     "rollout",
+			resource: NewRolloutResource,
+			id:       "production/team-a/dark-mode/1",
+			expect:   map[string]attr.Value{"flag_key": str("dark-mode"), "rank": types.Int64Value(1), "id": str("dark-mode/1")},
+		},
+		{
+			name:     "flag default variant",
+			resource: NewFlagDefaultVariantResource,
+			id:       "production/team-a/checkout",
+			expect:   map[string]attr.Value{"namespace_key": str("team-a"), "flag_key": str("checkout"), "id": str("checkout")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := importResource(t, tt.resource(), tt.id)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Import failed: %v", resp.Diagnostics)
+			}
+
+			for name, expected := range tt.expect {
+				var got attr.Value
+				switch expected.(type) {
+				case types.Int64:
+					var v types.Int64
+					resp.State.GetAttribute(context.Background(), path.Root(name), &v)
+					got = v
+				default:
+					var v types.String
+					resp.State.GetAttribute(context.Background(), path.Root(name), &v)
+					got = v
+				}
+				if !got.Equal(expected) {
+					t.Errorf("Expected %s to be %s, got %s", name, expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestImportStateInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource func() resource.Resource
+		id       string
+	}{
+		{name: "bare key", resource: NewFlagResource, id: "checkout"},
+		{name: "missing environment", resource: NewFlagResource, id: "team-a/checkout"},
+		{name: "too many parts", resource: NewNamespaceResource, id: "production/team-a/extra"},
+		{name: "empty part", resource: NewVariantResource, id: "production//checkout/blue"},
+		{name: "trailing slash", resource: NewSegmentResource, id: "production/team-a/beta-users/"},
+		{name: "padded part", resource: NewConstraintResource, id: "production/team-a/beta-users/ email"},
+		{name: "constraint with unescaped percent", resource: NewConstraintResource, id: "production/team-a/beta-users/discount/eq/100%"},
+		{name: "constraint without operator", resource: NewConstraintResource, id: "production/team-a/beta-users/email//@example.com"},
+		{name: "non-numeric rule index", resource: NewRuleResource, id: "production/team-a/checkout/first"},
+		{name: "negative rule index", resource: NewRuleResource, id: "production/team-a/checkout/-1"},
+		{name: "signed rank", resource: NewRolloutResource, id: "production/team-a/dark-mode/+1"},
+		{name: "distribution without variant", resource: NewDistributionResource, id: "production/team-a/checkout/0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := importResource(t, tt.resource(), tt.id)
+			if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != "Invalid Import ID" {
+				t.Fatalf("Expected invalid import ID error, got %v", resp.Diagnostics)
+			}
+		})
+	}
+}
+
+// importResource calls ImportState on a resource with an empty state.
+func importResource(t *testing.T, r resource.Resource, id string) *resource.ImportStateResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	resp := &resource.ImportStateResponse{
+		State: tfsdk.State{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+		},
+	}
+	r.(resource.ResourceWithImportState).ImportState(ctx, resource.ImportStateRequest{ID: id}, resp)
+
+	return resp
+}
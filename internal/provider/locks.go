@@ -0,0 +1,56 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"strings"
+	"sync"
+)
+
+// parentLocks serializes read-modify-write cycles on flags and segments.
+// Variants, rules and constraints are stored inside the payload of their
+// parent, so two resources changing children of the same parent at the same
+// time would otherwise overwrite each other's changes. The zero value is
+// ready to use.
+type parentLocks struct {
+	mu    sync.Mutex
+	locks map[string]*parentLock
+}
+
+// parentLock is the lock of one parent. refs counts the callers holding or
+// waiting for it, the entry is removed once the last one releases it.
+type parentLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock acquires the lock for the parent identified by the given keys and
+// returns a function releasing it.
+func (l *parentLocks) lock(envKey, namespaceKey, typeURL, key string) func() {
+	id := strings.Join([]string{envKey, namespaceKey, typeURL, key}, "/")
+
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*parentLock)
+	}
+	m, ok := l.locks[id]
+	if !ok {
+		m = &parentLock{}
+		l.locks[id] = m
+	}
+	m.refs++
+	l.mu.Unlock()
+
+	m.Lock()
+	return func() {
+		m.Unlock()
+
+		l.mu.Lock()
+		m.refs--
+		if m.refs == 0 {
+			delete(l.locks, id)
+		}
+		l.mu.Unlock()
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"terraform-provider-flipt/internal/client"
+)
+
+func TestParentLocks(t *testing.T) {
+	var locks parentLocks
+
+	unlock := locks.lock("default", "default", client.TypeFlag, "checkout")
+
+	// A different parent must not be blocked.
+	done := make(chan struct{})
+	go func() {
+		locks.lock("default", "default", client.TypeSegment, "checkout")()
+		locks.lock("production", "default", client.TypeFlag, "checkout")()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Lock on a different parent was blocked")
+	}
+
+	// The same parent must wait until the lock is released.
+	acquired := make(chan struct{})
+	go func() {
+		locks.lock("default", "default", client.TypeFlag, "checkout")()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("Lock on the same parent was acquired while held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("Lock was not acquired after release")
+	}
+
+	// Released locks are not kept around.
+	locks.mu.Lock()
+	defer locks.mu.Unlock()
+	if len(locks.locks) != 0 {
+		t.Errorf("Expected no locks to be left, got %d", len(locks.locks))
+	}
+}
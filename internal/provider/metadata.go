@@ -0,0 +1,124 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// metadataFromValue converts a Terraform value into the JSON value stored by
+// Flipt, as decoded by encoding/json. Objects and maps become
+// map[string]interface{}, lists, sets and tuples become []interface{}.
+func metadataFromValue(ctx context.Context, value attr.Value) (interface{}, error) {
+	if value == nil || value.IsNull() {
+		return nil, nil
+	}
+	if value.IsUnknown() {
+		return nil, fmt.Errorf("value is not known yet")
+	}
+
+	switch v := value.(type) {
+	case basetypes.DynamicValue:
+		return metadataFromValue(ctx, v.UnderlyingValue())
+	case basetypes.StringValue:
+		return v.ValueString(), nil
+	case basetypes.BoolValue:
+		return v.ValueBool(), nil
+	case basetypes.NumberValue:
+		f, _ := v.ValueBigFloat().Float64()
+		return f, nil
+	case basetypes.Int64Value:
+		return float64(v.ValueInt64()), nil
+	case basetypes.Float64Value:
+		return v.ValueFloat64(), nil
+	case basetypes.ObjectValue:
+		return metadataFromElements(ctx, v.Attributes())
+	case basetypes.MapValue:
+		return metadataFromElements(ctx, v.Elements())
+	case basetypes.ListValue:
+		return metadataFromList(ctx, v.Elements())
+	case basetypes.SetValue:
+		return metadataFromList(ctx, v.Elements())
+	case basetypes.TupleValue:
+		return metadataFromList(ctx, v.Elements())
+	}
+
+	return nil, fmt.Errorf("unsupported value type %s", value.Type(ctx))
+}
+
+func metadataFromElements(ctx context.Context, elements map[string]attr.Value) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(elements))
+	for k, elem := range elements {
+		v, err := metadataFromValue(ctx, elem)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		result[k] = v
+	}
+	return result, nil
+}
+
+func metadataFromList(ctx context.Context, elements []attr.Value) ([]interface{}, error) {
+	result := make([]interface{}, 0, len(elements))
+	for i, elem := range elements {
+		v, err := metadataFromValue(ctx, elem)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %w", i, err)
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+// metadataToValue converts a JSON value stored by Flipt into a Terraform
+// value. Objects become objects and arrays become tuples, so that the value
+// has the same type as the equivalent HCL literal.
+func metadataToValue(ctx context.Context, value interface{}) (attr.Value, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	switch v := value.(type) {
+	case nil:
+		return types.StringNull(), diags
+	case string:
+		return types.StringValue(v), diags
+	case bool:
+		return types.BoolValue(v), diags
+	case float64:
+		return types.NumberValue(big.NewFloat(v)), diags
+	case map[string]interface{}:
+		attrTypes := make(map[string]attr.Type, len(v))
+		attrs := make(map[string]attr.Value, len(v))
+		for k, e := range v {
+			elem, d := metadataToValue(ctx, e)
+			diags.Append(d...)
+			attrTypes[k] = elem.Type(ctx)
+			attrs[k] = elem
+		}
+		object, d := types.ObjectValue(attrTypes, attrs)
+		diags.Append(d...)
+		return object, diags
+	case []interface{}:
+		elemTypes := make([]attr.Type, 0, len(v))
+		elems := make([]attr.Value, 0, len(v))
+		for _, e := range v {
+			elem, d := metadataToValue(ctx, e)
+			diags.Append(d...)
+			elemTypes = append(elemTypes, elem.Type(ctx))
+			elems = append(elems, elem)
+		}
+		tuple, d := types.TupleValue(elemTypes, elems)
+		diags.Append(d...)
+		return tuple, diags
+	}
+
+	diags.AddError("Invalid Metadata", fmt.Sprintf("Unsupported metadata value of type %T", value))
+	return types.StringNull(), diags
+}
@@ -5,15 +5,14 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
 )
 
 var _ datasource.DataSource = &NamespaceDataSource{}
@@ -45,8 +44,9 @@ func (d *NamespaceDataSource) Schema(ctx context.Context, req datasource.SchemaR
 
 		Attributes: map[string]schema.Attribute{
 			"environment_key": schema.StringAttribute{
-				MarkdownDescription: "Environment key (defaults to 'default')",
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
 				Optional:            true,
+				Computed:            true,
 			},
 			"key": schema.StringAttribute{
 				MarkdownDescription: "Unique key for the namespace",
@@ -93,11 +93,8 @@ func (d *NamespaceDataSource) Read(ctx context.Context, req datasource.ReadReque
 		return
 	}
 
-	// Default to "default" environment if not specified
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the data source
+	envKey := d.config.EnvironmentKey(data.EnvironmentKey)
 	data.EnvironmentKey = types.StringValue(envKey)
 
 	tflog.Debug(ctx, "Reading namespace", map[string]interface{}{
@@ -106,53 +103,15 @@ func (d *NamespaceDataSource) Read(ctx context.Context, req datasource.ReadReque
 	})
 
 	// Get the namespace from Flipt
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s", d.config.Endpoint, envKey, data.Key.ValueString())
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-
-	d.config.AddAuthHeader(httpReq)
-	httpResp, err := d.config.HTTPClient.Do(httpReq)
+	namespace, err := d.config.Client.GetNamespace(ctx, envKey, data.Key.ValueString())
 	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			resp.Diagnostics.AddError("Not Found", fmt.Sprintf("Namespace with key '%s' not found", data.Key.ValueString()))
+			return
+		}
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read namespace, got error: %s", err))
 		return
 	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode == http.StatusNotFound {
-		resp.Diagnostics.AddError("Not Found", fmt.Sprintf("Namespace with key '%s' not found", data.Key.ValueString()))
-		return
-	}
-
-	body, err := io.ReadAll(httpResp.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Read Error", fmt.Sprintf("Unable to read response: %s", err))
-		return
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read namespace, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var response struct {
-		Namespace struct {
-			Key         string `json:"key"`
-			Name        string `json:"name"`
-			Description string `json:"description"`
-			Protected   bool   `json:"protected"`
-		} `json:"namespace"`
-		Revision string `json:"revision"`
-	}
-
-	if err := json.Unmarshal(body, &response); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s", err))
-		return
-	}
-
-	namespace := response.Namespace
 
 	data.Key = types.StringValue(namespace.Key)
 	data.Name = types.StringValue(namespace.Name)
@@ -4,25 +4,27 @@
 package provider
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &NamespaceResource{}
 var _ resource.ResourceWithImportState = &NamespaceResource{}
+var _ resource.ResourceWithModifyPlan = &NamespaceResource{}
 
 func NewNamespaceResource() resource.Resource {
 	return &NamespaceResource{}
@@ -40,6 +42,7 @@ type NamespaceResourceModel struct {
 	Name           types.String `tfsdk:"name"`
 	Description    types.String `tfsdk:"description"`
 	Protected      types.Bool   `tfsdk:"protected"`
+	ForceDestroy   types.Bool   `tfsdk:"force_destroy"`
 }
 
 func (r *NamespaceResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -52,7 +55,7 @@ func (r *NamespaceResource) Schema(ctx context.Context, req resource.SchemaReque
 
 		Attributes: map[string]schema.Attribute{
 			"environment_key": schema.StringAttribute{
-				MarkdownDescription: "Environment key (defaults to 'default')",
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
 				Optional:            true,
 				Computed:            true,
 				PlanModifiers: []planmodifier.String{
@@ -61,8 +64,11 @@ func (r *NamespaceResource) Schema(ctx context.Context, req resource.SchemaReque
 				},
 			},
 			"key": schema.StringAttribute{
-				MarkdownDescription: "Unique key for the namespace",
+				MarkdownDescription: "Unique key for the namespace. Letters, numbers, `-`, `_` and `,` only",
 				Required:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
@@ -80,6 +86,12 @@ func (r *NamespaceResource) Schema(ctx context.Context, req resource.SchemaReque
 				Optional:            true,
 				Computed:            true,
 			},
+			"force_destroy": schema.BoolAttribute{
+				MarkdownDescription: "Delete every flag and segment in the namespace, including those not managed by Terraform, and clear its protection when the namespace is destroyed. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
 		},
 	}
 }
@@ -104,6 +116,10 @@ func (r *NamespaceResource) Configure(ctx context.Context, req resource.Configur
 	r.config = providerConfig
 }
 
+func (r *NamespaceResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultKeys(ctx, r.config, false, req, resp)
+}
+
 func (r *NamespaceResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data NamespaceResourceModel
 
@@ -114,11 +130,8 @@ func (r *NamespaceResource) Create(ctx context.Context, req resource.CreateReque
 		return
 	}
 
-	// Default to "default" environment if not specified
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
 	data.EnvironmentKey = types.StringValue(envKey)
 
 	tflog.Debug(ctx, "Creating namespace", map[string]interface{}{
@@ -127,39 +140,7 @@ func (r *NamespaceResource) Create(ctx context.Context, req resource.CreateReque
 		"name":            data.Name.ValueString(),
 	})
 
-	// Create the namespace using manual HTTP request
-	createReq := map[string]interface{}{
-		"key":  data.Key.ValueString(),
-		"name": data.Name.ValueString(),
-	}
-
-	if !data.Description.IsNull() {
-		createReq["description"] = data.Description.ValueString()
-	}
-
-	reqBody, err := json.Marshal(createReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Serialization Error", fmt.Sprintf("Unable to marshal request: %s", err))
-		return
-	}
-
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces", r.config.Endpoint, envKey)
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	tflog.Debug(ctx, "Making HTTP request", map[string]interface{}{
-		"method":          "POST",
-		"url":             url,
-		"environment_key": envKey,
-		"key":             data.Key.ValueString(),
-	})
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
+	namespace, err := r.config.Client.CreateNamespace(ctx, envKey, namespaceFromModel(data))
 	if err != nil {
 		tflog.Error(ctx, "Failed to create namespace", map[string]interface{}{
 			"error":           err.Error(),
@@ -169,49 +150,6 @@ func (r *NamespaceResource) Create(ctx context.Context, req resource.CreateReque
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create namespace, got error: %s", err))
 		return
 	}
-	defer httpResp.Body.Close()
-
-	// Read the response body first so we can log it
-	body, err := io.ReadAll(httpResp.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Read Error", fmt.Sprintf("Unable to read response: %s", err))
-		return
-	}
-
-	tflog.Debug(ctx, "Received create response", map[string]interface{}{
-		"status_code":     httpResp.StatusCode,
-		"response_body":   string(body),
-		"environment_key": envKey,
-		"key":             data.Key.ValueString(),
-	})
-
-	if httpResp.StatusCode != http.StatusOK && httpResp.StatusCode != http.StatusCreated {
-		tflog.Error(ctx, "Failed to create namespace", map[string]interface{}{
-			"status_code":     httpResp.StatusCode,
-			"response_body":   string(body),
-			"environment_key": envKey,
-			"key":             data.Key.ValueString(),
-		})
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to create namespace, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var response struct {
-		Namespace struct {
-			Key         string `json:"key"`
-			Name        string `json:"name"`
-			Description string `json:"description"`
-			Protected   bool   `json:"protected"`
-		} `json:"namespace"`
-		Revision string `json:"revision"`
-	}
-
-	if err := json.Unmarshal(body, &response); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s, body: %s", err, string(body)))
-		return
-	}
-
-	namespace := response.Namespace
 
 	tflog.Debug(ctx, "Parsed namespace from response", map[string]interface{}{
 		"key":         namespace.Key,
@@ -229,12 +167,6 @@ func (r *NamespaceResource) Create(ctx context.Context, req resource.CreateReque
 	// Always set Computed fields
 	data.Protected = types.BoolValue(namespace.Protected)
 
-	tflog.Debug(ctx, "Saving state after create", map[string]interface{}{
-		"key":       data.Key.ValueString(),
-		"name":      data.Name.ValueString(),
-		"protected": data.Protected.ValueBool(),
-	})
-
 	tflog.Trace(ctx, "created a namespace resource")
 
 	// Save data into Terraform state
@@ -251,11 +183,9 @@ func (r *NamespaceResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	// Default to "default" environment if not specified
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
 
 	tflog.Debug(ctx, "Reading namespace", map[string]interface{}{
 		"environment_key": envKey,
@@ -263,60 +193,16 @@ func (r *NamespaceResource) Read(ctx context.Context, req resource.ReadRequest,
 	})
 
 	// Get the namespace from Flipt
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s", r.config.Endpoint, envKey, data.Key.ValueString())
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
+	namespace, err := r.config.Client.GetNamespace(ctx, envKey, data.Key.ValueString())
 	if err != nil {
-		tflog.Warn(ctx, "Namespace not found, removing from state", map[string]interface{}{
-			"error":           err.Error(),
-			"environment_key": envKey,
-			"key":             data.Key.ValueString(),
-		})
-		resp.State.RemoveResource(ctx)
-		return
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode == http.StatusNotFound {
-		tflog.Warn(ctx, "Namespace not found, removing from state", map[string]interface{}{
-			"environment_key": envKey,
-			"key":             data.Key.ValueString(),
-		})
-		resp.State.RemoveResource(ctx)
-		return
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read namespace, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var response struct {
-		Namespace struct {
-			Key         string `json:"key"`
-			Name        string `json:"name"`
-			Description string `json:"description"`
-			Protected   bool   `json:"protected"`
-		} `json:"namespace"`
-		Revision string `json:"revision"`
-	}
-
-	if err := json.NewDecoder(httpResp.Body).Decode(&response); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s", err))
+		handleReadError(ctx, resp, "namespace", data.Key.ValueString(), err)
 		return
 	}
 
-	namespace := response.Namespace
+	// The name is read as well so that imported namespaces and changes made
+	// outside of Terraform show up in the state
+	data.Name = types.StringValue(namespace.Name)
 
-	// Don't overwrite Required fields (key, name) - they should remain as they are in state
-	// Only update Optional and Computed fields
 	if namespace.Description != "" {
 		data.Description = types.StringValue(namespace.Description)
 	} else {
@@ -325,6 +211,12 @@ func (r *NamespaceResource) Read(ctx context.Context, req resource.ReadRequest,
 
 	data.Protected = types.BoolValue(namespace.Protected)
 
+	// force_destroy only exists in Terraform, imported namespaces start
+	// with the default
+	if data.ForceDestroy.IsNull() {
+		data.ForceDestroy = types.BoolValue(false)
+	}
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -339,11 +231,9 @@ func (r *NamespaceResource) Update(ctx context.Context, req resource.UpdateReque
 		return
 	}
 
-	// Default to "default" environment if not specified
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
 
 	tflog.Debug(ctx, "Updating namespace", map[string]interface{}{
 		"environment_key": envKey,
@@ -351,32 +241,7 @@ func (r *NamespaceResource) Update(ctx context.Context, req resource.UpdateReque
 		"name":            data.Name.ValueString(),
 	})
 
-	// Update the namespace
-	updateReq := map[string]interface{}{
-		"key":  data.Key.ValueString(),
-		"name": data.Name.ValueString(),
-	}
-
-	if !data.Description.IsNull() {
-		updateReq["description"] = data.Description.ValueString()
-	}
-
-	reqBody, err := json.Marshal(updateReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Serialization Error", fmt.Sprintf("Unable to marshal request: %s", err))
-		return
-	}
-
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces", r.config.Endpoint, envKey)
-	httpReq, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewReader(reqBody))
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
+	namespace, err := r.config.Client.UpdateNamespace(ctx, envKey, namespaceFromModel(data))
 	if err != nil {
 		tflog.Error(ctx, "Failed to update namespace", map[string]interface{}{
 			"error":           err.Error(),
@@ -386,36 +251,6 @@ func (r *NamespaceResource) Update(ctx context.Context, req resource.UpdateReque
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update namespace, got error: %s", err))
 		return
 	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		tflog.Error(ctx, "Failed to update namespace", map[string]interface{}{
-			"status_code":     httpResp.StatusCode,
-			"response_body":   string(body),
-			"environment_key": envKey,
-			"key":             data.Key.ValueString(),
-		})
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to update namespace, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var response struct {
-		Namespace struct {
-			Key         string `json:"key"`
-			Name        string `json:"name"`
-			Description string `json:"description"`
-			Protected   bool   `json:"protected"`
-		} `json:"namespace"`
-		Revision string `json:"revision"`
-	}
-
-	if err := json.NewDecoder(httpResp.Body).Decode(&response); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s", err))
-		return
-	}
-
-	namespace := response.Namespace
 
 	// Don't overwrite Required fields (key, name) - use values from plan
 	// Only update Optional and Computed fields
@@ -441,13 +276,6 @@ func (r *NamespaceResource) Delete(ctx context.Context, req resource.DeleteReque
 		return
 	}
 
-	tflog.Debug(ctx, "State data retrieved", map[string]interface{}{
-		"key_is_null":    data.Key.IsNull(),
-		"key_is_unknown": data.Key.IsUnknown(),
-		"key_value":      data.Key.ValueString(),
-		"raw_state":      fmt.Sprintf("%+v", data),
-	})
-
 	// Validate that key is present
 	if data.Key.IsNull() || data.Key.ValueString() == "" {
 		resp.Diagnostics.AddError("Missing Namespace Key",
@@ -455,93 +283,125 @@ func (r *NamespaceResource) Delete(ctx context.Context, req resource.DeleteReque
 		return
 	}
 
-	// Default to "default" environment if not specified
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
 
 	tflog.Debug(ctx, "Deleting namespace", map[string]interface{}{
 		"environment_key": envKey,
 		"key":             data.Key.ValueString(),
+		"force_destroy":   data.ForceDestroy.ValueBool(),
 	})
 
-	// Delete the namespace
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s", r.config.Endpoint, envKey, data.Key.ValueString())
-
-	tflog.Debug(ctx, "Making DELETE request", map[string]interface{}{
-		"method":          "DELETE",
-		"url":             url,
-		"endpoint":        r.config.Endpoint,
-		"environment_key": envKey,
-		"key":             data.Key.ValueString(),
-	})
-
-	httpReq, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
+	if data.ForceDestroy.ValueBool() {
+		err := r.deleteContents(ctx, envKey, data.Key.ValueString())
+		if errors.Is(err, client.ErrNotFound) {
+			return
+		}
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to force destroy namespace '%s': %s", data.Key.ValueString(), err))
+			return
+		}
 	}
 
-	tflog.Debug(ctx, "HTTP Request details", map[string]interface{}{
-		"method": httpReq.Method,
-		"url":    httpReq.URL.String(),
-		"host":   httpReq.Host,
-		"header": fmt.Sprintf("%v", httpReq.Header),
-	})
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
+	err := r.config.Client.DeleteNamespace(ctx, envKey, data.Key.ValueString())
 	if err != nil {
+		// If namespace is already gone (404), consider it a success
+		if errors.Is(err, client.ErrNotFound) {
+			tflog.Debug(ctx, "Namespace already deleted", map[string]interface{}{
+				"environment_key": envKey,
+				"key":             data.Key.ValueString(),
+			})
+			return
+		}
+
 		tflog.Error(ctx, "Failed to delete namespace", map[string]interface{}{
 			"error":           err.Error(),
 			"environment_key": envKey,
 			"key":             data.Key.ValueString(),
 		})
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete namespace, got error: %s", err))
+
+		// If namespace is protected or has resources, provide a helpful message
+		var apiErr *client.APIError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotImplemented || apiErr.StatusCode == http.StatusMethodNotAllowed) {
+			resp.Diagnostics.AddError("Namespace Cannot Be Deleted",
+				fmt.Sprintf("Unable to delete namespace '%s'. The namespace may be protected or contain resources that must be deleted first. "+
+					"Set force_destroy to delete its flags and segments along with it. Status: %d, Response: %s",
+					data.Key.ValueString(), apiErr.StatusCode, apiErr.Body))
+		} else {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete namespace, got error: %s", err))
+		}
 		return
 	}
-	defer httpResp.Body.Close()
 
-	tflog.Debug(ctx, "Received DELETE response", map[string]interface{}{
-		"status_code":     httpResp.StatusCode,
-		"environment_key": envKey,
-		"key":             data.Key.ValueString(),
-	})
+	tflog.Trace(ctx, "deleted a namespace resource")
+}
 
-	// If namespace is already gone (404), consider it a success
-	if httpResp.StatusCode == http.StatusNotFound {
-		tflog.Debug(ctx, "Namespace already deleted", map[string]interface{}{
-			"environment_key": envKey,
-			"key":             data.Key.ValueString(),
-		})
+// ImportState imports a namespace by an ID of the form
+// environment_key/namespace_key.
+func (r *NamespaceResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts, ok := parseImportID(req, resp, "environment_key", "namespace_key")
+	if !ok {
 		return
 	}
 
-	if httpResp.StatusCode != http.StatusOK && httpResp.StatusCode != http.StatusNoContent {
-		body, _ := io.ReadAll(httpResp.Body)
-		tflog.Error(ctx, "Failed to delete namespace", map[string]interface{}{
-			"status_code":     httpResp.StatusCode,
-			"response_body":   string(body),
-			"environment_key": envKey,
-			"key":             data.Key.ValueString(),
-			"url":             url,
-		})
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("environment_key"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("key"), parts[1])...)
+}
 
-		// If namespace is protected or has resources, provide a helpful message
-		if httpResp.StatusCode == http.StatusNotImplemented || httpResp.StatusCode == http.StatusMethodNotAllowed {
-			resp.Diagnostics.AddError("Namespace Cannot Be Deleted",
-				fmt.Sprintf("Unable to delete namespace '%s'. The namespace may be protected or contain resources that must be deleted first. Status: %d, Response: %s",
-					data.Key.ValueString(), httpResp.StatusCode, string(body)))
-		} else {
-			resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to delete namespace, status: %d, body: %s", httpResp.StatusCode, string(body)))
+// deleteContents deletes every flag and then every segment in a namespace, as
+// rules and rollouts of flags refer to segments, and clears the protection of
+// the namespace so that it can be deleted. ErrNotFound is returned when the
+// namespace no longer exists.
+func (r *NamespaceResource) deleteContents(ctx context.Context, envKey, nsKey string) error {
+	flags, err := r.config.Client.ListFlags(ctx, envKey, nsKey)
+	if err != nil {
+		return fmt.Errorf("unable to list flags: %w", err)
+	}
+	for _, flag := range flags {
+		tflog.Debug(ctx, "Force destroying flag", map[string]interface{}{"namespace_key": nsKey, "key": flag.Key})
+		err := r.config.Client.DeleteResource(ctx, envKey, nsKey, client.TypeFlag, flag.Key)
+		if err != nil && !errors.Is(err, client.ErrNotFound) {
+			return fmt.Errorf("unable to delete flag '%s': %w", flag.Key, err)
 		}
-		return
 	}
 
-	tflog.Trace(ctx, "deleted a namespace resource")
+	segments, err := r.config.Client.ListSegments(ctx, envKey, nsKey)
+	if err != nil {
+		return fmt.Errorf("unable to list segments: %w", err)
+	}
+	for _, segment := range segments {
+		tflog.Debug(ctx, "Force destroying segment", map[string]interface{}{"namespace_key": nsKey, "key": segment.Key})
+		err := r.config.Client.DeleteResource(ctx, envKey, nsKey, client.TypeSegment, segment.Key)
+		if err != nil && !errors.Is(err, client.ErrNotFound) {
+			return fmt.Errorf("unable to delete segment '%s': %w", segment.Key, err)
+		}
+	}
+
+	namespace, err := r.config.Client.GetNamespace(ctx, envKey, nsKey)
+	if err != nil {
+		return fmt.Errorf("unable to read namespace: %w", err)
+	}
+	if namespace.Protected {
+		namespace.Protected = false
+		if _, err := r.config.Client.UpdateNamespace(ctx, envKey, namespace); err != nil {
+			return fmt.Errorf("unable to clear the protection: %w", err)
+		}
+	}
+
+	return nil
 }
 
-func (r *NamespaceResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("key"), req, resp)
+// namespaceFromModel builds the namespace request body from the resource model.
+func namespaceFromModel(data NamespaceResourceModel) *client.Namespace {
+	namespace := &client.Namespace{
+		Key:  data.Key.ValueString(),
+		Name: data.Name.ValueString(),
+	}
+
+	if !data.Description.IsNull() {
+		namespace.Description = data.Description.ValueString()
+	}
+
+	return namespace
 }
@@ -4,12 +4,17 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"terraform-provider-flipt/internal/client"
 )
 
 func TestAccNamespaceResource(t *testing.T) {
@@ -27,6 +32,13 @@ func TestAccNamespaceResource(t *testing.T) {
 					resource.TestCheckResourceAttr("flipt_namespace.test", "description", "Test description"),
 				),
 			},
+			// ImportState testing
+			{
+				ResourceName:      "flipt_namespace.test",
+				ImportState:       true,
+				ImportStateId:     "default/test-namespace",
+				ImportStateVerify: true,
+			},
 			// Update and Read testing
 			{
 				Config: testAccNamespaceResourceConfig("default", "test-namespace", "Updated Namespace", "Updated description"),
@@ -55,6 +67,57 @@ resource "flipt_namespace" "test" {
 `
 }
 
+func TestAccNamespaceResourceForceDestroy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNamespaceResourceForceDestroyConfig(),
+				Check:  resource.TestCheckResourceAttr("flipt_namespace.test", "force_destroy", "true"),
+			},
+			{
+				// Add a flag and a segment outside of Terraform, then destroy
+				// the namespace together with them
+				PreConfig: func() {
+					ctx := context.Background()
+					c := client.New(client.Config{Endpoint: getTestFliptEndpoint()})
+					if _, err := c.CreateSegment(ctx, "default", "force-destroy", &client.Segment{
+						Key: "unmanaged-segment", Name: "Unmanaged", MatchType: "ALL_MATCH_TYPE",
+					}); err != nil {
+						t.Fatalf("Unable to create segment: %v", err)
+					}
+					if _, err := c.CreateFlag(ctx, "default", "force-destroy", &client.Flag{
+						Key: "unmanaged-flag", Name: "Unmanaged", Type: "BOOLEAN_FLAG_TYPE",
+						Rollouts: []client.Rollout{{
+							Type:    segmentRolloutType,
+							Segment: &client.RolloutSegment{Segments: []string{"unmanaged-segment"}, SegmentOperator: "OR_SEGMENT_OPERATOR", Value: true},
+						}},
+					}); err != nil {
+						t.Fatalf("Unable to create flag: %v", err)
+					}
+				},
+				Config:  testAccNamespaceResourceForceDestroyConfig(),
+				Destroy: true,
+			},
+		},
+	})
+}
+
+func testAccNamespaceResourceForceDestroyConfig() string {
+	return `
+provider "flipt" {
+  endpoint = "` + getTestFliptEndpoint() + `"
+}
+
+resource "flipt_namespace" "test" {
+  key           = "force-destroy"
+  name          = "Force Destroy"
+  force_destroy = true
+}
+`
+}
+
 func TestNamespaceResourceSchema(t *testing.T) {
 	r := NewNamespaceResource()
 
@@ -131,3 +194,65 @@ func TestNamespaceResourceCRUD(t *testing.T) {
 		t.Fatal("Expected server URL to be set")
 	}
 }
+
+func TestNamespaceResourceForceDestroy(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.pageSize = 2
+	fake.namespaces["default/team-a"] = map[string]interface{}{"key": "team-a", "name": "Team A", "protected": true}
+	for _, key := range []string{"a", "b", "c"} {
+		fake.put("default", "team-a", client.TypeFlag, key, map[string]interface{}{"name": key, "type": "BOOLEAN_FLAG_TYPE"})
+	}
+	for _, key := range []string{"x", "y"} {
+		fake.put("default", "team-a", client.TypeSegment, key, map[string]interface{}{"name": key, "matchType": "ALL_MATCH_TYPE"})
+	}
+	fake.put("default", "default", client.TypeFlag, "other", map[string]interface{}{"name": "other", "type": "BOOLEAN_FLAG_TYPE"})
+
+	r := NewNamespaceResource()
+	configureResource(t, r, fake.config())
+
+	state := NamespaceResourceModel{
+		EnvironmentKey: types.StringValue("default"),
+		Key:            types.StringValue("team-a"),
+		Name:           types.StringValue("Team A"),
+		Description:    types.StringValue(""),
+		Protected:      types.BoolValue(true),
+		ForceDestroy:   types.BoolValue(false),
+	}
+
+	resp := deleteResource(t, r, state)
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("Expected an error deleting a protected namespace without force_destroy")
+	}
+	if d := resp.Diagnostics.Errors()[0]; !strings.Contains(d.Detail(), "force_destroy") {
+		t.Errorf("Expected the error to mention force_destroy, got: %s", d.Detail())
+	}
+	if len(fake.deletes) != 0 {
+		t.Fatalf("Expected no resources to be deleted, got %v", fake.deletes)
+	}
+
+	state.ForceDestroy = types.BoolValue(true)
+	resp = deleteResource(t, r, state)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Delete failed: %v", resp.Diagnostics)
+	}
+
+	expected := []string{
+		client.TypeFlag + "/a", client.TypeFlag + "/b", client.TypeFlag + "/c",
+		client.TypeSegment + "/x", client.TypeSegment + "/y",
+	}
+	if !reflect.DeepEqual(fake.deletes, expected) {
+		t.Errorf("Expected flags to be deleted before segments, got %v", fake.deletes)
+	}
+	if _, ok := fake.namespaces["default/team-a"]; ok {
+		t.Error("Expected the namespace to be deleted")
+	}
+	if fake.get("default", "default", client.TypeFlag, "other") == nil {
+		t.Error("Expected flags of other namespaces to be kept")
+	}
+
+	// A namespace that is already gone is not an error
+	resp = deleteResource(t, r, state)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Delete of a missing namespace failed: %v", resp.Diagnostics)
+	}
+}
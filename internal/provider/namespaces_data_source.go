@@ -0,0 +1,148 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
+)
+
+var _ datasource.DataSource = &NamespacesDataSource{}
+
+func NewNamespacesDataSource() datasource.DataSource {
+	return &NamespacesDataSource{}
+}
+
+type NamespacesDataSource struct {
+	config *FliptProviderConfig
+}
+
+type NamespacesDataSourceModel struct {
+	EnvironmentKey types.String                         `tfsdk:"environment_key"`
+	KeyPrefix      types.String                         `tfsdk:"key_prefix"`
+	Namespaces     []NamespacesDataSourceNamespaceModel `tfsdk:"namespaces"`
+}
+
+type NamespacesDataSourceNamespaceModel struct {
+	Key         types.String `tfsdk:"key"`
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"`
+	Protected   types.Bool   `tfsdk:"protected"`
+}
+
+func (d *NamespacesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_namespaces"
+}
+
+func (d *NamespacesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the namespaces of an environment, optionally filtered",
+		Description:         "Lists the namespaces of an environment, optionally filtered",
+
+		Attributes: map[string]schema.Attribute{
+			"environment_key": schema.StringAttribute{
+				MarkdownDescription: "Environment key to list the namespaces of. Defaults to the provider's `default_environment_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+			},
+			"key_prefix": schema.StringAttribute{
+				MarkdownDescription: "Only list namespaces whose key starts with this prefix",
+				Optional:            true,
+			},
+			"namespaces": schema.ListNestedAttribute{
+				MarkdownDescription: "Namespaces matching the filters, ordered by key",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"key": schema.StringAttribute{
+							MarkdownDescription: "Unique key of the namespace",
+							Computed:            true,
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Display name of the namespace",
+							Computed:            true,
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "Description of the namespace",
+							Computed:            true,
+						},
+						"protected": schema.BoolAttribute{
+							MarkdownDescription: "Whether the namespace is protected",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *NamespacesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerConfig, ok := req.ProviderData.(*FliptProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *FliptProviderConfig, got: %T", req.ProviderData),
+		)
+		return
+	}
+
+	d.config = providerConfig
+}
+
+func (d *NamespacesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data NamespacesDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider default for the environment if not set
+	envKey := d.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+
+	tflog.Debug(ctx, "Listing namespaces", map[string]interface{}{
+		"environment_key": envKey,
+	})
+
+	namespaces, err := d.config.Client.ListNamespaces(ctx, envKey)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list namespaces in environment '%s', got error: %s", envKey, err))
+		return
+	}
+	slices.SortFunc(namespaces, func(a, b client.Namespace) int { return strings.Compare(a.Key, b.Key) })
+
+	data.Namespaces = []NamespacesDataSourceNamespaceModel{}
+	for _, namespace := range namespaces {
+		if !strings.HasPrefix(namespace.Key, data.KeyPrefix.ValueString()) {
+			continue
+		}
+
+		item := NamespacesDataSourceNamespaceModel{
+			Key:         types.StringValue(namespace.Key),
+			Name:        types.StringValue(namespace.Name),
+			Description: types.StringNull(),
+			Protected:   types.BoolValue(namespace.Protected),
+		}
+		if namespace.Description != "" {
+			item.Description = types.StringValue(namespace.Description)
+		}
+		data.Namespaces = append(data.Namespaces, item)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
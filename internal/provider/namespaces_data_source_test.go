@@ -0,0 +1,120 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccNamespacesDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNamespacesDataSourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.flipt_namespaces.teams", "namespaces.#", "2"),
+					resource.TestCheckResourceAttr("data.flipt_namespaces.teams", "namespaces.0.key", "team-a"),
+					resource.TestCheckResourceAttr("data.flipt_namespaces.teams", "namespaces.0.description", "Team A flags"),
+					resource.TestCheckResourceAttr("data.flipt_namespaces.teams", "namespaces.1.key", "team-b"),
+				),
+			},
+		},
+	})
+}
+
+func testAccNamespacesDataSourceConfig() string {
+	return `
+provider "flipt" {
+  endpoint = "` + getTestFliptEndpoint() + `"
+}
+
+resource "flipt_namespace" "team_a" {
+  key         = "team-a"
+  name        = "Team A"
+  description = "Team A flags"
+}
+
+resource "flipt_namespace" "team_b" {
+  key  = "team-b"
+  name = "Team B"
+}
+
+data "flipt_namespaces" "teams" {
+  key_prefix = "team-"
+  depends_on = [flipt_namespace.team_a, flipt_namespace.team_b]
+}
+`
+}
+
+func TestNamespacesDataSource(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.namespaces["default/team-b"] = map[string]interface{}{"key": "team-b", "name": "Team B"}
+	fake.namespaces["default/team-a"] = map[string]interface{}{"key": "team-a", "name": "Team A", "description": "Team A flags"}
+	fake.namespaces["staging/team-c"] = map[string]interface{}{"key": "team-c", "name": "Team C"}
+
+	tests := []struct {
+		name   string
+		config map[string]tftypes.Value
+		want   []string
+	}{
+		{
+			name: "all",
+			want: []string{"default", "team-a", "team-b"},
+		},
+		{
+			name:   "key prefix",
+			config: map[string]tftypes.Value{"key_prefix": tftypes.NewValue(tftypes.String, "team-")},
+			want:   []string{"team-a", "team-b"},
+		},
+		{
+			name:   "environment",
+			config: map[string]tftypes.Value{"environment_key": tftypes.NewValue(tftypes.String, "staging")},
+			want:   []string{"team-c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewNamespacesDataSource()
+			configureDataSource(t, d, fake.config())
+
+			resp, state := readDataSource(t, d, tt.config)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Read failed: %v", resp.Diagnostics)
+			}
+
+			var data NamespacesDataSourceModel
+			if diags := state.Get(context.Background(), &data); diags.HasError() {
+				t.Fatalf("Unable to read state: %v", diags)
+			}
+
+			keys := []string{}
+			for _, namespace := range data.Namespaces {
+				keys = append(keys, namespace.Key.ValueString())
+			}
+			if !reflect.DeepEqual(keys, tt.want) {
+				t.Errorf("Expected namespaces %v, got %v", tt.want, keys)
+			}
+			for _, namespace := range data.Namespaces {
+				switch namespace.Key.ValueString() {
+				case "default":
+					if !namespace.Protected.ValueBool() {
+						t.Error("Expected the default namespace to be protected")
+					}
+				case "team-a":
+					if namespace.Description.ValueString() != "Team A flags" {
+						t.Errorf("Unexpected namespace %+v", namespace)
+					}
+				}
+			}
+		})
+	}
+}
@@ -5,13 +5,17 @@ package provider
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"terraform-provider-flipt/internal/client"
 )
 
 // Ensure FliptProvider satisfies various provider interfaces.
@@ -23,6 +27,10 @@ type FliptProvider struct {
 	// provider is built and ran locally, and "test" when running acceptance
 	// testing.
 	version string
+
+	// transport replaces the HTTP transport of the Flipt client. It is only
+	// set by tests to capture the requests sent to Flipt.
+	transport http.RoundTripper
 }
 
 // FliptProviderModel describes the provider data model.
@@ -30,23 +38,76 @@ type FliptProviderModel struct {
 	Endpoint types.String `tfsdk:"endpoint"`
 	Token    types.String `tfsdk:"token"`
 	JWT      types.String `tfsdk:"jwt"`
+
+	MaxRetries     types.Int64  `tfsdk:"max_retries"`
+	RetryWaitMin   types.String `tfsdk:"retry_wait_min"`
+	RetryWaitMax   types.String `tfsdk:"retry_wait_max"`
+	RequestTimeout types.String `tfsdk:"request_timeout"`
+
+	CACertFile         types.String `tfsdk:"ca_cert_file"`
+	CACertPEM          types.String `tfsdk:"ca_cert_pem"`
+	ClientCert         types.String `tfsdk:"client_cert"`
+	ClientKey          types.String `tfsdk:"client_key"`
+	TLSServerName      types.String `tfsdk:"tls_server_name"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+
+	DefaultEnvironmentKey types.String `tfsdk:"default_environment_key"`
+	DefaultNamespaceKey   types.String `tfsdk:"default_namespace_key"`
 }
 
-// FliptProviderConfig holds the configured HTTP client and endpoint for resources.
+// Defaults for the HTTP retry and timeout settings.
+const (
+	defaultMaxRetries     = 3
+	defaultRetryWaitMin   = time.Second
+	defaultRetryWaitMax   = 30 * time.Second
+	defaultRequestTimeout = 30 * time.Second
+
+	// fallbackKey is the environment and namespace key used when neither the
+	// resource nor the provider configuration sets one.
+	fallbackKey = "default"
+)
+
+// FliptProviderConfig holds the configured Flipt client for resources.
 type FliptProviderConfig struct {
-	HTTPClient *http.Client
-	Endpoint   string
-	Token      string
-	JWT        string
+	// Client is the typed Flipt API client shared by all resources and data sources.
+	Client *client.Client
+
+	// DefaultEnvironmentKey and DefaultNamespaceKey are used by resources
+	// and data sources that do not set their own keys.
+	DefaultEnvironmentKey string
+	DefaultNamespaceKey   string
+
+	locks parentLocks
 }
 
-// AddAuthHeader adds the appropriate authentication header to an HTTP request.
-func (c *FliptProviderConfig) AddAuthHeader(req *http.Request) {
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else if c.JWT != "" {
-		req.Header.Set("Authorization", "JWT "+c.JWT)
+// LockParent serializes changes to the flag or segment identified by the
+// given keys. It must be held across the GET and PUT of a read-modify-write
+// cycle on the parent's payload. The returned function releases the lock.
+func (c *FliptProviderConfig) LockParent(envKey, namespaceKey, typeURL, key string) func() {
+	return c.locks.lock(envKey, namespaceKey, typeURL, key)
+}
+
+// EnvironmentKey returns the environment key set on a resource or data
+// source, falling back to the provider's default environment and finally to
+// "default".
+func (c *FliptProviderConfig) EnvironmentKey(value types.String) string {
+	return resolveKey(value, c.DefaultEnvironmentKey)
+}
+
+// NamespaceKey returns the namespace key set on a resource or data source,
+// falling back to the provider's default namespace and finally to "default".
+func (c *FliptProviderConfig) NamespaceKey(value types.String) string {
+	return resolveKey(value, c.DefaultNamespaceKey)
+}
+
+func resolveKey(value types.String, providerDefault string) string {
+	if !value.IsNull() && !value.IsUnknown() && value.ValueString() != "" {
+		return value.ValueString()
 	}
+	if providerDefault != "" {
+		return providerDefault
+	}
+	return fallbackKey
 }
 
 func (p *FliptProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -58,19 +119,78 @@ func (p *FliptProvider) Schema(ctx context.Context, req provider.SchemaRequest,
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"endpoint": schema.StringAttribute{
-				MarkdownDescription: "Flipt server endpoint URL",
-				Required:            true,
+				MarkdownDescription: "Flipt server endpoint URL. Can also be set with the `FLIPT_ENDPOINT` environment variable.",
+				Optional:            true,
 			},
 			"token": schema.StringAttribute{
-				MarkdownDescription: "Static authentication token for Bearer authentication",
+				MarkdownDescription: "Static authentication token for Bearer authentication. Can also be set with the `FLIPT_TOKEN` environment variable.",
 				Optional:            true,
 				Sensitive:           true,
 			},
 			"jwt": schema.StringAttribute{
-				MarkdownDescription: "JWT token for JWT authentication",
+				MarkdownDescription: "JWT token for JWT authentication. Can also be set with the `FLIPT_JWT` environment variable.",
 				Optional:            true,
 				Sensitive:           true,
 			},
+			"max_retries": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("Maximum number of times a failed request is retried. Rate limited (429) requests are always retried, "+
+					"gateway errors (502, 503, 504) and connection errors only for idempotent requests. Set to `0` to disable retries. Defaults to `%d`. Can also be set with the `FLIPT_MAX_RETRIES` environment variable.", defaultMaxRetries),
+				Optional: true,
+			},
+			"retry_wait_min": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Wait before the first retry as a duration, e.g. `500ms`. "+
+					"The wait doubles with every further retry unless the server sends a `Retry-After` header. Defaults to `%s`. Can also be set with the `FLIPT_RETRY_WAIT_MIN` environment variable.", defaultRetryWaitMin),
+				Optional: true,
+			},
+			"retry_wait_max": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Maximum wait between retries as a duration, e.g. `1m`. Defaults to `%s`. Can also be set with the `FLIPT_RETRY_WAIT_MAX` environment variable.", defaultRetryWaitMax),
+				Optional:            true,
+			},
+			"request_timeout": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Timeout for a single HTTP request as a duration, e.g. `10s`. Set to `0s` to disable the timeout. Defaults to `%s`. Can also be set with the `FLIPT_REQUEST_TIMEOUT` environment variable.", defaultRequestTimeout),
+				Optional:            true,
+			},
+			"ca_cert_file": schema.StringAttribute{
+				MarkdownDescription: "Path to a PEM encoded CA certificate bundle used in addition to the system roots to verify the Flipt server. " +
+					"Conflicts with `ca_cert_pem`. Can also be set with the `FLIPT_CA_CERT_FILE` environment variable.",
+				Optional: true,
+			},
+			"ca_cert_pem": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded CA certificate bundle used in addition to the system roots to verify the Flipt server. " +
+					"Conflicts with `ca_cert_file`. Can also be set with the `FLIPT_CA_CERT_PEM` environment variable.",
+				Optional: true,
+			},
+			"client_cert": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded client certificate, or the path to a file containing it, for mutual TLS. " +
+					"Requires `client_key`. Can also be set with the `FLIPT_CLIENT_CERT` environment variable.",
+				Optional: true,
+			},
+			"client_key": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded private key of the client certificate, or the path to a file containing it. " +
+					"Requires `client_cert`. Can also be set with the `FLIPT_CLIENT_KEY` environment variable.",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"tls_server_name": schema.StringAttribute{
+				MarkdownDescription: "Server name used to verify the certificate of the Flipt server instead of the host of `endpoint`. " +
+					"Can also be set with the `FLIPT_TLS_SERVER_NAME` environment variable.",
+				Optional: true,
+			},
+			"default_environment_key": schema.StringAttribute{
+				MarkdownDescription: "Environment used by resources and data sources that do not set `environment_key`. Defaults to `default`. " +
+					"Can also be set with the `FLIPT_DEFAULT_ENVIRONMENT_KEY` environment variable.",
+				Optional: true,
+			},
+			"default_namespace_key": schema.StringAttribute{
+				MarkdownDescription: "Namespace used by resources and data sources that do not set `namespace_key`. Defaults to `default`. " +
+					"Can also be set with the `FLIPT_DEFAULT_NAMESPACE_KEY` environment variable.",
+				Optional: true,
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				MarkdownDescription: "Disable verification of the Flipt server's TLS certificate. Only use this for testing. " +
+					"Can also be set with the `FLIPT_INSECURE_SKIP_VERIFY` environment variable.",
+				Optional: true,
+			},
 		},
 	}
 }
@@ -84,47 +204,97 @@ func (p *FliptProvider) Configure(ctx context.Context, req provider.ConfigureReq
 		return
 	}
 
+	// Settings not in the provider configuration fall back to environment variables
+	endpoint := endpointSetting.resolve(data.Endpoint, &resp.Diagnostics)
+	token := tokenSetting.resolve(data.Token, &resp.Diagnostics)
+	jwt := jwtSetting.resolve(data.JWT, &resp.Diagnostics)
+
 	// Validate endpoint is provided
-	if data.Endpoint.IsNull() || data.Endpoint.ValueString() == "" {
-		resp.Diagnostics.AddError(
+	if !endpoint.isSet() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("endpoint"),
 			"Missing Flipt Endpoint",
-			"The provider requires a Flipt server endpoint URL to be configured.",
+			"The provider requires a Flipt server endpoint URL. Set the endpoint attribute in the provider configuration "+
+				"or the FLIPT_ENDPOINT environment variable.",
 		)
 		return
 	}
 
-	// Use the base endpoint without environment path
-	endpoint := data.Endpoint.ValueString()
-
-	// Get authentication tokens
-	token := ""
-	if !data.Token.IsNull() {
-		token = data.Token.ValueString()
+	// Validate that only one authentication method is provided. A method set
+	// in the provider configuration wins over one from the environment.
+	if token.isSet() && jwt.isSet() {
+		switch {
+		case token.source == sourceConfig && jwt.source == sourceEnv:
+			resp.Diagnostics.AddAttributeWarning(path.Root("token"), "Conflicting Authentication",
+				fmt.Sprintf("Both %s and %s are set. Using token authentication from the provider configuration, "+
+					"the %s environment variable is ignored.", token.describe(), jwt.describe(), jwt.envVar))
+			jwt = resolvedSetting{setting: jwtSetting}
+		case jwt.source == sourceConfig && token.source == sourceEnv:
+			resp.Diagnostics.AddAttributeWarning(path.Root("jwt"), "Conflicting Authentication",
+				fmt.Sprintf("Both %s and %s are set. Using JWT authentication from the provider configuration, "+
+					"the %s environment variable is ignored.", jwt.describe(), token.describe(), token.envVar))
+			token = resolvedSetting{setting: tokenSetting}
+		default:
+			resp.Diagnostics.AddError(
+				"Conflicting Authentication",
+				fmt.Sprintf("Both %s and %s are set. Please provide only one authentication method.", token.describe(), jwt.describe()),
+			)
+			return
+		}
 	}
 
-	jwt := ""
-	if !data.JWT.IsNull() {
-		jwt = data.JWT.ValueString()
+	maxRetries := parseNonNegativeInt(maxRetriesSetting.resolve(int64String(data.MaxRetries), &resp.Diagnostics), defaultMaxRetries, &resp.Diagnostics)
+	retryWaitMin := parseDuration(retryWaitMinSetting.resolve(data.RetryWaitMin, &resp.Diagnostics), defaultRetryWaitMin, &resp.Diagnostics)
+	retryWaitMax := parseDuration(retryWaitMaxSetting.resolve(data.RetryWaitMax, &resp.Diagnostics), defaultRetryWaitMax, &resp.Diagnostics)
+	requestTimeout := parseDuration(requestTimeoutSetting.resolve(data.RequestTimeout, &resp.Diagnostics), defaultRequestTimeout, &resp.Diagnostics)
+
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	// Validate that only one authentication method is provided
-	if token != "" && jwt != "" {
-		resp.Diagnostics.AddError(
-			"Conflicting Authentication",
-			"Both token and jwt are configured. Please provide only one authentication method.",
+	if retryWaitMin > retryWaitMax {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry_wait_min"),
+			"Invalid Retry Wait",
+			fmt.Sprintf("retry_wait_min (%s) must not be greater than retry_wait_max (%s).", retryWaitMin, retryWaitMax),
 		)
 		return
 	}
 
+	transport := newTransport(tlsSettings{
+		caCertFile:         caCertFileSetting.resolve(data.CACertFile, &resp.Diagnostics),
+		caCertPEM:          caCertPEMSetting.resolve(data.CACertPEM, &resp.Diagnostics),
+		clientCert:         clientCertSetting.resolve(data.ClientCert, &resp.Diagnostics),
+		clientKey:          clientKeySetting.resolve(data.ClientKey, &resp.Diagnostics),
+		serverName:         tlsServerNameSetting.resolve(data.TLSServerName, &resp.Diagnostics),
+		insecureSkipVerify: parseBool(insecureSkipVerifySetting.resolve(boolString(data.InsecureSkipVerify), &resp.Diagnostics), &resp.Diagnostics),
+	}, &resp.Diagnostics)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if transport == nil {
+		transport = p.transport
+	}
+
 	// Create HTTP client
-	httpClient := &http.Client{}
+	httpClient := &http.Client{Timeout: requestTimeout, Transport: transport}
 
 	// Create provider configuration
 	config := &FliptProviderConfig{
-		HTTPClient: httpClient,
-		Endpoint:   endpoint,
-		Token:      token,
-		JWT:        jwt,
+		DefaultEnvironmentKey: defaultEnvironmentKeySetting.resolve(data.DefaultEnvironmentKey, &resp.Diagnostics).value,
+		DefaultNamespaceKey:   defaultNamespaceKeySetting.resolve(data.DefaultNamespaceKey, &resp.Diagnostics).value,
+
+		Client: client.New(client.Config{
+			Endpoint:     endpoint.value,
+			Token:        token.value,
+			JWT:          jwt.value,
+			HTTPClient:   httpClient,
+			MaxRetries:   int(maxRetries),
+			RetryWaitMin: retryWaitMin,
+			RetryWaitMax: retryWaitMax,
+		}),
 	}
 
 	resp.DataSourceData = config
@@ -139,6 +309,11 @@ func (p *FliptProvider) Resources(ctx context.Context) []func() resource.Resourc
 		NewVariantResource,
 		NewConstraintResource,
 		NewRuleResource,
+		NewDistributionResource,
+		NewRolloutResource,
+		NewFlagDefaultVariantResource,
+		NewFlagRulesResource,
+		NewSegmentConstraintsResource,
 	}
 }
 
@@ -149,6 +324,12 @@ func (p *FliptProvider) DataSources(ctx context.Context) []func() datasource.Dat
 		NewFlagDataSource,
 		NewSegmentDataSource,
 		NewVariantDataSource,
+		NewNamespacesDataSource,
+		NewEnvironmentsDataSource,
+		NewFlagsDataSource,
+		NewSegmentsDataSource,
+		NewFlagRulesDataSource,
+		NewSegmentConstraintsDataSource,
 	}
 }
 
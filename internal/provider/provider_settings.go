@@ -0,0 +1,185 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// setting is a provider attribute that falls back to an environment variable
+// when it is not set in the provider configuration.
+type setting struct {
+	attr   string
+	envVar string
+	// sensitive keeps the value out of diagnostics.
+	sensitive bool
+}
+
+// Provider settings and the environment variables they fall back to.
+var (
+	endpointSetting       = setting{attr: "endpoint", envVar: "FLIPT_ENDPOINT"}
+	tokenSetting          = setting{attr: "token", envVar: "FLIPT_TOKEN", sensitive: true}
+	jwtSetting            = setting{attr: "jwt", envVar: "FLIPT_JWT", sensitive: true}
+	maxRetriesSetting     = setting{attr: "max_retries", envVar: "FLIPT_MAX_RETRIES"}
+	retryWaitMinSetting   = setting{attr: "retry_wait_min", envVar: "FLIPT_RETRY_WAIT_MIN"}
+	retryWaitMaxSetting   = setting{attr: "retry_wait_max", envVar: "FLIPT_RETRY_WAIT_MAX"}
+	requestTimeoutSetting = setting{attr: "request_timeout", envVar: "FLIPT_REQUEST_TIMEOUT"}
+
+	caCertFileSetting         = setting{attr: "ca_cert_file", envVar: "FLIPT_CA_CERT_FILE"}
+	caCertPEMSetting          = setting{attr: "ca_cert_pem", envVar: "FLIPT_CA_CERT_PEM", sensitive: true}
+	clientCertSetting         = setting{attr: "client_cert", envVar: "FLIPT_CLIENT_CERT", sensitive: true}
+	clientKeySetting          = setting{attr: "client_key", envVar: "FLIPT_CLIENT_KEY", sensitive: true}
+	tlsServerNameSetting      = setting{attr: "tls_server_name", envVar: "FLIPT_TLS_SERVER_NAME"}
+	insecureSkipVerifySetting = setting{attr: "insecure_skip_verify", envVar: "FLIPT_INSECURE_SKIP_VERIFY"}
+
+	defaultEnvironmentKeySetting = setting{attr: "default_environment_key", envVar: "FLIPT_DEFAULT_ENVIRONMENT_KEY"}
+	defaultNamespaceKeySetting   = setting{attr: "default_namespace_key", envVar: "FLIPT_DEFAULT_NAMESPACE_KEY"}
+)
+
+// settingSource describes where the value of a setting was taken from.
+type settingSource int
+
+const (
+	sourceNone settingSource = iota
+	sourceConfig
+	sourceEnv
+)
+
+// resolvedSetting is the value of a setting together with its source.
+type resolvedSetting struct {
+	setting
+	value  string
+	source settingSource
+}
+
+// isSet reports whether the setting has a value from any source.
+func (r resolvedSetting) isSet() bool {
+	return r.source != sourceNone
+}
+
+// describe names the source of the value for use in diagnostics.
+func (r resolvedSetting) describe() string {
+	if r.source == sourceEnv {
+		return fmt.Sprintf("the %s environment variable", r.envVar)
+	}
+	return fmt.Sprintf("the %q provider attribute", r.attr)
+}
+
+// resolve returns the value of the setting. A value in the provider
+// configuration takes precedence over the environment variable. When both are
+// set to different values a warning names the source that was used.
+func (s setting) resolve(configured types.String, diags *diag.Diagnostics) resolvedSetting {
+	envValue := os.Getenv(s.envVar)
+
+	if configured.IsNull() || configured.IsUnknown() || configured.ValueString() == "" {
+		if envValue == "" {
+			return resolvedSetting{setting: s}
+		}
+		return resolvedSetting{setting: s, value: envValue, source: sourceEnv}
+	}
+
+	value := configured.ValueString()
+	if envValue != "" && envValue != value {
+		detail := fmt.Sprintf("Both the %q provider attribute and the %s environment variable are set", s.attr, s.envVar)
+		if !s.sensitive {
+			detail += fmt.Sprintf(" (%q and %q)", value, envValue)
+		}
+		diags.AddAttributeWarning(path.Root(s.attr), "Conflicting Provider Configuration",
+			detail+fmt.Sprintf(". Using the value from the provider configuration, the %s environment variable is ignored.", s.envVar))
+	}
+
+	return resolvedSetting{setting: s, value: value, source: sourceConfig}
+}
+
+// int64String converts an optional number attribute to the string form used
+// by resolve.
+func int64String(value types.Int64) types.String {
+	if value.IsNull() {
+		return types.StringNull()
+	}
+	if value.IsUnknown() {
+		return types.StringUnknown()
+	}
+	return types.StringValue(strconv.FormatInt(value.ValueInt64(), 10))
+}
+
+// boolString converts an optional bool attribute to the string form used by
+// resolve.
+func boolString(value types.Bool) types.String {
+	if value.IsNull() {
+		return types.StringNull()
+	}
+	if value.IsUnknown() {
+		return types.StringUnknown()
+	}
+	return types.StringValue(strconv.FormatBool(value.ValueBool()))
+}
+
+// parseBool parses a boolean setting, returning false when it is not set.
+// Invalid values are reported naming their source.
+func parseBool(r resolvedSetting, diags *diag.Diagnostics) bool {
+	if !r.isSet() {
+		return false
+	}
+
+	b, err := strconv.ParseBool(r.value)
+	if err != nil {
+		diags.AddAttributeError(path.Root(r.attr), "Invalid Boolean",
+			fmt.Sprintf("Unable to parse %s as a boolean, got error: %s", r.describe(), err))
+		return false
+	}
+
+	return b
+}
+
+// parseNonNegativeInt parses an integer setting, returning def when it is not
+// set. Invalid and negative values are reported naming their source.
+func parseNonNegativeInt(r resolvedSetting, def int64, diags *diag.Diagnostics) int64 {
+	if !r.isSet() {
+		return def
+	}
+
+	n, err := strconv.ParseInt(r.value, 10, 64)
+	if err != nil {
+		diags.AddAttributeError(path.Root(r.attr), "Invalid Number",
+			fmt.Sprintf("Unable to parse %s as a number, got error: %s", r.describe(), err))
+		return def
+	}
+	if n < 0 {
+		diags.AddAttributeError(path.Root(r.attr), "Invalid Number",
+			fmt.Sprintf("%s must not be negative, got: %d", r.describe(), n))
+		return def
+	}
+
+	return n
+}
+
+// parseDuration parses a duration setting, returning def when it is not set.
+// Invalid and negative durations are reported naming their source.
+func parseDuration(r resolvedSetting, def time.Duration, diags *diag.Diagnostics) time.Duration {
+	if !r.isSet() {
+		return def
+	}
+
+	d, err := time.ParseDuration(r.value)
+	if err != nil {
+		diags.AddAttributeError(path.Root(r.attr), "Invalid Duration",
+			fmt.Sprintf("Unable to parse %s as a duration such as \"30s\" or \"1m\", got error: %s", r.describe(), err))
+		return def
+	}
+	if d < 0 {
+		diags.AddAttributeError(path.Root(r.attr), "Invalid Duration",
+			fmt.Sprintf("%s must not be negative, got: %s", r.describe(), r.value))
+		return def
+	}
+
+	return d
+}
@@ -0,0 +1,227 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// clearFliptEnv unsets all environment variables read by the provider for
+// the duration of the test.
+func clearFliptEnv(t *testing.T) {
+	t.Helper()
+
+	for _, s := range []setting{endpointSetting, tokenSetting, jwtSetting, maxRetriesSetting, retryWaitMinSetting, retryWaitMaxSetting, requestTimeoutSetting,
+		caCertFileSetting, caCertPEMSetting, clientCertSetting, clientKeySetting, tlsServerNameSetting, insecureSkipVerifySetting,
+		defaultEnvironmentKeySetting, defaultNamespaceKeySetting} {
+		t.Setenv(s.envVar, "")
+	}
+}
+
+func TestSettingResolve(t *testing.T) {
+	tests := []struct {
+		name           string
+		configured     types.String
+		env            string
+		expectedValue  string
+		expectedSource settingSource
+		expectWarning  bool
+	}{
+		{name: "unset", configured: types.StringNull(), expectedSource: sourceNone},
+		{name: "config only", configured: types.StringValue("http://config"), expectedValue: "http://config", expectedSource: sourceConfig},
+		{name: "environment only", configured: types.StringNull(), env: "http://env", expectedValue: "http://env", expectedSource: sourceEnv},
+		{name: "unknown falls back to environment", configured: types.StringUnknown(), env: "http://env", expectedValue: "http://env", expectedSource: sourceEnv},
+		{name: "empty falls back to environment", configured: types.StringValue(""), env: "http://env", expectedValue: "http://env", expectedSource: sourceEnv},
+		{name: "config wins over environment", configured: types.StringValue("http://config"), env: "http://env", expectedValue: "http://config", expectedSource: sourceConfig, expectWarning: true},
+		{name: "same value in both", configured: types.StringValue("http://same"), env: "http://same", expectedValue: "http://same", expectedSource: sourceConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("FLIPT_ENDPOINT", tt.env)
+
+			var diags diag.Diagnostics
+			got := endpointSetting.resolve(tt.configured, &diags)
+
+			if got.value != tt.expectedValue || got.source != tt.expectedSource {
+				t.Errorf("Expected %q from source %d, got %q from source %d", tt.expectedValue, tt.expectedSource, got.value, got.source)
+			}
+			if hasWarning := diags.WarningsCount() > 0; hasWarning != tt.expectWarning {
+				t.Errorf("Expected warning %t, got %v", tt.expectWarning, diags)
+			}
+			if tt.expectWarning && !strings.Contains(diags[0].Detail(), "FLIPT_ENDPOINT environment variable is ignored") {
+				t.Errorf("Expected warning to name the ignored source, got %q", diags[0].Detail())
+			}
+		})
+	}
+}
+
+func TestSettingResolveSensitive(t *testing.T) {
+	t.Setenv("FLIPT_TOKEN", "env-secret")
+
+	var diags diag.Diagnostics
+	got := tokenSetting.resolve(types.StringValue("config-secret"), &diags)
+
+	if got.value != "config-secret" {
+		t.Errorf("Expected configured token, got %q", got.value)
+	}
+	if diags.WarningsCount() != 1 {
+		t.Fatalf("Expected a warning, got %v", diags)
+	}
+	if detail := diags[0].Detail(); strings.Contains(detail, "secret") {
+		t.Errorf("Expected sensitive values to be left out of the warning, got %q", detail)
+	}
+}
+
+func TestProviderConfigure_Environment(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           map[string]tftypes.Value
+		env              map[string]string
+		expectedEndpoint string
+		expectedToken    string
+		expectedJWT      string
+		expectedTimeout  time.Duration
+		expectWarning    string
+		expectErr        string
+	}{
+		{
+			name:             "endpoint from environment",
+			env:              map[string]string{"FLIPT_ENDPOINT": "http://env:8080"},
+			expectedEndpoint: "http://env:8080",
+			expectedTimeout:  defaultRequestTimeout,
+		},
+		{
+			name:      "endpoint missing",
+			expectErr: "Missing Flipt Endpoint",
+		},
+		{
+			name:             "config endpoint wins",
+			config:           map[string]tftypes.Value{"endpoint": tftypes.NewValue(tftypes.String, "http://config:8080")},
+			env:              map[string]string{"FLIPT_ENDPOINT": "http://env:8080"},
+			expectedEndpoint: "http://config:8080",
+			expectedTimeout:  defaultRequestTimeout,
+			expectWarning:    "Conflicting Provider Configuration",
+		},
+		{
+			name:             "token from environment",
+			env:              map[string]string{"FLIPT_ENDPOINT": "http://env:8080", "FLIPT_TOKEN": "env-token"},
+			expectedEndpoint: "http://env:8080",
+			expectedToken:    "env-token",
+			expectedTimeout:  defaultRequestTimeout,
+		},
+		{
+			name:             "jwt from environment",
+			env:              map[string]string{"FLIPT_ENDPOINT": "http://env:8080", "FLIPT_JWT": "env.jwt"},
+			expectedEndpoint: "http://env:8080",
+			expectedJWT:      "env.jwt",
+			expectedTimeout:  defaultRequestTimeout,
+		},
+		{
+			name:             "configured token wins over environment jwt",
+			config:           map[string]tftypes.Value{"token": tftypes.NewValue(tftypes.String, "config-token")},
+			env:              map[string]string{"FLIPT_ENDPOINT": "http://env:8080", "FLIPT_JWT": "env.jwt"},
+			expectedEndpoint: "http://env:8080",
+			expectedToken:    "config-token",
+			expectedTimeout:  defaultRequestTimeout,
+			expectWarning:    "Conflicting Authentication",
+		},
+		{
+			name:             "configured jwt wins over environment token",
+			config:           map[string]tftypes.Value{"jwt": tftypes.NewValue(tftypes.String, "config.jwt")},
+			env:              map[string]string{"FLIPT_ENDPOINT": "http://env:8080", "FLIPT_TOKEN": "env-token"},
+			expectedEndpoint: "http://env:8080",
+			expectedJWT:      "config.jwt",
+			expectedTimeout:  defaultRequestTimeout,
+			expectWarning:    "Conflicting Authentication",
+		},
+		{
+			name:      "token and jwt both from environment",
+			env:       map[string]string{"FLIPT_ENDPOINT": "http://env:8080", "FLIPT_TOKEN": "env-token", "FLIPT_JWT": "env.jwt"},
+			expectErr: "Conflicting Authentication",
+		},
+		{
+			name: "token and jwt both configured",
+			config: map[string]tftypes.Value{
+				"endpoint": tftypes.NewValue(tftypes.String, "http://config:8080"),
+				"token":    tftypes.NewValue(tftypes.String, "config-token"),
+				"jwt":      tftypes.NewValue(tftypes.String, "config.jwt"),
+			},
+			expectErr: "Conflicting Authentication",
+		},
+		{
+			name:             "request timeout from environment",
+			env:              map[string]string{"FLIPT_ENDPOINT": "http://env:8080", "FLIPT_REQUEST_TIMEOUT": "5s"},
+			expectedEndpoint: "http://env:8080",
+			expectedTimeout:  5 * time.Second,
+		},
+		{
+			name:             "configured request timeout wins",
+			config:           map[string]tftypes.Value{"request_timeout": tftypes.NewValue(tftypes.String, "10s")},
+			env:              map[string]string{"FLIPT_ENDPOINT": "http://env:8080", "FLIPT_REQUEST_TIMEOUT": "5s"},
+			expectedEndpoint: "http://env:8080",
+			expectedTimeout:  10 * time.Second,
+			expectWarning:    "Conflicting Provider Configuration",
+		},
+		{
+			name:      "invalid max retries in environment",
+			env:       map[string]string{"FLIPT_ENDPOINT": "http://env:8080", "FLIPT_MAX_RETRIES": "many"},
+			expectErr: "Invalid Number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearFliptEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			resp := configureProvider(t, tt.config)
+
+			if tt.expectErr != "" {
+				if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != tt.expectErr {
+					t.Fatalf("Expected %q error, got %v", tt.expectErr, resp.Diagnostics)
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Unexpected error: %v", resp.Diagnostics)
+			}
+
+			warnings := resp.Diagnostics.Warnings()
+			if tt.expectWarning == "" && len(warnings) > 0 {
+				t.Errorf("Unexpected warnings: %v", warnings)
+			}
+			if tt.expectWarning != "" && (len(warnings) != 1 || warnings[0].Summary() != tt.expectWarning) {
+				t.Errorf("Expected %q warning, got %v", tt.expectWarning, warnings)
+			}
+
+			config := resp.ResourceData.(*FliptProviderConfig)
+			if config.Client.Endpoint() != tt.expectedEndpoint {
+				t.Errorf("Expected endpoint %q, got %q", tt.expectedEndpoint, config.Client.Endpoint())
+			}
+
+			req := sentRequest(t, tt.config)
+			expectedHeader := ""
+			if tt.expectedToken != "" {
+				expectedHeader = "Bearer " + tt.expectedToken
+			} else if tt.expectedJWT != "" {
+				expectedHeader = "JWT " + tt.expectedJWT
+			}
+			if authHeader := req.Header.Get("Authorization"); authHeader != expectedHeader {
+				t.Errorf("Expected Authorization header %q, got %q", expectedHeader, authHeader)
+			}
+			if timeout := requestTimeout(t, req); timeout != tt.expectedTimeout {
+				t.Errorf("Expected request timeout %s, got %s", tt.expectedTimeout, timeout)
+			}
+		})
+	}
+}
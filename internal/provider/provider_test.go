@@ -6,14 +6,20 @@ package provider
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
@@ -69,6 +75,12 @@ func TestProviderDataSources(t *testing.T) {
 		"flipt_flag",
 		"flipt_segment",
 		"flipt_variant",
+		"flipt_environments",
+		"flipt_namespaces",
+		"flipt_flags",
+		"flipt_segments",
+		"flipt_flag_rules",
+		"flipt_segment_constraints",
 	}
 
 	for _, dsName := range expectedDataSources {
@@ -89,6 +101,11 @@ func TestProviderResources(t *testing.T) {
 		"flipt_variant",
 		"flipt_constraint",
 		"flipt_rule",
+		"flipt_distribution",
+		"flipt_rollout",
+		"flipt_flag_default_variant",
+		"flipt_flag_rules",
+		"flipt_segment_constraints",
 	}
 
 	for _, resourceName := range expectedResources {
@@ -264,41 +281,39 @@ func TestMain(m *testing.M) {
 func TestFliptProviderConfig_AddAuthHeader(t *testing.T) {
 	tests := []struct {
 		name           string
-		config         *FliptProviderConfig
+		values         map[string]tftypes.Value
 		expectedHeader string
 		expectAuth     bool
 	}{
 		{
 			name: "Bearer token authentication",
-			config: &FliptProviderConfig{
-				Token: "test-token",
+			values: map[string]tftypes.Value{
+				"token": tftypes.NewValue(tftypes.String, "test-token"),
 			},
 			expectedHeader: "Bearer test-token",
 			expectAuth:     true,
 		},
 		{
 			name: "JWT authentication",
-			config: &FliptProviderConfig{
-				JWT: "test.jwt.token",
+			values: map[string]tftypes.Value{
+				"jwt": tftypes.NewValue(tftypes.String, "test.jwt.token"),
 			},
 			expectedHeader: "JWT test.jwt.token",
 			expectAuth:     true,
 		},
 		{
 			name:       "No authentication",
-			config:     &FliptProviderConfig{},
+			values:     map[string]tftypes.Value{},
 			expectAuth: false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			req, err := http.NewRequest("GET", "http://example.com", nil)
-			if err != nil {
-				t.Fatalf("Failed to create request: %v", err)
-			}
+			clearFliptEnv(t)
+			tt.values["endpoint"] = tftypes.NewValue(tftypes.String, "http://localhost:8080")
 
-			tt.config.AddAuthHeader(req)
+			req := sentRequest(t, tt.values)
 
 			authHeader := req.Header.Get("Authorization")
 			if tt.expectAuth {
@@ -316,3 +331,199 @@ func TestFliptProviderConfig_AddAuthHeader(t *testing.T) {
 		})
 	}
 }
+
+// configureProvider calls Configure on a new provider with the given
+// attribute values. Attributes not present in values are null.
+func configureProvider(t *testing.T, values map[string]tftypes.Value) *provider.ConfigureResponse {
+	t.Helper()
+	return configureProviderWith(t, &FliptProvider{version: "test"}, values)
+}
+
+// configureProviderWith calls Configure on p with the given attribute values.
+func configureProviderWith(t *testing.T, p *FliptProvider, values map[string]tftypes.Value) *provider.ConfigureResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	schemaResp := &provider.SchemaResponse{}
+	p.Schema(ctx, provider.SchemaRequest{}, schemaResp)
+
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	attrs := map[string]tftypes.Value{}
+	for name, typ := range objectType.AttributeTypes {
+		attrs[name] = tftypes.NewValue(typ, nil)
+		if v, ok := values[name]; ok {
+			attrs[name] = v
+		}
+	}
+
+	req := provider.ConfigureRequest{
+		Config: tfsdk.Config{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(objectType, attrs),
+		},
+	}
+	resp := &provider.ConfigureResponse{}
+	p.Configure(ctx, req, resp)
+
+	return resp
+}
+
+// roundTripFunc is an http.RoundTripper calling a function.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// sentRequest configures a provider with the given attribute values and
+// returns the request its client sends to list the environments. The
+// request is captured by the transport of the provider and never sent.
+func sentRequest(t *testing.T, values map[string]tftypes.Value) *http.Request {
+	t.Helper()
+
+	var sent *http.Request
+	p := &FliptProvider{
+		version: "test",
+		transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent = req
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"environments":[]}`)),
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	resp := configureProviderWith(t, p, values)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Unexpected error: %v", resp.Diagnostics)
+	}
+
+	config := resp.ResourceData.(*FliptProviderConfig)
+	if _, err := config.Client.ListEnvironments(context.Background()); err != nil {
+		t.Fatalf("Unable to list environments: %v", err)
+	}
+	return sent
+}
+
+// requestTimeout returns the time left until the deadline the client set on
+// a sent request, which is the configured request timeout.
+func requestTimeout(t *testing.T, req *http.Request) time.Duration {
+	t.Helper()
+
+	deadline, ok := req.Context().Deadline()
+	if !ok {
+		t.Fatal("Expected the request to have a deadline")
+	}
+	return time.Until(deadline).Round(time.Second)
+}
+
+func TestProviderConfigure_Retries(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    map[string]tftypes.Value
+		expectErr string
+		timeout   time.Duration
+	}{
+		{
+			name:    "defaults",
+			timeout: defaultRequestTimeout,
+		},
+		{
+			name: "custom settings",
+			values: map[string]tftypes.Value{
+				"max_retries":     tftypes.NewValue(tftypes.Number, 5),
+				"retry_wait_min":  tftypes.NewValue(tftypes.String, "100ms"),
+				"retry_wait_max":  tftypes.NewValue(tftypes.String, "2s"),
+				"request_timeout": tftypes.NewValue(tftypes.String, "10s"),
+			},
+			timeout: 10 * time.Second,
+		},
+		{
+			name: "negative max retries",
+			values: map[string]tftypes.Value{
+				"max_retries": tftypes.NewValue(tftypes.Number, -1),
+			},
+			expectErr: "Invalid Number",
+		},
+		{
+			name: "invalid duration",
+			values: map[string]tftypes.Value{
+				"request_timeout": tftypes.NewValue(tftypes.String, "soon"),
+			},
+			expectErr: "Invalid Duration",
+		},
+		{
+			name: "negative duration",
+			values: map[string]tftypes.Value{
+				"retry_wait_min": tftypes.NewValue(tftypes.String, "-1s"),
+			},
+			expectErr: "Invalid Duration",
+		},
+		{
+			name: "min greater than max",
+			values: map[string]tftypes.Value{
+				"retry_wait_min": tftypes.NewValue(tftypes.String, "1m"),
+				"retry_wait_max": tftypes.NewValue(tftypes.String, "1s"),
+			},
+			expectErr: "Invalid Retry Wait",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearFliptEnv(t)
+
+			values := map[string]tftypes.Value{"endpoint": tftypes.NewValue(tftypes.String, "http://localhost:8080")}
+			for k, v := range tt.values {
+				values[k] = v
+			}
+
+			if tt.expectErr != "" {
+				resp := configureProvider(t, values)
+				if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != tt.expectErr {
+					t.Fatalf("Expected %q error, got %v", tt.expectErr, resp.Diagnostics)
+				}
+				return
+			}
+
+			req := sentRequest(t, values)
+			if timeout := requestTimeout(t, req); timeout != tt.timeout {
+				t.Errorf("Expected request timeout %s, got %s", tt.timeout, timeout)
+			}
+		})
+	}
+}
+
+func TestProviderConfigure_RetriesFlakyServer(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"environments":[{"key":"default","name":"Default","default":true}]}`))
+	}))
+	defer server.Close()
+
+	clearFliptEnv(t)
+	resp := configureProvider(t, map[string]tftypes.Value{
+		"endpoint":       tftypes.NewValue(tftypes.String, server.URL),
+		"max_retries":    tftypes.NewValue(tftypes.Number, 2),
+		"retry_wait_min": tftypes.NewValue(tftypes.String, "1ms"),
+		"retry_wait_max": tftypes.NewValue(tftypes.String, "1ms"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Unexpected error: %v", resp.Diagnostics)
+	}
+
+	config := resp.ResourceData.(*FliptProviderConfig)
+	if _, err := config.Client.GetEnvironment(context.Background(), "default"); err != nil {
+		t.Fatalf("Expected request to succeed after retries, got %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+}
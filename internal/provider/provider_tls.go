@@ -0,0 +1,149 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// tlsSettings holds the resolved TLS settings of the provider.
+type tlsSettings struct {
+	caCertFile         resolvedSetting
+	caCertPEM          resolvedSetting
+	clientCert         resolvedSetting
+	clientKey          resolvedSetting
+	serverName         resolvedSetting
+	insecureSkipVerify bool
+}
+
+// configured reports whether any TLS setting differs from the defaults.
+func (s tlsSettings) configured() bool {
+	return s.caCertFile.isSet() || s.caCertPEM.isSet() || s.clientCert.isSet() || s.clientKey.isSet() ||
+		s.serverName.isSet() || s.insecureSkipVerify
+}
+
+// newTransport returns an HTTP transport using the TLS settings, or nil when
+// none are configured and the default transport can be used.
+func newTransport(s tlsSettings, diags *diag.Diagnostics) http.RoundTripper {
+	if !s.configured() {
+		return nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         s.serverName.value,
+		InsecureSkipVerify: s.insecureSkipVerify, //nolint:gosec // explicitly requested by the user
+	}
+
+	if s.caCertFile.isSet() && s.caCertPEM.isSet() {
+		diags.AddAttributeError(path.Root("ca_cert_pem"), "Conflicting CA Certificate",
+			fmt.Sprintf("Both %s and %s are set. Please provide the CA certificate only once.", s.caCertFile.describe(), s.caCertPEM.describe()))
+		return nil
+	}
+
+	if s.caCertFile.isSet() || s.caCertPEM.isSet() {
+		pool := caCertPool(s, diags)
+		if diags.HasError() {
+			return nil
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if s.clientCert.isSet() != s.clientKey.isSet() {
+		diags.AddAttributeError(path.Root("client_cert"), "Incomplete Client Certificate",
+			"Both client_cert and client_key must be set to authenticate with a client certificate.")
+		return nil
+	}
+
+	if s.clientCert.isSet() {
+		cert := clientCertificate(s, diags)
+		if diags.HasError() {
+			return nil
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if s.insecureSkipVerify {
+		diags.AddAttributeWarning(path.Root("insecure_skip_verify"), "Insecure TLS Configuration",
+			"TLS certificate verification of the Flipt server is disabled. Only use this for testing.")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return transport
+}
+
+// caCertPool returns the system certificate pool extended by the configured
+// CA certificates.
+func caCertPool(s tlsSettings, diags *diag.Diagnostics) *x509.CertPool {
+	source := s.caCertPEM
+	pemData := []byte(s.caCertPEM.value)
+	if s.caCertFile.isSet() {
+		source = s.caCertFile
+		data, err := os.ReadFile(s.caCertFile.value)
+		if err != nil {
+			diags.AddAttributeError(path.Root(source.attr), "Invalid CA Certificate",
+				fmt.Sprintf("Unable to read the CA certificate file from %s, got error: %s", source.describe(), err))
+			return nil
+		}
+		pemData = data
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pemData) {
+		diags.AddAttributeError(path.Root(source.attr), "Invalid CA Certificate",
+			fmt.Sprintf("No PEM encoded certificates found in %s.", source.describe()))
+		return nil
+	}
+
+	return pool
+}
+
+// clientCertificate loads the client certificate and key, each given either
+// as PEM encoded content or as the path of a file containing it.
+func clientCertificate(s tlsSettings, diags *diag.Diagnostics) tls.Certificate {
+	certPEM := pemOrFile(s.clientCert, diags)
+	keyPEM := pemOrFile(s.clientKey, diags)
+	if diags.HasError() {
+		return tls.Certificate{}
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		diags.AddAttributeError(path.Root("client_cert"), "Invalid Client Certificate",
+			fmt.Sprintf("Unable to load the client certificate from %s and %s, got error: %s", s.clientCert.describe(), s.clientKey.describe(), err))
+		return tls.Certificate{}
+	}
+
+	return cert
+}
+
+// pemOrFile returns the value of a setting when it holds PEM encoded content
+// and otherwise reads the file it points to.
+func pemOrFile(r resolvedSetting, diags *diag.Diagnostics) []byte {
+	if strings.Contains(r.value, "-----BEGIN") {
+		return []byte(r.value)
+	}
+
+	data, err := os.ReadFile(r.value)
+	if err != nil {
+		diags.AddAttributeError(path.Root(r.attr), "Invalid Client Certificate",
+			fmt.Sprintf("Unable to read the file given by %s, got error: %s", r.describe(), err))
+		return nil
+	}
+
+	return data
+}
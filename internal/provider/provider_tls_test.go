@@ -0,0 +1,304 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// testCertificate is a self-signed certificate usable as CA and as client
+// certificate.
+type testCertificate struct {
+	cert    *x509.Certificate
+	certPEM string
+	keyPEM  string
+}
+
+func newTestCertificate(t *testing.T, commonName string) testCertificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unable to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Unable to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Unable to parse certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Unable to marshal key: %v", err)
+	}
+
+	return testCertificate{
+		cert:    cert,
+		certPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		keyPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+	}
+}
+
+// newTLSFlipt starts a TLS server answering the environments endpoint. When
+// clientCA is set the server requires a client certificate signed by it.
+func newTLSFlipt(t *testing.T, clientCA *x509.Certificate) (*httptest.Server, string) {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"environments":[{"key":"default","name":"Default","default":true}]}`))
+	}))
+	if clientCA != nil {
+		pool := x509.NewCertPool()
+		pool.AddCert(clientCA)
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	return server, caPEM
+}
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatalf("Unable to write %s: %v", file, err)
+	}
+	return file
+}
+
+func TestProviderConfigure_TLS(t *testing.T) {
+	clientCert := newTestCertificate(t, "terraform")
+	otherCert := newTestCertificate(t, "someone-else")
+
+	server, caPEM := newTLSFlipt(t, nil)
+	mtlsServer, mtlsCAPEM := newTLSFlipt(t, clientCert.cert)
+
+	str := func(v string) tftypes.Value { return tftypes.NewValue(tftypes.String, v) }
+
+	tests := []struct {
+		name         string
+		endpoint     string
+		config       map[string]tftypes.Value
+		env          map[string]string
+		expectErr    string
+		expectReqErr string
+	}{
+		{
+			name:         "untrusted server certificate",
+			endpoint:     server.URL,
+			expectReqErr: "certificate",
+		},
+		{
+			name:     "CA from PEM",
+			endpoint: server.URL,
+			config:   map[string]tftypes.Value{"ca_cert_pem": str(caPEM)},
+		},
+		{
+			name:     "CA from file",
+			endpoint: server.URL,
+			config:   map[string]tftypes.Value{"ca_cert_file": str(writeTestFile(t, "ca.pem", caPEM))},
+		},
+		{
+			name:     "CA from environment",
+			endpoint: server.URL,
+			env:      map[string]string{"FLIPT_CA_CERT_PEM": caPEM},
+		},
+		{
+			name:     "insecure skip verify",
+			endpoint: server.URL,
+			config:   map[string]tftypes.Value{"insecure_skip_verify": tftypes.NewValue(tftypes.Bool, true)},
+		},
+		{
+			name:     "insecure skip verify from environment",
+			endpoint: server.URL,
+			env:      map[string]string{"FLIPT_INSECURE_SKIP_VERIFY": "true"},
+		},
+		{
+			name:     "matching server name",
+			endpoint: server.URL,
+			config:   map[string]tftypes.Value{"ca_cert_pem": str(caPEM), "tls_server_name": str("example.com")},
+		},
+		{
+			name:         "mismatching server name",
+			endpoint:     server.URL,
+			config:       map[string]tftypes.Value{"ca_cert_pem": str(caPEM), "tls_server_name": str("flipt.internal")},
+			expectReqErr: "flipt.internal",
+		},
+		{
+			name:         "mTLS without client certificate",
+			endpoint:     mtlsServer.URL,
+			config:       map[string]tftypes.Value{"ca_cert_pem": str(mtlsCAPEM)},
+			expectReqErr: "certificate",
+		},
+		{
+			name:     "mTLS with client certificate from PEM",
+			endpoint: mtlsServer.URL,
+			config: map[string]tftypes.Value{
+				"ca_cert_pem": str(mtlsCAPEM),
+				"client_cert": str(clientCert.certPEM),
+				"client_key":  str(clientCert.keyPEM),
+			},
+		},
+		{
+			name:     "mTLS with client certificate from files",
+			endpoint: mtlsServer.URL,
+			config: map[string]tftypes.Value{
+				"ca_cert_pem": str(mtlsCAPEM),
+				"client_cert": str(writeTestFile(t, "client.pem", clientCert.certPEM)),
+				"client_key":  str(writeTestFile(t, "client-key.pem", clientCert.keyPEM)),
+			},
+		},
+		{
+			name:     "mTLS with client certificate from environment",
+			endpoint: mtlsServer.URL,
+			config:   map[string]tftypes.Value{"ca_cert_pem": str(mtlsCAPEM)},
+			env:      map[string]string{"FLIPT_CLIENT_CERT": clientCert.certPEM, "FLIPT_CLIENT_KEY": clientCert.keyPEM},
+		},
+		{
+			name:     "mTLS with untrusted client certificate",
+			endpoint: mtlsServer.URL,
+			config: map[string]tftypes.Value{
+				"ca_cert_pem": str(mtlsCAPEM),
+				"client_cert": str(otherCert.certPEM),
+				"client_key":  str(otherCert.keyPEM),
+			},
+			expectReqErr: "certificate",
+		},
+		{
+			name:     "CA file and PEM",
+			endpoint: server.URL,
+			config: map[string]tftypes.Value{
+				"ca_cert_pem":  str(caPEM),
+				"ca_cert_file": str(writeTestFile(t, "ca.pem", caPEM)),
+			},
+			expectErr: "Conflicting CA Certificate",
+		},
+		{
+			name:      "missing CA file",
+			endpoint:  server.URL,
+			config:    map[string]tftypes.Value{"ca_cert_file": str(filepath.Join(t.TempDir(), "missing.pem"))},
+			expectErr: "Invalid CA Certificate",
+		},
+		{
+			name:      "CA without certificates",
+			endpoint:  server.URL,
+			config:    map[string]tftypes.Value{"ca_cert_pem": str("not a certificate")},
+			expectErr: "Invalid CA Certificate",
+		},
+		{
+			name:      "client certificate without key",
+			endpoint:  mtlsServer.URL,
+			config:    map[string]tftypes.Value{"client_cert": str(clientCert.certPEM)},
+			expectErr: "Incomplete Client Certificate",
+		},
+		{
+			name:     "client certificate with wrong key",
+			endpoint: mtlsServer.URL,
+			config: map[string]tftypes.Value{
+				"client_cert": str(clientCert.certPEM),
+				"client_key":  str(otherCert.keyPEM),
+			},
+			expectErr: "Invalid Client Certificate",
+		},
+		{
+			name:      "invalid insecure skip verify in environment",
+			endpoint:  server.URL,
+			env:       map[string]string{"FLIPT_INSECURE_SKIP_VERIFY": "maybe"},
+			expectErr: "Invalid Boolean",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearFliptEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			// Handshake failures with the mTLS server are connection errors
+			// that would otherwise be retried.
+			values := map[string]tftypes.Value{
+				"endpoint":    str(tt.endpoint),
+				"max_retries": tftypes.NewValue(tftypes.Number, 0),
+			}
+			for k, v := range tt.config {
+				values[k] = v
+			}
+
+			resp := configureProvider(t, values)
+
+			if tt.expectErr != "" {
+				if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != tt.expectErr {
+					t.Fatalf("Expected %q error, got %v", tt.expectErr, resp.Diagnostics)
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Unexpected error: %v", resp.Diagnostics)
+			}
+
+			config := resp.ResourceData.(*FliptProviderConfig)
+			_, err := config.Client.ListEnvironments(context.Background())
+
+			if tt.expectReqErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectReqErr) {
+					t.Fatalf("Expected request to fail with %q, got %v", tt.expectReqErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected request error: %v", err)
+			}
+		})
+	}
+}
+
+func TestProviderConfigure_InsecureSkipVerifyWarning(t *testing.T) {
+	clearFliptEnv(t)
+
+	resp := configureProvider(t, map[string]tftypes.Value{
+		"endpoint":             tftypes.NewValue(tftypes.String, "https://localhost:8443"),
+		"insecure_skip_verify": tftypes.NewValue(tftypes.Bool, true),
+	})
+
+	warnings := resp.Diagnostics.Warnings()
+	if len(warnings) != 1 || warnings[0].Summary() != "Insecure TLS Configuration" {
+		t.Errorf("Expected insecure TLS warning, got %v", resp.Diagnostics)
+	}
+}
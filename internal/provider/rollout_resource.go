@@ -0,0 +1,608 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
+)
+
+var _ resource.Resource = &RolloutResource{}
+var _ resource.ResourceWithImportState = &RolloutResource{}
+var _ resource.ResourceWithModifyPlan = &RolloutResource{}
+var _ resource.ResourceWithValidateConfig = &RolloutResource{}
+
+const (
+	thresholdRolloutType = "THRESHOLD_ROLLOUT_TYPE"
+	segmentRolloutType   = "SEGMENT_ROLLOUT_TYPE"
+)
+
+// errNotBooleanFlag is returned from the modify function when rollouts are
+// added to a flag that does not evaluate them.
+var errNotBooleanFlag = errors.New("rollouts are only supported on boolean flags")
+
+func NewRolloutResource() resource.Resource {
+	return &RolloutResource{}
+}
+
+type RolloutResource struct {
+	config *FliptProviderConfig
+}
+
+type RolloutResourceModel struct {
+	NamespaceKey    types.String  `tfsdk:"namespace_key"`
+	EnvironmentKey  types.String  `tfsdk:"environment_key"`
+	FlagKey         types.String  `tfsdk:"flag_key"`
+	ID              types.String  `tfsdk:"id"`
+	Rank            types.Int64   `tfsdk:"rank"`
+	Type            types.String  `tfsdk:"type"`
+	Description     types.String  `tfsdk:"description"`
+	Value           types.Bool    `tfsdk:"value"`
+	Percentage      types.Float64 `tfsdk:"percentage"`
+	SegmentKeys     types.List    `tfsdk:"segment_keys"`
+	SegmentOperator types.String  `tfsdk:"segment_operator"`
+}
+
+func (r *RolloutResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rollout"
+}
+
+func (r *RolloutResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Flipt rollout resource (belongs to a boolean flag)",
+
+		Attributes: map[string]schema.Attribute{
+			"namespace_key": schema.StringAttribute{
+				MarkdownDescription: "Namespace key where the flag belongs. Defaults to the provider's `default_namespace_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"environment_key": schema.StringAttribute{
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"flag_key": schema.StringAttribute{
+				MarkdownDescription: "Key of the boolean flag that this rollout belongs to",
+				Required:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Identifier of the rollout, composed of the flag key and its current rank",
+				Computed:            true,
+			},
+			"rank": schema.Int64Attribute{
+				MarkdownDescription: "Position of the rollout in the flag's rollouts (rollouts with lower ranks are evaluated first). Must not be greater than the number of other rollouts. Defaults to the end of the rollouts",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"type": schema.StringAttribute{
+				MarkdownDescription: "Type of the rollout (THRESHOLD_ROLLOUT_TYPE or SEGMENT_ROLLOUT_TYPE)",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(thresholdRolloutType, segmentRolloutType),
+				},
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Description of the rollout",
+				Optional:            true,
+			},
+			"value": schema.BoolAttribute{
+				MarkdownDescription: "Value returned for the entities matched by the rollout",
+				Required:            true,
+			},
+			"percentage": schema.Float64Attribute{
+				MarkdownDescription: "Percentage of entities matched by a threshold rollout, between 0 and 100",
+				Optional:            true,
+			},
+			"segment_keys": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "List of segment keys matched by a segment rollout",
+				Optional:            true,
+				Validators: []validator.List{
+					segmentKeysValidator(),
+				},
+			},
+			"segment_operator": schema.StringAttribute{
+				MarkdownDescription: "Operator for combining the segments of a segment rollout (OR_SEGMENT_OPERATOR or AND_SEGMENT_OPERATOR). AND_SEGMENT_OPERATOR needs at least two segment keys",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					segmentOperatorValidator(),
+				},
+			},
+		},
+	}
+}
+
+func (r *RolloutResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerConfig, ok := req.ProviderData.(*FliptProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *FliptProviderConfig, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.config = providerConfig
+}
+
+func (r *RolloutResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data RolloutResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.Type.IsUnknown() {
+		return
+	}
+
+	switch data.Type.ValueString() {
+	case thresholdRolloutType:
+		if data.Percentage.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("percentage"), "Missing Percentage",
+				"A threshold rollout requires a percentage.")
+		} else if !data.Percentage.IsUnknown() {
+			if v := data.Percentage.ValueFloat64(); v < 0 || v > 100 {
+				resp.Diagnostics.AddAttributeError(path.Root("percentage"), "Invalid Percentage",
+					fmt.Sprintf("Percentage must be between 0 and 100, got: %g", v))
+			}
+		}
+		if !data.SegmentKeys.IsNull() || !data.SegmentOperator.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("segment_keys"), "Invalid Rollout Configuration",
+				"segment_keys and segment_operator can only be set on a segment rollout.")
+		}
+	case segmentRolloutType:
+		if data.SegmentKeys.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("segment_keys"), "Missing Segment Keys",
+				"A segment rollout requires segment_keys.")
+		}
+		if !data.Percentage.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("percentage"), "Invalid Rollout Configuration",
+				"percentage can only be set on a threshold rollout.")
+		}
+		validateSegmentOperator(&resp.Diagnostics, path.Empty(), data.SegmentOperator, data.SegmentKeys)
+	}
+}
+
+func (r *RolloutResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultKeys(ctx, r.config, true, req, resp)
+}
+
+func (r *RolloutResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data RolloutResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Creating rollout", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+		"type":            data.Type.ValueString(),
+	})
+
+	// Serialize with other changes to the flag's rollouts
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
+
+	rollout, diags := rolloutFromModel(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Rollouts have no ID, so the position of a previous attempt is kept to
+	// recognize a rollout already added by an attempt whose response was lost
+	// when the write is retried.
+	rank := int64(-1)
+	_, err := r.config.Client.UpdateFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString(), func(flag *client.Flag) error {
+		if flag.Type != "BOOLEAN_FLAG_TYPE" {
+			return errNotBooleanFlag
+		}
+
+		if rank >= 0 && rank < int64(len(flag.Rollouts)) && reflect.DeepEqual(flag.Rollouts[rank], rollout) {
+			return nil
+		}
+
+		// Auto-assign rank as next available
+		rank = int64(len(flag.Rollouts))
+		if !data.Rank.IsNull() && !data.Rank.IsUnknown() {
+			rank = data.Rank.ValueInt64()
+			if err := checkRank(rank, len(flag.Rollouts)); err != nil {
+				return err
+			}
+		}
+
+		flag.Rollouts = insertRollout(flag.Rollouts, rank, rollout)
+		return nil
+	})
+	if r.addWriteError(&resp.Diagnostics, "create rollout", data, err) {
+		return
+	}
+
+	data.Rank = types.Int64Value(rank)
+	data.ID = types.StringValue(rolloutID(data.FlagKey.ValueString(), rank))
+	data.SegmentOperator = rolloutSegmentOperator(rollout)
+
+	tflog.Trace(ctx, "created a rollout resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RolloutResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data RolloutResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Reading rollout", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+		"rank":            data.Rank.ValueInt64(),
+	})
+
+	// Get the flag to read its rollouts
+	flag, err := r.config.Client.GetFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
+	if err != nil {
+		handleReadError(ctx, resp, "flag", data.FlagKey.ValueString(), err)
+		return
+	}
+
+	// Imported rollouts only know their rank until the first read
+	var rank int64
+	if data.Type.IsNull() {
+		rank = data.Rank.ValueInt64()
+		if rank < 0 || rank >= int64(len(flag.Rollouts)) {
+			rank = -1
+		}
+	} else {
+		rollout, diags := rolloutFromModel(ctx, data)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		rank = int64(findRollout(flag.Rollouts, rollout, data.Rank.ValueInt64()))
+	}
+	if rank < 0 {
+		tflog.Warn(ctx, "Rollout not found in flag, removing from state", map[string]interface{}{
+			"rank":     data.Rank.ValueInt64(),
+			"flag_key": data.FlagKey.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(rolloutToModel(ctx, flag.Rollouts[rank], &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Rank = types.Int64Value(rank)
+	data.ID = types.StringValue(rolloutID(data.FlagKey.ValueString(), rank))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RolloutResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data RolloutResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the current state to know which rollout to update
+	var state RolloutResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Updating rollout", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+		"old_rank":        state.Rank.ValueInt64(),
+		"type":            data.Type.ValueString(),
+	})
+
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
+
+	rollout, diags := rolloutFromModel(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Find the rollout by the values it had so far
+	oldRollout, diags := rolloutFromModel(ctx, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var rank int64
+	_, err := r.config.Client.UpdateFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString(), func(flag *client.Flag) error {
+		i := findRollout(flag.Rollouts, oldRollout, state.Rank.ValueInt64())
+		if i < 0 {
+			return errNotInParent
+		}
+
+		// Move the rollout when its rank changes. An unchanged rank keeps it
+		// where it is, as other rollouts may have shifted it since the plan.
+		flag.Rollouts = append(flag.Rollouts[:i], flag.Rollouts[i+1:]...)
+		rank = int64(i)
+		if !data.Rank.IsNull() && !data.Rank.IsUnknown() && !data.Rank.Equal(state.Rank) {
+			rank = data.Rank.ValueInt64()
+			if err := checkRank(rank, len(flag.Rollouts)); err != nil {
+				return err
+			}
+		}
+		flag.Rollouts = insertRollout(flag.Rollouts, rank, rollout)
+		return nil
+	})
+	if r.addWriteError(&resp.Diagnostics, "update rollout", data, err) {
+		return
+	}
+
+	data.Rank = types.Int64Value(rank)
+	data.ID = types.StringValue(rolloutID(data.FlagKey.ValueString(), rank))
+	data.SegmentOperator = rolloutSegmentOperator(rollout)
+
+	tflog.Trace(ctx, "updated a rollout resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RolloutResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data RolloutResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Deleting rollout", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"flag_key":        data.FlagKey.ValueString(),
+		"rank":            data.Rank.ValueInt64(),
+	})
+
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
+
+	rollout, diags := rolloutFromModel(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.config.Client.UpdateFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString(), func(flag *client.Flag) error {
+		i := findRollout(flag.Rollouts, rollout, data.Rank.ValueInt64())
+		if i < 0 {
+			return errNotInParent
+		}
+
+		// Update the flag without the deleted rollout
+		flag.Rollouts = append(flag.Rollouts[:i], flag.Rollouts[i+1:]...)
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) || errors.Is(err, errNotInParent) {
+			// Flag or rollout doesn't exist, rollout is already gone
+			return
+		}
+		addParentWriteError(&resp.Diagnostics, "delete rollout", "flag", data.FlagKey.ValueString(), err)
+		return
+	}
+
+	tflog.Trace(ctx, "deleted a rollout resource")
+}
+
+// ImportState imports a rollout by an ID of the form
+// environment_key/namespace_key/flag_key/rank.
+func (r *RolloutResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts, ok := parseImportID(req, resp, "environment_key", "namespace_key", "flag_key", "rank")
+	if !ok {
+		return
+	}
+	rank, ok := parseImportIndex(resp, "rank", parts[3])
+	if !ok {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("environment_key"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("namespace_key"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("flag_key"), parts[2])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("rank"), rank)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), rolloutID(parts[2], rank))...)
+}
+
+// addWriteError adds the diagnostic for a failed write of a rollout and
+// reports whether there was one.
+func (r *RolloutResource) addWriteError(diags *diag.Diagnostics, action string, data RolloutResourceModel, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, errNotBooleanFlag):
+		diags.AddAttributeError(path.Root("flag_key"), "Invalid Flag Type",
+			fmt.Sprintf("Unable to %s: flag '%s' is not a BOOLEAN_FLAG_TYPE flag. Use flipt_rule for variant flags.", action, data.FlagKey.ValueString()))
+	case errors.Is(err, errNotInParent):
+		diags.AddError("Not Found", fmt.Sprintf("Unable to %s: the rollout is no longer in flag '%s'", action, data.FlagKey.ValueString()))
+	default:
+		addParentWriteError(diags, action, "flag", data.FlagKey.ValueString(), err)
+	}
+	return true
+}
+
+// findRollout returns the index of the rollout equal to want. Rollouts have
+// no ID, so they are matched by their content and keep being found when the
+// ranks of the flag's rollouts shift. The one at the given rank is preferred
+// when several are equal. A rollout changed outside of Terraform matches
+// none, and the one at the given rank is returned so that the change shows
+// up as drift. It returns -1 if there is no match and the rank is out of
+// range.
+func findRollout(rollouts []client.Rollout, want client.Rollout, rank int64) int {
+	match := -1
+	for i, rollout := range rollouts {
+		if !reflect.DeepEqual(rollout, want) {
+			continue
+		}
+		if int64(i) == rank {
+			return i
+		}
+		if match < 0 {
+			match = i
+		}
+	}
+	if match < 0 && rank >= 0 && rank < int64(len(rollouts)) {
+		return int(rank)
+	}
+	return match
+}
+
+// rolloutID returns the identifier of the rollout at the given rank.
+func rolloutID(flagKey string, rank int64) string {
+	return fmt.Sprintf("%s/%d", flagKey, rank)
+}
+
+// insertRollout inserts a rollout at the given position.
+func insertRollout(rollouts []client.Rollout, i int64, rollout client.Rollout) []client.Rollout {
+	rollouts = append(rollouts, client.Rollout{})
+	copy(rollouts[i+1:], rollouts[i:])
+	rollouts[i] = rollout
+	return rollouts
+}
+
+// rolloutSegmentOperator returns the segment operator of a rollout for the
+// state, which is null on threshold rollouts.
+func rolloutSegmentOperator(rollout client.Rollout) types.String {
+	if rollout.Segment == nil {
+		return types.StringNull()
+	}
+	return types.StringValue(rollout.Segment.SegmentOperator)
+}
+
+// rolloutFromModel builds the rollout payload from the resource model.
+func rolloutFromModel(ctx context.Context, data RolloutResourceModel) (client.Rollout, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	rollout := client.Rollout{
+		Type: data.Type.ValueString(),
+	}
+
+	if !data.Description.IsNull() && !data.Description.IsUnknown() {
+		rollout.Description = data.Description.ValueString()
+	}
+
+	if rollout.Type == segmentRolloutType {
+		var segmentKeys []string
+		diags.Append(data.SegmentKeys.ElementsAs(ctx, &segmentKeys, false)...)
+
+		segmentOperator := "OR_SEGMENT_OPERATOR"
+		if !data.SegmentOperator.IsNull() && !data.SegmentOperator.IsUnknown() {
+			segmentOperator = data.SegmentOperator.ValueString()
+		}
+
+		rollout.Segment = &client.RolloutSegment{
+			Value:           data.Value.ValueBool(),
+			Segments:        segmentKeys,
+			SegmentOperator: segmentOperator,
+		}
+		return rollout, diags
+	}
+
+	rollout.Threshold = &client.RolloutThreshold{
+		Percentage: data.Percentage.ValueFloat64(),
+		Value:      data.Value.ValueBool(),
+	}
+	return rollout, diags
+}
+
+// rolloutToModel copies a rollout read from Flipt into the resource model.
+func rolloutToModel(ctx context.Context, rollout client.Rollout, data *RolloutResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	data.Type = types.StringValue(rollout.Type)
+
+	data.Description = types.StringNull()
+	if rollout.Description != "" {
+		data.Description = types.StringValue(rollout.Description)
+	}
+
+	data.Percentage = types.Float64Null()
+	data.SegmentKeys = types.ListNull(types.StringType)
+	data.SegmentOperator = rolloutSegmentOperator(rollout)
+
+	switch {
+	case rollout.Segment != nil:
+		data.Value = types.BoolValue(rollout.Segment.Value)
+
+		segmentKeys, d := types.ListValueFrom(ctx, types.StringType, rollout.Segment.Segments)
+		diags.Append(d...)
+		data.SegmentKeys = segmentKeys
+	case rollout.Threshold != nil:
+		data.Value = types.BoolValue(rollout.Threshold.Value)
+		data.Percentage = types.Float64Value(rollout.Threshold.Percentage)
+	}
+
+	return diags
+}
@@ -0,0 +1,412 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"terraform-provider-flipt/internal/client"
+)
+
+func TestAccRolloutResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccRolloutResourceConfig(25),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_rollout.segment", "rank", "0"),
+					resource.TestCheckResourceAttr("flipt_rollout.segment", "id", "test-flag/0"),
+					resource.TestCheckResourceAttr("flipt_rollout.segment", "type", "SEGMENT_ROLLOUT_TYPE"),
+					resource.TestCheckResourceAttr("flipt_rollout.segment", "segment_keys.0", "test-segment"),
+					resource.TestCheckResourceAttr("flipt_rollout.segment", "segment_operator", "OR_SEGMENT_OPERATOR"),
+					resource.TestCheckResourceAttr("flipt_rollout.segment", "value", "true"),
+					resource.TestCheckResourceAttr("flipt_rollout.threshold", "rank", "1"),
+					resource.TestCheckResourceAttr("flipt_rollout.threshold", "id", "test-flag/1"),
+					resource.TestCheckResourceAttr("flipt_rollout.threshold", "type", "THRESHOLD_ROLLOUT_TYPE"),
+					resource.TestCheckResourceAttr("flipt_rollout.threshold", "percentage", "25"),
+					resource.TestCheckResourceAttr("flipt_rollout.threshold", "value", "false"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "flipt_rollout.threshold",
+				ImportState:       true,
+				ImportStateId:     "default/test-rollout-ns/test-flag/1",
+				ImportStateVerify: true,
+			},
+			// Update and Read testing
+			{
+				Config: testAccRolloutResourceConfig(75),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_rollout.threshold", "percentage", "75"),
+				),
+			},
+		},
+	})
+}
+
+func testAccRolloutResourceConfig(percentage float64) string {
+	return fmt.Sprintf(`
+provider "flipt" {
+  endpoint = %[1]q
+}
+
+resource "flipt_namespace" "test" {
+  key  = "test-rollout-ns"
+  name = "Test Namespace"
+}
+
+resource "flipt_flag" "test" {
+  namespace_key = flipt_namespace.test.key
+  key           = "test-flag"
+  name          = "Test Flag"
+  type          = "BOOLEAN_FLAG_TYPE"
+}
+
+resource "flipt_segment" "test" {
+  namespace_key = flipt_namespace.test.key
+  key           = "test-segment"
+  name          = "Test Segment"
+  match_type    = "ALL_MATCH_TYPE"
+}
+
+resource "flipt_rollout" "segment" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.test.key
+  rank          = 0
+  type          = "SEGMENT_ROLLOUT_TYPE"
+  segment_keys  = [flipt_segment.test.key]
+  value         = true
+}
+
+resource "flipt_rollout" "threshold" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.test.key
+  type          = "THRESHOLD_ROLLOUT_TYPE"
+  percentage    = %[2]g
+  value         = false
+
+  depends_on = [flipt_rollout.segment]
+}
+`, getTestFliptEndpoint(), percentage)
+}
+
+// thresholdRollout returns the model of a threshold rollout on the checkout
+// flag.
+func thresholdRollout(rank types.Int64, percentage float64) *RolloutResourceModel {
+	return &RolloutResourceModel{
+		NamespaceKey:    types.StringValue("default"),
+		EnvironmentKey:  types.StringValue("default"),
+		FlagKey:         types.StringValue("checkout"),
+		ID:              types.StringUnknown(),
+		Rank:            rank,
+		Type:            types.StringValue(thresholdRolloutType),
+		Description:     types.StringNull(),
+		Value:           types.BoolValue(true),
+		Percentage:      types.Float64Value(percentage),
+		SegmentKeys:     types.ListNull(types.StringType),
+		SegmentOperator: types.StringUnknown(),
+	}
+}
+
+// storedRollouts returns the rollouts of the checkout flag on the fake server.
+func storedRollouts(t *testing.T, fake *fakeFlipt) []client.Rollout {
+	t.Helper()
+
+	raw, err := json.Marshal(fake.get("default", "default", client.TypeFlag, "checkout")["rollouts"])
+	if err != nil {
+		t.Fatalf("Unable to marshal rollouts: %v", err)
+	}
+	var rollouts []client.Rollout
+	if err := json.Unmarshal(raw, &rollouts); err != nil {
+		t.Fatalf("Unable to unmarshal rollouts: %v", err)
+	}
+	return rollouts
+}
+
+func TestRolloutResourceCreate(t *testing.T) {
+	tests := []struct {
+		name        string
+		rank        types.Int64
+		expectRank  int64
+		expectOrder []float64
+		expectErr   bool
+	}{
+		{name: "Appended without rank", rank: types.Int64Unknown(), expectRank: 2, expectOrder: []float64{10, 20, 50}},
+		{name: "Inserted at rank", rank: types.Int64Value(0), expectRank: 0, expectOrder: []float64{50, 10, 20}},
+		{name: "Appended at rank", rank: types.Int64Value(2), expectRank: 2, expectOrder: []float64{10, 20, 50}},
+		{name: "Rank past the end", rank: types.Int64Value(7), expectOrder: []float64{10, 20}, expectErr: true},
+		{name: "Negative rank", rank: types.Int64Value(-1), expectOrder: []float64{10, 20}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeFlipt(t)
+			fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+				"name":    "Checkout",
+				"type":    "BOOLEAN_FLAG_TYPE",
+				"enabled": true,
+				"rollouts": []interface{}{
+					map[string]interface{}{"type": thresholdRolloutType, "threshold": map[string]interface{}{"percentage": 10, "value": true}},
+					map[string]interface{}{"type": thresholdRolloutType, "threshold": map[string]interface{}{"percentage": 20, "value": true}},
+				},
+			})
+
+			r := NewRolloutResource()
+			configureResource(t, r, fake.config())
+
+			resp, state := createResource(t, r, thresholdRollout(tt.rank, 50))
+			if tt.expectErr {
+				if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != "Invalid Rank" {
+					t.Fatalf("Expected an Invalid Rank error, got %v", resp.Diagnostics)
+				}
+				if d, ok := resp.Diagnostics.Errors()[0].(diag.DiagnosticWithPath); !ok || !d.Path().Equal(path.Root("rank")) {
+					t.Errorf("Expected the error on rank, got %v", resp.Diagnostics.Errors()[0])
+				}
+			} else {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("Create failed: %v", resp.Diagnostics)
+				}
+
+				var data RolloutResourceModel
+				state.Get(context.Background(), &data)
+				if data.Rank.ValueInt64() != tt.expectRank || data.ID.ValueString() != fmt.Sprintf("checkout/%d", tt.expectRank) {
+					t.Errorf("Expected rank %d, got rank %s and id %s", tt.expectRank, data.Rank, data.ID)
+				}
+			}
+
+			rollouts := storedRollouts(t, fake)
+			if len(rollouts) != len(tt.expectOrder) {
+				t.Fatalf("Expected %d rollouts, got %d", len(tt.expectOrder), len(rollouts))
+			}
+			for i, percentage := range tt.expectOrder {
+				if rollouts[i].Threshold.Percentage != percentage {
+					t.Errorf("Expected rollout %d to have percentage %g, got %g", i, percentage, rollouts[i].Threshold.Percentage)
+				}
+			}
+		})
+	}
+}
+
+func TestRolloutResourceCreateVariantFlag(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+		"name":    "Checkout",
+		"type":    "VARIANT_FLAG_TYPE",
+		"enabled": true,
+	})
+
+	r := NewRolloutResource()
+	configureResource(t, r, fake.config())
+
+	resp, _ := createResource(t, r, thresholdRollout(types.Int64Unknown(), 50))
+	if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != "Invalid Flag Type" {
+		t.Fatalf("Expected invalid flag type error, got %v", resp.Diagnostics)
+	}
+}
+
+func TestRolloutResourceUpdateAndDelete(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+		"name":    "Checkout",
+		"type":    "BOOLEAN_FLAG_TYPE",
+		"enabled": true,
+		"rollouts": []interface{}{
+			map[string]interface{}{"type": thresholdRolloutType, "threshold": map[string]interface{}{"percentage": 10, "value": true}},
+			map[string]interface{}{"type": thresholdRolloutType, "threshold": map[string]interface{}{"percentage": 20, "value": true}},
+		},
+	})
+
+	r := NewRolloutResource()
+	configureResource(t, r, fake.config())
+
+	state := thresholdRollout(types.Int64Value(0), 10)
+	state.ID = types.StringValue("checkout/0")
+	state.SegmentOperator = types.StringNull()
+
+	// Moving the first rollout behind the second one
+	resp, _ := updateResource(t, r, thresholdRollout(types.Int64Value(1), 15), state)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Update failed: %v", resp.Diagnostics)
+	}
+	rollouts := storedRollouts(t, fake)
+	if len(rollouts) != 2 || rollouts[0].Threshold.Percentage != 20 || rollouts[1].Threshold.Percentage != 15 {
+		t.Fatalf("Unexpected rollouts after update: %+v", rollouts)
+	}
+
+	// Without the rollout itself there is only one other rollout
+	state = thresholdRollout(types.Int64Value(1), 15)
+	state.ID = types.StringValue("checkout/1")
+	state.SegmentOperator = types.StringNull()
+	resp, _ = updateResource(t, r, thresholdRollout(types.Int64Value(2), 15), state)
+	if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != "Invalid Rank" {
+		t.Fatalf("Expected an Invalid Rank error, got %v", resp.Diagnostics)
+	}
+
+	state.Rank = types.Int64Value(1)
+	if resp := deleteResource(t, r, state); resp.Diagnostics.HasError() {
+		t.Fatalf("Delete failed: %v", resp.Diagnostics)
+	}
+	rollouts = storedRollouts(t, fake)
+	if len(rollouts) != 1 || rollouts[0].Threshold.Percentage != 20 {
+		t.Fatalf("Unexpected rollouts after delete: %+v", rollouts)
+	}
+}
+
+func TestRolloutResourceShiftedRank(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+		"name":    "Checkout",
+		"type":    "BOOLEAN_FLAG_TYPE",
+		"enabled": true,
+		"rollouts": []interface{}{
+			map[string]interface{}{"type": thresholdRolloutType, "threshold": map[string]interface{}{"percentage": 10, "value": true}},
+			map[string]interface{}{"type": thresholdRolloutType, "threshold": map[string]interface{}{"percentage": 20, "value": true}},
+			map[string]interface{}{"type": thresholdRolloutType, "threshold": map[string]interface{}{"percentage": 30, "value": true}},
+		},
+	})
+
+	r := NewRolloutResource()
+	configureResource(t, r, fake.config())
+
+	state := thresholdRollout(types.Int64Value(2), 30)
+	state.ID = types.StringValue("checkout/2")
+	state.SegmentOperator = types.StringNull()
+
+	// The first rollout is removed, shifting the others up
+	rollouts := fake.get("default", "default", client.TypeFlag, "checkout")["rollouts"].([]interface{})
+	fake.get("default", "default", client.TypeFlag, "checkout")["rollouts"] = rollouts[1:]
+
+	// Read follows the rollout to its new rank
+	resp, readState := readResource(t, r, state)
+	if resp.Diagnostics.HasError() || readState.Raw.IsNull() {
+		t.Fatalf("Read failed: %v", resp.Diagnostics)
+	}
+	var data RolloutResourceModel
+	readState.Get(context.Background(), &data)
+	if data.Rank.ValueInt64() != 1 || data.ID.ValueString() != "checkout/1" || data.Percentage.ValueFloat64() != 30 {
+		t.Errorf("Expected the rollout at rank 1, got rank %s id %s percentage %s", data.Rank, data.ID, data.Percentage)
+	}
+
+	// Update and Delete with the rank from before the shift change the same
+	// rollout instead of failing or touching another one
+	updateResp, _ := updateResource(t, r, thresholdRollout(types.Int64Value(2), 35), state)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("Update failed: %v", updateResp.Diagnostics)
+	}
+	stored := storedRollouts(t, fake)
+	if len(stored) != 2 || stored[0].Threshold.Percentage != 20 || stored[1].Threshold.Percentage != 35 {
+		t.Fatalf("Unexpected rollouts after update: %+v", stored)
+	}
+
+	state.Percentage = types.Float64Value(35)
+	if resp := deleteResource(t, r, state); resp.Diagnostics.HasError() {
+		t.Fatalf("Delete failed: %v", resp.Diagnostics)
+	}
+	stored = storedRollouts(t, fake)
+	if len(stored) != 1 || stored[0].Threshold.Percentage != 20 {
+		t.Fatalf("Expected only the rollout at 20%% to remain, got %+v", stored)
+	}
+
+	// A rollout past the end of the flag's rollouts is gone
+	if resp := deleteResource(t, r, state); resp.Diagnostics.HasError() {
+		t.Fatalf("Delete failed: %v", resp.Diagnostics)
+	}
+	if stored = storedRollouts(t, fake); len(stored) != 1 {
+		t.Fatalf("Expected the remaining rollout to be kept, got %+v", stored)
+	}
+}
+
+func TestRolloutResourceChangedOutsideTerraform(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+		"name":    "Checkout",
+		"type":    "BOOLEAN_FLAG_TYPE",
+		"enabled": true,
+		"rollouts": []interface{}{
+			map[string]interface{}{"type": thresholdRolloutType, "threshold": map[string]interface{}{"percentage": 10, "value": true}},
+			map[string]interface{}{"type": thresholdRolloutType, "threshold": map[string]interface{}{"percentage": 45, "value": true}},
+		},
+	})
+
+	r := NewRolloutResource()
+	configureResource(t, r, fake.config())
+
+	// The second rollout was created at 30% and changed to 45% since
+	state := thresholdRollout(types.Int64Value(1), 30)
+	state.ID = types.StringValue("checkout/1")
+	state.SegmentOperator = types.StringNull()
+
+	// Read keeps the rollout at its rank and shows the change as drift
+	resp, readState := readResource(t, r, state)
+	if resp.Diagnostics.HasError() || readState.Raw.IsNull() {
+		t.Fatalf("Expected the rollout to stay in state, got %v", resp.Diagnostics)
+	}
+	var data RolloutResourceModel
+	readState.Get(context.Background(), &data)
+	if data.Rank.ValueInt64() != 1 || data.Percentage.ValueFloat64() != 45 {
+		t.Errorf("Expected the rollout at rank 1 with 45%%, got rank %s percentage %s", data.Rank, data.Percentage)
+	}
+
+	// Applying the configuration changes it back instead of adding another
+	data.SegmentOperator = types.StringNull()
+	updateResp, _ := updateResource(t, r, thresholdRollout(types.Int64Value(1), 30), &data)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("Update failed: %v", updateResp.Diagnostics)
+	}
+	stored := storedRollouts(t, fake)
+	if len(stored) != 2 || stored[0].Threshold.Percentage != 10 || stored[1].Threshold.Percentage != 30 {
+		t.Fatalf("Unexpected rollouts after update: %+v", stored)
+	}
+}
+
+func TestRolloutResourceReadImported(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+		"name":    "Checkout",
+		"type":    "BOOLEAN_FLAG_TYPE",
+		"enabled": true,
+		"rollouts": []interface{}{
+			map[string]interface{}{"type": thresholdRolloutType, "threshold": map[string]interface{}{"percentage": 10, "value": true}},
+			map[string]interface{}{"type": thresholdRolloutType, "threshold": map[string]interface{}{"percentage": 20, "value": false}},
+		},
+	})
+
+	r := NewRolloutResource()
+	configureResource(t, r, fake.config())
+
+	// Imported state only knows the keys and the rank
+	resp, state := readResource(t, r, &RolloutResourceModel{
+		NamespaceKey:    types.StringValue("default"),
+		EnvironmentKey:  types.StringValue("default"),
+		FlagKey:         types.StringValue("checkout"),
+		ID:              types.StringValue("checkout/1"),
+		Rank:            types.Int64Value(1),
+		Type:            types.StringNull(),
+		Description:     types.StringNull(),
+		Value:           types.BoolNull(),
+		Percentage:      types.Float64Null(),
+		SegmentKeys:     types.ListNull(types.StringType),
+		SegmentOperator: types.StringNull(),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read failed: %v", resp.Diagnostics)
+	}
+
+	var data RolloutResourceModel
+	state.Get(context.Background(), &data)
+	if data.Type.ValueString() != thresholdRolloutType || data.Percentage.ValueFloat64() != 20 || data.Value.ValueBool() {
+		t.Errorf("Unexpected rollout in state: %+v", data)
+	}
+}
@@ -4,25 +4,30 @@
 package provider
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
 )
 
 var _ resource.Resource = &RuleResource{}
 var _ resource.ResourceWithImportState = &RuleResource{}
+var _ resource.ResourceWithModifyPlan = &RuleResource{}
+var _ resource.ResourceWithValidateConfig = &RuleResource{}
 
 type RuleResource struct {
 	config *FliptProviderConfig
@@ -52,14 +57,19 @@ func (r *RuleResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 
 		Attributes: map[string]schema.Attribute{
 			"namespace_key": schema.StringAttribute{
-				MarkdownDescription: "Namespace key where the flag belongs",
-				Required:            true,
+				MarkdownDescription: "Namespace key where the flag belongs. Defaults to the provider's `default_namespace_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
 				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
 			"environment_key": schema.StringAttribute{
-				MarkdownDescription: "Environment key (defaults to 'default' if not specified)",
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
 				Optional:            true,
 				Computed:            true,
 				PlanModifiers: []planmodifier.String{
@@ -70,12 +80,15 @@ func (r *RuleResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 			"flag_key": schema.StringAttribute{
 				MarkdownDescription: "Flag key that this rule belongs to",
 				Required:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
 			"id": schema.StringAttribute{
-				MarkdownDescription: "Unique identifier for the rule (auto-generated)",
+				MarkdownDescription: "Unique identifier for the rule, generated by the provider and stored with the rule in Flipt",
 				Computed:            true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
@@ -85,16 +98,25 @@ func (r *RuleResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				ElementType:         types.StringType,
 				MarkdownDescription: "List of segment keys to evaluate for this rule",
 				Required:            true,
+				Validators: []validator.List{
+					segmentKeysValidator(),
+				},
 			},
 			"segment_operator": schema.StringAttribute{
-				MarkdownDescription: "Operator for combining segments (OR_SEGMENT_OPERATOR or AND_SEGMENT_OPERATOR)",
+				MarkdownDescription: "Operator for combining segments (OR_SEGMENT_OPERATOR or AND_SEGMENT_OPERATOR). AND_SEGMENT_OPERATOR needs at least two segment keys",
 				Optional:            true,
 				Computed:            true,
+				Validators: []validator.String{
+					segmentOperatorValidator(),
+				},
 			},
 			"rank": schema.Int64Attribute{
-				MarkdownDescription: "Rank/order of the rule (lower ranks are evaluated first)",
+				MarkdownDescription: "Rank of the rule (lower ranks are evaluated first). The rules of a flag are kept ordered by rank, which does not need to start at 0 or be consecutive. Defaults to one more than the highest rank of the flag's rules, appending the rule",
 				Optional:            true,
 				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
 			},
 		},
 	}
@@ -117,6 +139,20 @@ func (r *RuleResource) Configure(ctx context.Context, req resource.ConfigureRequ
 	r.config = providerConfig
 }
 
+func (r *RuleResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data RuleResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateSegmentOperator(&resp.Diagnostics, path.Empty(), data.SegmentOperator, data.SegmentKeys)
+}
+
+func (r *RuleResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultKeys(ctx, r.config, true, req, resp)
+}
+
 func (r *RuleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data RuleResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -124,11 +160,10 @@ func (r *RuleResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Creating rule", map[string]interface{}{
 		"environment_key": envKey,
@@ -136,51 +171,9 @@ func (r *RuleResource) Create(ctx context.Context, req resource.CreateRequest, r
 		"flag_key":        data.FlagKey.ValueString(),
 	})
 
-	// First, get the current flag to read existing rules
-	flagURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Flag/%s",
-		r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
-
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", flagURL, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read flag, got error: %s", err))
-		return
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read flag, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var flagResponse struct {
-		Resource struct {
-			Payload struct {
-				Type           string                   `json:"type"`
-				Key            string                   `json:"key"`
-				Name           string                   `json:"name"`
-				Description    string                   `json:"description"`
-				Enabled        bool                     `json:"enabled"`
-				Variants       []map[string]interface{} `json:"variants"`
-				Rules          []map[string]interface{} `json:"rules"`
-				DefaultVariant string                   `json:"defaultVariant"`
-				Metadata       map[string]interface{}   `json:"metadata"`
-			} `json:"payload"`
-		} `json:"resource"`
-	}
-
-	body, _ := io.ReadAll(httpResp.Body)
-	if err := json.Unmarshal(body, &flagResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse flag response: %s", err))
-		return
-	}
+	// Serialize with other changes to the flag's rules
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
 
 	// Extract segment keys from plan
 	var segmentKeys []string
@@ -189,90 +182,48 @@ func (r *RuleResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	// Generate ID for new rule
-	ruleID := uuid.New().String()
-
 	// Set defaults
 	segmentOperator := "OR_SEGMENT_OPERATOR"
 	if !data.SegmentOperator.IsNull() && !data.SegmentOperator.IsUnknown() {
 		segmentOperator = data.SegmentOperator.ValueString()
 	}
 
-	rank := int64(0)
-	if !data.Rank.IsNull() && !data.Rank.IsUnknown() {
-		rank = data.Rank.ValueInt64()
-	} else {
-		// Auto-assign rank as next available
-		rank = int64(len(flagResponse.Resource.Payload.Rules))
-	}
-
-	// Build new rule
-	newRule := map[string]interface{}{
-		"id":              ruleID,
-		"segments":        segmentKeys,
-		"segmentOperator": segmentOperator,
-		"rank":            rank,
-		"distributions":   []interface{}{}, // Empty distributions array
-	}
-
-	// Add new rule to existing rules
-	existingRules := flagResponse.Resource.Payload.Rules
-	if existingRules == nil {
-		existingRules = []map[string]interface{}{}
-	}
-	allRules := append(existingRules, newRule)
-
-	// Update the flag with all rules (including the new one)
-	flagPayload := map[string]interface{}{
-		"@type":          "flipt.core.Flag",
-		"key":            flagResponse.Resource.Payload.Key,
-		"name":           flagResponse.Resource.Payload.Name,
-		"description":    flagResponse.Resource.Payload.Description,
-		"type":           flagResponse.Resource.Payload.Type,
-		"enabled":        flagResponse.Resource.Payload.Enabled,
-		"variants":       flagResponse.Resource.Payload.Variants,
-		"rules":          allRules,
-		"defaultVariant": flagResponse.Resource.Payload.DefaultVariant,
-		"metadata":       flagResponse.Resource.Payload.Metadata,
-	}
-
-	updateReq := map[string]interface{}{
-		"key":     data.FlagKey.ValueString(),
-		"payload": flagPayload,
-	}
+	// The ID is stored with the rule and identifies it from then on, whatever
+	// its position or segments. It is generated once so that a rule already
+	// added by an attempt whose response was lost is recognized when the
+	// write is retried.
+	ruleID := uuid.New().String()
 
-	reqBody, err := json.Marshal(updateReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Serialization Error", fmt.Sprintf("Unable to marshal request: %s", err))
-		return
-	}
+	var rank int64
+	_, err := r.config.Client.UpdateFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString(), func(flag *client.Flag) error {
+		for _, rule := range flag.Rules {
+			if rule.ID == ruleID {
+				rank = rule.Rank
+				return nil
+			}
+		}
 
-	updateURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources", r.config.Endpoint, envKey, data.NamespaceKey.ValueString())
-	httpReq, err = http.NewRequestWithContext(ctx, "PUT", updateURL, bytes.NewReader(reqBody))
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
+		// Auto-assign rank as next available
+		rank = nextRuleRank(flag.Rules)
+		if !data.Rank.IsNull() && !data.Rank.IsUnknown() {
+			rank = data.Rank.ValueInt64()
+		}
 
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err = r.config.HTTPClient.Do(httpReq)
+		flag.Rules = insertRule(flag.Rules, client.Rule{
+			ID:              ruleID,
+			Segments:        segmentKeys,
+			SegmentOperator: segmentOperator,
+			Rank:            rank,
+		})
+		return nil
+	})
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create rule, got error: %s", err))
-		return
-	}
-	defer httpResp.Body.Close()
-
-	body, _ = io.ReadAll(httpResp.Body)
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to create rule, status: %d, body: %s", httpResp.StatusCode, string(body)))
+		addParentWriteError(&resp.Diagnostics, "create rule", "flag", data.FlagKey.ValueString(), err)
 		return
 	}
 
 	// Set computed values
 	data.EnvironmentKey = types.StringValue(envKey)
-	// Generate a stable ID based on flag_key and rank (rank is more stable than operator)
-	ruleID = fmt.Sprintf("%s/%d", data.FlagKey.ValueString(), rank)
 	data.ID = types.StringValue(ruleID)
 	data.SegmentOperator = types.StringValue(segmentOperator)
 	data.Rank = types.Int64Value(rank)
@@ -288,11 +239,10 @@ func (r *RuleResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Reading rule", map[string]interface{}{
 		"environment_key": envKey,
@@ -302,124 +252,64 @@ func (r *RuleResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	})
 
 	// Get the flag to read its rules
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Flag/%s",
-		r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
-
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	flag, err := r.config.Client.GetFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
+		handleReadError(ctx, resp, "flag", data.FlagKey.ValueString(), err)
 		return
 	}
 
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
-	if err != nil {
-		resp.State.RemoveResource(ctx)
-		return
-	}
-	defer httpResp.Body.Close()
+	tflog.Debug(ctx, "Flag response received", map[string]interface{}{
+		"rules_count":    len(flag.Rules),
+		"looking_for_id": data.ID.ValueString(),
+	})
 
-	if httpResp.StatusCode == http.StatusNotFound {
-		resp.State.RemoveResource(ctx)
+	var expectedSegments []string
+	resp.Diagnostics.Append(data.SegmentKeys.ElementsAs(ctx, &expectedSegments, false)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	body, err := io.ReadAll(httpResp.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Read Error", fmt.Sprintf("Unable to read response: %s", err))
-		return
+	// Imported rules only know their position until the first read
+	imported := data.SegmentKeys.IsNull()
+	var i int
+	if imported {
+		i = findRuleByID(flag.Rules, data.FlagKey.ValueString(), data.ID.ValueString())
+	} else {
+		i = findRule(flag.Rules, data.ID.ValueString(), expectedSegments, data.SegmentOperator.ValueString(), data.Rank.ValueInt64())
 	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read flag, status: %d, body: %s", httpResp.StatusCode, string(body)))
+	if i < 0 {
+		tflog.Warn(ctx, "Rule not found in flag, removing from state", map[string]interface{}{
+			"rule_id":  data.ID.ValueString(),
+			"flag_key": data.FlagKey.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
 		return
 	}
+	rule := flag.Rules[i]
 
-	var flagResponse struct {
-		Resource struct {
-			Payload struct {
-				Rules []struct {
-					ID              string   `json:"id"`
-					Segments        []string `json:"segments"`
-					SegmentOperator string   `json:"segmentOperator"`
-					Rank            int64    `json:"rank"`
-				} `json:"rules"`
-			} `json:"payload"`
-		} `json:"resource"`
-	}
-
-	if err := json.Unmarshal(body, &flagResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s", err))
+	// Convert segments to types.List
+	segmentsList, diags := types.ListValueFrom(ctx, types.StringType, rule.Segments)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
+	data.SegmentKeys = segmentsList
 
-	tflog.Debug(ctx, "Flag response received", map[string]interface{}{
-		"rules_count":    len(flagResponse.Resource.Payload.Rules),
-		"looking_for_id": data.ID.ValueString(),
-	})
-
-	// Find the rule by matching segments, operator, and rank since Flipt doesn't preserve rule IDs
-	var found bool
-	for _, rule := range flagResponse.Resource.Payload.Rules {
-		tflog.Debug(ctx, "Checking rule", map[string]interface{}{
-			"rule_id":           rule.ID,
-			"rule_segments":     rule.Segments,
-			"rule_operator":     rule.SegmentOperator,
-			"rule_rank":         rule.Rank,
-			"expected_operator": data.SegmentOperator.ValueString(),
-			"expected_rank":     data.Rank.ValueInt64(),
-		})
-
-		// Match by segments, operator, and rank since Flipt doesn't preserve IDs
-		var expectedSegments []string
-		resp.Diagnostics.Append(data.SegmentKeys.ElementsAs(ctx, &expectedSegments, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-
-		// Check if segments match
-		segmentsMatch := len(rule.Segments) == len(expectedSegments)
-		if segmentsMatch {
-			for i, seg := range rule.Segments {
-				if i >= len(expectedSegments) || seg != expectedSegments[i] {
-					segmentsMatch = false
-					break
-				}
-			}
-		}
-
-		if segmentsMatch &&
-			rule.SegmentOperator == data.SegmentOperator.ValueString() &&
-			rule.Rank == data.Rank.ValueInt64() {
-			found = true
-
-			// Convert segments to types.List
-			segmentsList, diags := types.ListValueFrom(ctx, types.StringType, rule.Segments)
-			resp.Diagnostics.Append(diags...)
-			if resp.Diagnostics.HasError() {
-				return
-			}
-			data.SegmentKeys = segmentsList
-
-			data.SegmentOperator = types.StringValue(rule.SegmentOperator)
-			data.Rank = types.Int64Value(rule.Rank)
+	data.SegmentOperator = types.StringValue(rule.SegmentOperator)
 
-			// Generate a stable ID based on rule attributes if not already set
-			if data.ID.IsNull() || data.ID.ValueString() == "" {
-				ruleID := fmt.Sprintf("%s/%d", data.FlagKey.ValueString(), rule.Rank)
-				data.ID = types.StringValue(ruleID)
-			}
-			break
-		}
+	// The rank in state is the configured one, which orders the rule among
+	// the others but is not its position. Imported rules take the rank
+	// stored with the rule.
+	if imported {
+		data.Rank = types.Int64Value(rule.Rank)
 	}
 
-	if !found {
-		tflog.Warn(ctx, "Rule not found in flag, removing from state", map[string]interface{}{
-			"rule_id":  data.ID.ValueString(),
-			"flag_key": data.FlagKey.ValueString(),
-		})
-		resp.State.RemoveResource(ctx)
-		return
+	// Rules created before IDs were stored with them, or outside of
+	// Terraform, keep an ID based on their rank until the next update
+	if rule.ID != "" {
+		data.ID = types.StringValue(rule.ID)
+	} else if data.ID.IsNull() || data.ID.ValueString() == "" {
+		data.ID = types.StringValue(fmt.Sprintf("%s/%d", data.FlagKey.ValueString(), i))
 	}
 
 	// Ensure EnvironmentKey is set in state
@@ -442,11 +332,10 @@ func (r *RuleResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Updating rule", map[string]interface{}{
 		"environment_key": envKey,
@@ -456,51 +345,8 @@ func (r *RuleResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		"new_plan_values": fmt.Sprintf("operator=%s rank=%d", data.SegmentOperator.ValueString(), data.Rank.ValueInt64()),
 	})
 
-	// Get the current flag to read existing rules
-	flagURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Flag/%s",
-		r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
-
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", flagURL, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read flag, got error: %s", err))
-		return
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read flag, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var flagResponse struct {
-		Resource struct {
-			Payload struct {
-				Type           string                   `json:"type"`
-				Key            string                   `json:"key"`
-				Name           string                   `json:"name"`
-				Description    string                   `json:"description"`
-				Enabled        bool                     `json:"enabled"`
-				Variants       []map[string]interface{} `json:"variants"`
-				Rules          []map[string]interface{} `json:"rules"`
-				DefaultVariant string                   `json:"defaultVariant"`
-				Metadata       map[string]interface{}   `json:"metadata"`
-			} `json:"payload"`
-		} `json:"resource"`
-	}
-
-	body, _ := io.ReadAll(httpResp.Body)
-	if err := json.Unmarshal(body, &flagResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse flag response: %s", err))
-		return
-	}
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
 
 	// Extract segment keys from plan
 	var segmentKeys []string
@@ -516,111 +362,58 @@ func (r *RuleResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
-	// Find and update the rule in the rules array by matching old state values
-	var found bool
-	existingRules := flagResponse.Resource.Payload.Rules
-	if existingRules == nil {
-		existingRules = []map[string]interface{}{}
-	}
-
-	for i, rule := range existingRules {
-		// Match by old state values (operator and rank) to find the rule to update
-		ruleSegments, _ := rule["segments"].([]interface{})
-		ruleOperator, _ := rule["segmentOperator"].(string)
-		ruleRank, _ := rule["rank"].(float64)
-
-		// Check if this rule matches the old state
-		segmentsMatch := len(ruleSegments) == len(oldSegmentKeys)
-		if segmentsMatch {
-			for j, seg := range ruleSegments {
-				if segStr, ok := seg.(string); ok && j < len(oldSegmentKeys) {
-					if segStr != oldSegmentKeys[j] {
-						segmentsMatch = false
-						break
-					}
-				}
-			}
-		}
-
-		if segmentsMatch &&
-			ruleOperator == state.SegmentOperator.ValueString() &&
-			int64(ruleRank) == state.Rank.ValueInt64() {
-			found = true
-
-			// Preserve distributions if they exist
-			distributions := rule["distributions"]
-			if distributions == nil {
-				distributions = []interface{}{}
-			}
+	// Rules without a stored ID are given one now. A rule whose ID was not
+	// kept by the server was matched by its content, and keeps the ID in state.
+	newID := state.ID.ValueString()
+	if _, err := uuid.Parse(newID); err != nil {
+		newID = uuid.New().String()
+	}
 
-			// Update the rule with new values
-			existingRules[i] = map[string]interface{}{
-				"segments":        segmentKeys,
-				"segmentOperator": data.SegmentOperator.ValueString(),
-				"rank":            data.Rank.ValueInt64(),
-				"distributions":   distributions,
-			}
-			break
+	var ruleID string
+	var rank int64
+	_, err := r.config.Client.UpdateFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString(), func(flag *client.Flag) error {
+		i := findRule(flag.Rules, state.ID.ValueString(), oldSegmentKeys, state.SegmentOperator.ValueString(), state.Rank.ValueInt64())
+		if i < 0 {
+			return errNotInParent
 		}
-	}
 
-	if !found {
+		// Update the rule with new values, preserving its distributions
+		rule := flag.Rules[i]
+		if rule.ID == "" {
+			rule.ID = newID
+		}
+		rule.Segments = segmentKeys
+		rule.SegmentOperator = data.SegmentOperator.ValueString()
+
+		// Move the rule among the others by its new rank when the rank
+		// changes, and keep it where it is otherwise
+		rank = state.Rank.ValueInt64()
+		if !data.Rank.IsNull() && !data.Rank.IsUnknown() {
+			rank = data.Rank.ValueInt64()
+		}
+		rule.Rank = rank
+		if rank != state.Rank.ValueInt64() {
+			flag.Rules = insertRule(append(flag.Rules[:i], flag.Rules[i+1:]...), rule)
+		} else {
+			flag.Rules[i] = rule
+		}
+		ruleID = rule.ID
+		return nil
+	})
+	if errors.Is(err, errNotInParent) {
 		resp.Diagnostics.AddError("Not Found", fmt.Sprintf("Rule with state ID %s not found in flag (operator=%s, rank=%d)",
 			state.ID.ValueString(), state.SegmentOperator.ValueString(), state.Rank.ValueInt64()))
 		return
 	}
-
-	// Update the flag with all rules (including the modified one)
-	flagPayload := map[string]interface{}{
-		"@type":          "flipt.core.Flag",
-		"key":            flagResponse.Resource.Payload.Key,
-		"name":           flagResponse.Resource.Payload.Name,
-		"description":    flagResponse.Resource.Payload.Description,
-		"type":           flagResponse.Resource.Payload.Type,
-		"enabled":        flagResponse.Resource.Payload.Enabled,
-		"variants":       flagResponse.Resource.Payload.Variants,
-		"rules":          existingRules,
-		"defaultVariant": flagResponse.Resource.Payload.DefaultVariant,
-		"metadata":       flagResponse.Resource.Payload.Metadata,
-	}
-
-	updateReq := map[string]interface{}{
-		"key":     data.FlagKey.ValueString(),
-		"payload": flagPayload,
-	}
-
-	reqBody, err := json.Marshal(updateReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Serialization Error", fmt.Sprintf("Unable to marshal request: %s", err))
-		return
-	}
-
-	updateURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources", r.config.Endpoint, envKey, data.NamespaceKey.ValueString())
-	httpReq, err = http.NewRequestWithContext(ctx, "PUT", updateURL, bytes.NewReader(reqBody))
 	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err = r.config.HTTPClient.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update rule, got error: %s", err))
-		return
-	}
-	defer httpResp.Body.Close()
-
-	body, _ = io.ReadAll(httpResp.Body)
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to update rule, status: %d, body: %s", httpResp.StatusCode, string(body)))
+		addParentWriteError(&resp.Diagnostics, "update rule", "flag", data.FlagKey.ValueString(), err)
 		return
 	}
 
 	// Ensure EnvironmentKey is set in state
 	data.EnvironmentKey = types.StringValue(envKey)
-
-	// ID remains stable based on flag_key and rank (don't change it)
+	data.ID = types.StringValue(ruleID)
+	data.Rank = types.Int64Value(rank)
 
 	tflog.Trace(ctx, "updated a rule resource")
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -633,11 +426,10 @@ func (r *RuleResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Deleting rule", map[string]interface{}{
 		"environment_key": envKey,
@@ -646,121 +438,130 @@ func (r *RuleResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		"rule_id":         data.ID.ValueString(),
 	})
 
-	// Get the current flag to read existing rules
-	flagURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Flag/%s",
-		r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
 
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", flagURL, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
+	var segmentKeys []string
+	resp.Diagnostics.Append(data.SegmentKeys.ElementsAs(ctx, &segmentKeys, false)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
+	_, err := r.config.Client.UpdateFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString(), func(flag *client.Flag) error {
+		i := findRule(flag.Rules, data.ID.ValueString(), segmentKeys, data.SegmentOperator.ValueString(), data.Rank.ValueInt64())
+		if i < 0 {
+			return errNotInParent
+		}
+
+		// Update the flag without the deleted rule
+		flag.Rules = append(flag.Rules[:i], flag.Rules[i+1:]...)
+		return nil
+	})
 	if err != nil {
-		// If flag doesn't exist, rule is already gone
+		if errors.Is(err, client.ErrNotFound) || errors.Is(err, errNotInParent) {
+			// Flag or rule doesn't exist, rule is already gone
+			return
+		}
+		addParentWriteError(&resp.Diagnostics, "delete rule", "flag", data.FlagKey.ValueString(), err)
 		return
 	}
-	defer httpResp.Body.Close()
 
-	if httpResp.StatusCode == http.StatusNotFound {
-		// Flag doesn't exist, rule is already gone
-		return
-	}
+	tflog.Trace(ctx, "deleted a rule resource")
+}
 
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read flag, status: %d, body: %s", httpResp.StatusCode, string(body)))
+// ImportState imports a rule by an ID of the form
+// environment_key/namespace_key/flag_key/rule_index.
+func (r *RuleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts, ok := parseImportID(req, resp, "environment_key", "namespace_key", "flag_key", "rule_index")
+	if !ok {
 		return
 	}
-
-	var flagResponse struct {
-		Resource struct {
-			Payload struct {
-				Type           string                   `json:"type"`
-				Key            string                   `json:"key"`
-				Name           string                   `json:"name"`
-				Description    string                   `json:"description"`
-				Enabled        bool                     `json:"enabled"`
-				Variants       []map[string]interface{} `json:"variants"`
-				Rules          []map[string]interface{} `json:"rules"`
-				DefaultVariant string                   `json:"defaultVariant"`
-				Metadata       map[string]interface{}   `json:"metadata"`
-			} `json:"payload"`
-		} `json:"resource"`
-	}
-
-	body, _ := io.ReadAll(httpResp.Body)
-	if err := json.Unmarshal(body, &flagResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse flag response: %s", err))
+	rank, ok := parseImportIndex(resp, "rule_index", parts[3])
+	if !ok {
 		return
 	}
 
-	// Remove the rule from the rules array
-	existingRules := flagResponse.Resource.Payload.Rules
-	if existingRules == nil {
-		// No rules, already deleted
-		return
-	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("environment_key"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("namespace_key"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("flag_key"), parts[2])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("rank"), rank)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), fmt.Sprintf("%s/%d", parts[2], rank))...)
+}
 
-	var updatedRules []map[string]interface{}
-	for _, rule := range existingRules {
-		if id, ok := rule["id"].(string); ok && id != data.ID.ValueString() {
-			updatedRules = append(updatedRules, rule)
+// findRule returns the index of the rule with the given ID, or -1 if there is
+// none. Rules without a stored ID, such as rules created before IDs were
+// stored or by a server that does not keep them, are matched by their segments
+// and operator instead, preferring the one with the given rank when several
+// match. IDs of the form flag_key/rank from older states may match any rule
+// this way.
+func findRule(rules []client.Rule, id string, segments []string, operator string, rank int64) int {
+	for i, rule := range rules {
+		if rule.ID != "" && rule.ID == id {
+			return i
 		}
 	}
 
-	// Update the flag without the deleted rule
-	flagPayload := map[string]interface{}{
-		"@type":          "flipt.core.Flag",
-		"key":            flagResponse.Resource.Payload.Key,
-		"name":           flagResponse.Resource.Payload.Name,
-		"description":    flagResponse.Resource.Payload.Description,
-		"type":           flagResponse.Resource.Payload.Type,
-		"enabled":        flagResponse.Resource.Payload.Enabled,
-		"variants":       flagResponse.Resource.Payload.Variants,
-		"rules":          updatedRules,
-		"defaultVariant": flagResponse.Resource.Payload.DefaultVariant,
-		"metadata":       flagResponse.Resource.Payload.Metadata,
-	}
+	_, err := uuid.Parse(id)
+	legacy := err != nil
 
-	updateReq := map[string]interface{}{
-		"key":     data.FlagKey.ValueString(),
-		"payload": flagPayload,
+	match := -1
+	for i, rule := range rules {
+		if rule.ID != "" && !legacy {
+			// The rule belongs to another resource
+			continue
+		}
+		if rule.SegmentOperator != operator || !slices.Equal(rule.Segments, segments) {
+			continue
+		}
+		if rule.Rank == rank {
+			return i
+		}
+		if match < 0 {
+			match = i
+		}
 	}
+	return match
+}
 
-	reqBody, err := json.Marshal(updateReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Serialization Error", fmt.Sprintf("Unable to marshal request: %s", err))
-		return
+// insertRule inserts a rule after the last rule whose rank is not greater
+// than its own, so that rules stay ordered by rank and a rule is added after
+// those sharing its rank.
+func insertRule(rules []client.Rule, rule client.Rule) []client.Rule {
+	i := len(rules)
+	for i > 0 && rules[i-1].Rank > rule.Rank {
+		i--
 	}
+	return slices.Insert(rules, i, rule)
+}
 
-	updateURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources", r.config.Endpoint, envKey, data.NamespaceKey.ValueString())
-	httpReq, err = http.NewRequestWithContext(ctx, "PUT", updateURL, bytes.NewReader(reqBody))
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
+// nextRuleRank returns the rank that appends a rule to the given rules.
+func nextRuleRank(rules []client.Rule) int64 {
+	var rank int64
+	for _, rule := range rules {
+		if rule.Rank >= rank {
+			rank = rule.Rank + 1
+		}
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
+	return rank
+}
 
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err = r.config.HTTPClient.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete rule, got error: %s", err))
-		return
+// findRuleByID returns the index of the rule referenced by a flipt_rule id,
+// or -1 if there is none. Rules are matched by their server-side ID first and
+// by the position in ids of the form flag_key/rank otherwise.
+func findRuleByID(rules []client.Rule, flagKey, ruleID string) int {
+	for i, rule := range rules {
+		if rule.ID != "" && rule.ID == ruleID {
+			return i
+		}
 	}
-	defer httpResp.Body.Close()
 
-	body, _ = io.ReadAll(httpResp.Body)
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to delete rule, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
+	rank, ok := strings.CutPrefix(ruleID, flagKey+"/")
+	if !ok {
+		return -1
 	}
-
-	tflog.Trace(ctx, "deleted a rule resource")
-}
-
-func (r *RuleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	n, err := strconv.ParseInt(rank, 10, 64)
+	if err != nil || n < 0 || n >= int64(len(rules)) {
+		return -1
+	}
+	return int(n)
 }
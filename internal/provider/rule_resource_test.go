@@ -4,12 +4,19 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strconv"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"terraform-provider-flipt/internal/client"
 )
 
 func TestAccRuleResource(t *testing.T) {
@@ -25,19 +32,59 @@ func TestAccRuleResource(t *testing.T) {
 					resource.TestCheckResourceAttr("flipt_rule.test", "namespace_key", "test-namespace"),
 					resource.TestCheckResourceAttr("flipt_rule.test", "flag_key", "test-flag"),
 					resource.TestCheckResourceAttr("flipt_rule.test", "segment_operator", "OR_SEGMENT_OPERATOR"),
+					resource.TestCheckResourceAttr("flipt_rule.test", "rank", "1"),
+					resource.TestCheckResourceAttrSet("flipt_rule.test", "id"),
+					testAccCheckRuleIDStored("flipt_rule.test"),
 				),
 			},
+			// ImportState testing, by the position of the rule in the flag
+			{
+				ResourceName:      "flipt_rule.test",
+				ImportState:       true,
+				ImportStateId:     "default/test-namespace/test-flag/0",
+				ImportStateVerify: true,
+			},
 			// Update and Read testing
 			{
 				Config: testAccRuleResourceConfig("default", "test-namespace", "test-flag", "test-segment", "AND_SEGMENT_OPERATOR"),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("flipt_rule.test", "segment_operator", "AND_SEGMENT_OPERATOR"),
+					testAccCheckRuleIDStored("flipt_rule.test"),
 				),
 			},
 		},
 	})
 }
 
+// testAccCheckRuleIDStored checks that Flipt kept the ID the provider stored
+// with a rule, which identifies the rule independently of its rank.
+func testAccCheckRuleIDStored(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("resource %s not found in state", name)
+		}
+		attrs := rs.Primary.Attributes
+
+		c := client.New(client.Config{Endpoint: getTestFliptEndpoint()})
+		flag, err := c.GetFlag(context.Background(), attrs["environment_key"], attrs["namespace_key"], attrs["flag_key"])
+		if err != nil {
+			return fmt.Errorf("unable to read flag %s: %w", attrs["flag_key"], err)
+		}
+
+		for _, rule := range flag.Rules {
+			if rule.ID != attrs["id"] {
+				continue
+			}
+			if got := strconv.FormatInt(rule.Rank, 10); got != attrs["rank"] {
+				return fmt.Errorf("expected rule %s to have rank %s, got %s", attrs["id"], attrs["rank"], got)
+			}
+			return nil
+		}
+		return fmt.Errorf("flag %s has no rule with ID %s", attrs["flag_key"], attrs["id"])
+	}
+}
+
 func testAccRuleResourceConfig(envKey, namespaceKey, flagKey, segmentKey, operator string) string {
 	return `
 provider "flipt" {
@@ -72,7 +119,7 @@ resource "flipt_rule" "test" {
   flag_key         = flipt_flag.test.key
   segment_keys     = [flipt_segment.test.key]
   segment_operator = "` + operator + `"
-  rank             = 0
+  rank             = 1
 }
 `
 }
@@ -151,3 +198,320 @@ func TestRuleResourceHTTP(t *testing.T) {
 		t.Fatal("Expected server URL to be set")
 	}
 }
+
+func TestRuleResourceReadImported(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+		"name":    "Checkout",
+		"type":    "VARIANT_FLAG_TYPE",
+		"enabled": true,
+		"rules": []interface{}{
+			map[string]interface{}{"segments": []interface{}{"everyone"}, "segmentOperator": "OR_SEGMENT_OPERATOR", "rank": 0},
+			map[string]interface{}{"segments": []interface{}{"beta", "internal"}, "segmentOperator": "AND_SEGMENT_OPERATOR", "rank": 1},
+		},
+	})
+
+	r := NewRuleResource()
+	configureResource(t, r, fake.config())
+
+	// Imported state only knows the keys and the rule index
+	resp, state := readResource(t, r, &RuleResourceModel{
+		NamespaceKey:    types.StringValue("default"),
+		EnvironmentKey:  types.StringValue("default"),
+		FlagKey:         types.StringValue("checkout"),
+		ID:              types.StringValue("checkout/1"),
+		SegmentKeys:     types.ListNull(types.StringType),
+		SegmentOperator: types.StringNull(),
+		Rank:            types.Int64Value(1),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read failed: %v", resp.Diagnostics)
+	}
+
+	var data RuleResourceModel
+	state.Get(context.Background(), &data)
+	var segmentKeys []string
+	data.SegmentKeys.ElementsAs(context.Background(), &segmentKeys, false)
+	if len(segmentKeys) != 2 || segmentKeys[0] != "beta" || data.SegmentOperator.ValueString() != "AND_SEGMENT_OPERATOR" {
+		t.Errorf("Unexpected rule in state: %v %s", segmentKeys, data.SegmentOperator)
+	}
+}
+
+func TestRuleResourceIdentity(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+		"name":    "Checkout",
+		"type":    "VARIANT_FLAG_TYPE",
+		"enabled": true,
+	})
+
+	r := NewRuleResource()
+	configureResource(t, r, fake.config())
+
+	model := func(segments ...string) *RuleResourceModel {
+		segmentKeys, _ := types.ListValueFrom(context.Background(), types.StringType, segments)
+		return &RuleResourceModel{
+			NamespaceKey:    types.StringValue("default"),
+			EnvironmentKey:  types.StringValue("default"),
+			FlagKey:         types.StringValue("checkout"),
+			ID:              types.StringUnknown(),
+			SegmentKeys:     segmentKeys,
+			SegmentOperator: types.StringValue("OR_SEGMENT_OPERATOR"),
+			Rank:            types.Int64Unknown(),
+		}
+	}
+	create := func(segments ...string) RuleResourceModel {
+		resp, state := createResource(t, r, model(segments...))
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("Create failed: %v", resp.Diagnostics)
+		}
+		var data RuleResourceModel
+		state.Get(context.Background(), &data)
+		return data
+	}
+
+	beta := create("beta")
+	everyone := create("everyone")
+	if beta.Rank.ValueInt64() != 0 || everyone.Rank.ValueInt64() != 1 {
+		t.Fatalf("Expected ranks 0 and 1, got %d and %d", beta.Rank.ValueInt64(), everyone.Rank.ValueInt64())
+	}
+
+	// Reorder the rules and change the segments of one outside of Terraform
+	rules := fake.get("default", "default", client.TypeFlag, "checkout")["rules"].([]interface{})
+	rules[0], rules[1] = rules[1], rules[0]
+	rules[1].(map[string]interface{})["segments"] = []interface{}{"beta", "internal"}
+
+	resp, state := readResource(t, r, &beta)
+	if resp.Diagnostics.HasError() || state.Raw.IsNull() {
+		t.Fatalf("Read failed: %v", resp.Diagnostics)
+	}
+	var data RuleResourceModel
+	state.Get(context.Background(), &data)
+	var segmentKeys []string
+	data.SegmentKeys.ElementsAs(context.Background(), &segmentKeys, false)
+	if data.ID != beta.ID || data.Rank.ValueInt64() != 0 || len(segmentKeys) != 2 {
+		t.Errorf("Unexpected rule in state: id=%s rank=%d segments=%v", data.ID, data.Rank.ValueInt64(), segmentKeys)
+	}
+
+	// Changing the rank moves the rule after the rules with lower ranks
+	plan := model("beta")
+	plan.ID = beta.ID
+	plan.Rank = types.Int64Value(5)
+	if resp, _ := updateResource(t, r, plan, &data); resp.Diagnostics.HasError() {
+		t.Fatalf("Update failed: %v", resp.Diagnostics)
+	}
+	rules = fake.get("default", "default", client.TypeFlag, "checkout")["rules"].([]interface{})
+	if len(rules) != 2 || rules[1].(map[string]interface{})["id"] != beta.ID.ValueString() {
+		t.Fatalf("Expected rule %s last, got %v", beta.ID, rules)
+	}
+
+	// Deleting a rule removes only that rule
+	everyone.Rank = types.Int64Value(1)
+	if resp := deleteResource(t, r, &everyone); resp.Diagnostics.HasError() {
+		t.Fatalf("Delete failed: %v", resp.Diagnostics)
+	}
+	rules = fake.get("default", "default", client.TypeFlag, "checkout")["rules"].([]interface{})
+	if len(rules) != 1 || rules[0].(map[string]interface{})["id"] != beta.ID.ValueString() {
+		t.Errorf("Expected only rule %s to remain, got %v", beta.ID, rules)
+	}
+}
+
+func TestFindRule(t *testing.T) {
+	rules := []client.Rule{
+		{Segments: []string{"beta"}, SegmentOperator: "OR_SEGMENT_OPERATOR", Rank: 1},
+		{ID: "6f1c9f3e-5b0e-4d8a-9a51-2f6c1f0d8b11", Segments: []string{"beta"}, SegmentOperator: "OR_SEGMENT_OPERATOR", Rank: 2},
+		{Segments: []string{"beta"}, SegmentOperator: "OR_SEGMENT_OPERATOR", Rank: 3},
+	}
+
+	tests := []struct {
+		name   string
+		id     string
+		rank   int64
+		expect int
+	}{
+		{name: "stored id", id: "6f1c9f3e-5b0e-4d8a-9a51-2f6c1f0d8b11", rank: 1, expect: 1},
+		{name: "rule without id with rank", id: "0b7e2f4a-1c3d-4e5f-8a9b-c0d1e2f3a4b5", rank: 3, expect: 2},
+		{name: "rule without id with another rank", id: "0b7e2f4a-1c3d-4e5f-8a9b-c0d1e2f3a4b5", rank: 2, expect: 0},
+		{name: "legacy id", id: "checkout/2", rank: 2, expect: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findRule(rules, tt.id, []string{"beta"}, "OR_SEGMENT_OPERATOR", tt.rank); got != tt.expect {
+				t.Errorf("Expected rule %d, got %d", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestRuleResourceIDNotKept(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+		"name":    "Checkout",
+		"type":    "VARIANT_FLAG_TYPE",
+		"enabled": true,
+	})
+
+	r := NewRuleResource()
+	configureResource(t, r, fake.config())
+
+	segmentKeys, _ := types.ListValueFrom(context.Background(), types.StringType, []string{"beta"})
+	plan := &RuleResourceModel{
+		NamespaceKey:    types.StringValue("default"),
+		EnvironmentKey:  types.StringValue("default"),
+		FlagKey:         types.StringValue("checkout"),
+		ID:              types.StringUnknown(),
+		SegmentKeys:     segmentKeys,
+		SegmentOperator: types.StringValue("OR_SEGMENT_OPERATOR"),
+		Rank:            types.Int64Unknown(),
+	}
+	resp, state := createResource(t, r, plan)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Create failed: %v", resp.Diagnostics)
+	}
+	var created RuleResourceModel
+	state.Get(context.Background(), &created)
+
+	// Drop the stored ID as a server that does not keep it would
+	dropIDs := func() {
+		for _, rule := range fake.get("default", "default", client.TypeFlag, "checkout")["rules"].([]interface{}) {
+			delete(rule.(map[string]interface{}), "id")
+		}
+	}
+	dropIDs()
+
+	readResp, state := readResource(t, r, &created)
+	if readResp.Diagnostics.HasError() || state.Raw.IsNull() {
+		t.Fatalf("Expected the rule to be found by its content, got %v", readResp.Diagnostics)
+	}
+	var data RuleResourceModel
+	state.Get(context.Background(), &data)
+	if data.ID != created.ID {
+		t.Errorf("Expected ID %s to be kept, got %s", created.ID, data.ID)
+	}
+
+	// Updates keep the ID in state
+	plan.ID = created.ID
+	plan.SegmentOperator = types.StringValue("OR_SEGMENT_OPERATOR")
+	plan.Rank = types.Int64Value(0)
+	updateResp, state := updateResource(t, r, plan, &data)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("Update failed: %v", updateResp.Diagnostics)
+	}
+	state.Get(context.Background(), &data)
+	if data.ID != created.ID {
+		t.Errorf("Expected ID %s after update, got %s", created.ID, data.ID)
+	}
+	dropIDs()
+
+	if resp := deleteResource(t, r, &data); resp.Diagnostics.HasError() {
+		t.Fatalf("Delete failed: %v", resp.Diagnostics)
+	}
+	if rules, _ := fake.get("default", "default", client.TypeFlag, "checkout")["rules"].([]interface{}); len(rules) != 0 {
+		t.Errorf("Expected the rule to be deleted, got %v", rules)
+	}
+}
+
+func TestRuleResourceRank(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+		"name":    "Checkout",
+		"type":    "VARIANT_FLAG_TYPE",
+		"enabled": true,
+		"rules": []interface{}{
+			map[string]interface{}{"id": "rule-1", "segments": []interface{}{"everyone"}, "segmentOperator": "OR_SEGMENT_OPERATOR", "rank": 1},
+			map[string]interface{}{"id": "rule-10", "segments": []interface{}{"internal"}, "segmentOperator": "OR_SEGMENT_OPERATOR", "rank": 10},
+		},
+	})
+
+	r := NewRuleResource()
+	configureResource(t, r, fake.config())
+
+	model := func(segment string, rank types.Int64) *RuleResourceModel {
+		segmentKeys, _ := types.ListValueFrom(context.Background(), types.StringType, []string{segment})
+		return &RuleResourceModel{
+			NamespaceKey:    types.StringValue("default"),
+			EnvironmentKey:  types.StringValue("default"),
+			FlagKey:         types.StringValue("checkout"),
+			ID:              types.StringUnknown(),
+			SegmentKeys:     segmentKeys,
+			SegmentOperator: types.StringValue("OR_SEGMENT_OPERATOR"),
+			Rank:            rank,
+		}
+	}
+	create := func(plan *RuleResourceModel) RuleResourceModel {
+		t.Helper()
+		resp, state := createResource(t, r, plan)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("Create failed: %v", resp.Diagnostics)
+		}
+		var data RuleResourceModel
+		state.Get(context.Background(), &data)
+		return data
+	}
+	order := func() []string {
+		var segments []string
+		for _, rule := range storedRules(t, fake) {
+			segments = append(segments, rule.Segments[0])
+		}
+		return segments
+	}
+
+	// Ranks order the rules without being positions
+	beta := create(model("beta", types.Int64Value(5)))
+	first := create(model("first", types.Int64Value(0)))
+	last := create(model("last", types.Int64Unknown()))
+	if beta.Rank.ValueInt64() != 5 || first.Rank.ValueInt64() != 0 || last.Rank.ValueInt64() != 11 {
+		t.Fatalf("Expected ranks 5, 0 and 11, got %s, %s and %s", beta.Rank, first.Rank, last.Rank)
+	}
+	if got := order(); !reflect.DeepEqual(got, []string{"first", "everyone", "beta", "internal", "last"}) {
+		t.Fatalf("Unexpected order of rules: %v", got)
+	}
+
+	// Read keeps the configured rank
+	resp, state := readResource(t, r, &beta)
+	if resp.Diagnostics.HasError() || state.Raw.IsNull() {
+		t.Fatalf("Read failed: %v", resp.Diagnostics)
+	}
+	var data RuleResourceModel
+	state.Get(context.Background(), &data)
+	if data.Rank.ValueInt64() != 5 {
+		t.Errorf("Expected rank 5 in state, got %s", data.Rank)
+	}
+
+	// Changing the rank moves the rule among the others
+	updateResp, _ := updateResource(t, r, model("beta", types.Int64Value(20)), &data)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("Update failed: %v", updateResp.Diagnostics)
+	}
+	if got := order(); !reflect.DeepEqual(got, []string{"first", "everyone", "internal", "last", "beta"}) {
+		t.Fatalf("Unexpected order of rules after update: %v", got)
+	}
+
+	// Deleting a rule leaves the ranks of the others alone
+	if resp := deleteResource(t, r, &first); resp.Diagnostics.HasError() {
+		t.Fatalf("Delete failed: %v", resp.Diagnostics)
+	}
+	var ranks []int64
+	for _, rule := range storedRules(t, fake) {
+		ranks = append(ranks, rule.Rank)
+	}
+	if !reflect.DeepEqual(ranks, []int64{1, 10, 11, 20}) {
+		t.Errorf("Unexpected ranks after delete: %v", ranks)
+	}
+}
+
+// storedRules returns the rules of the checkout flag on the fake server.
+func storedRules(t *testing.T, fake *fakeFlipt) []client.Rule {
+	t.Helper()
+
+	raw, err := json.Marshal(fake.get("default", "default", client.TypeFlag, "checkout")["rules"])
+	if err != nil {
+		t.Fatalf("Unable to marshal rules: %v", err)
+	}
+	var rules []client.Rule
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		t.Fatalf("Unable to unmarshal rules: %v", err)
+	}
+	return rules
+}
@@ -0,0 +1,151 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
+)
+
+var _ datasource.DataSource = &SegmentConstraintsDataSource{}
+
+func NewSegmentConstraintsDataSource() datasource.DataSource {
+	return &SegmentConstraintsDataSource{}
+}
+
+type SegmentConstraintsDataSource struct {
+	config *FliptProviderConfig
+}
+
+type SegmentConstraintsDataSourceModel struct {
+	NamespaceKey   types.String `tfsdk:"namespace_key"`
+	EnvironmentKey types.String `tfsdk:"environment_key"`
+	SegmentKey     types.String `tfsdk:"segment_key"`
+	MatchType      types.String `tfsdk:"match_type"`
+	Constraints    types.Set    `tfsdk:"constraints"`
+}
+
+func (d *SegmentConstraintsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_segment_constraints"
+}
+
+func (d *SegmentConstraintsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reads the constraints of a segment, in the shape taken by the `constraints` of `flipt_segment_constraints`",
+		Description:         "Reads the constraints of a segment",
+
+		Attributes: map[string]schema.Attribute{
+			"namespace_key": schema.StringAttribute{
+				MarkdownDescription: "Namespace key where the segment belongs. Defaults to the provider's `default_namespace_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+			},
+			"environment_key": schema.StringAttribute{
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+			},
+			"segment_key": schema.StringAttribute{
+				MarkdownDescription: "Key of the segment whose constraints are read",
+				Required:            true,
+			},
+			"match_type": schema.StringAttribute{
+				MarkdownDescription: "Match type of the segment (ALL_MATCH_TYPE or ANY_MATCH_TYPE), whether all or any of the constraints must match",
+				Computed:            true,
+			},
+			"constraints": schema.SetNestedAttribute{
+				MarkdownDescription: "Constraints of the segment",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"property": schema.StringAttribute{
+							MarkdownDescription: "Property name for the constraint",
+							Computed:            true,
+						},
+						"type": schema.StringAttribute{
+							MarkdownDescription: "Constraint type: STRING_COMPARISON_TYPE, NUMBER_COMPARISON_TYPE, BOOLEAN_COMPARISON_TYPE, DATETIME_COMPARISON_TYPE or ENTITY_ID_COMPARISON_TYPE",
+							Computed:            true,
+						},
+						"operator": schema.StringAttribute{
+							MarkdownDescription: "Comparison operator",
+							Computed:            true,
+						},
+						"value": schema.StringAttribute{
+							MarkdownDescription: "Value to compare against, null for the operators that take no value",
+							Computed:            true,
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "Description of the constraint",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *SegmentConstraintsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerConfig, ok := req.ProviderData.(*FliptProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *FliptProviderConfig, got: %T", req.ProviderData),
+		)
+		return
+	}
+
+	d.config = providerConfig
+}
+
+func (d *SegmentConstraintsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data SegmentConstraintsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the data source
+	envKey := d.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(d.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Reading segment constraints", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"segment_key":     data.SegmentKey.ValueString(),
+	})
+
+	segment, err := d.config.Client.GetSegment(ctx, envKey, data.NamespaceKey.ValueString(), data.SegmentKey.ValueString())
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			resp.Diagnostics.AddError("Not Found", fmt.Sprintf("Segment with key '%s' not found in namespace '%s'", data.SegmentKey.ValueString(), data.NamespaceKey.ValueString()))
+			return
+		}
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read segment, got error: %s", err))
+		return
+	}
+
+	constraints, diags := constraintsToModel(ctx, segment.Constraints)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.MatchType = types.StringValue(segment.MatchType)
+	data.Constraints = constraints
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
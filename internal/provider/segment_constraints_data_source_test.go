@@ -0,0 +1,144 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"terraform-provider-flipt/internal/client"
+)
+
+func TestAccSegmentConstraintsDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSegmentConstraintsDataSourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.flipt_segment_constraints.test", "match_type", "ANY_MATCH_TYPE"),
+					resource.TestCheckResourceAttr("data.flipt_segment_constraints.test", "constraints.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.flipt_segment_constraints.test", "constraints.*", map[string]string{
+						"property":    "email",
+						"type":        "STRING_COMPARISON_TYPE",
+						"operator":    "suffix",
+						"value":       "@example.com",
+						"description": "Employees",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("data.flipt_segment_constraints.test", "constraints.*", map[string]string{
+						"property": "beta",
+						"type":     "BOOLEAN_COMPARISON_TYPE",
+						"operator": "true",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func testAccSegmentConstraintsDataSourceConfig() string {
+	return `
+provider "flipt" {
+  endpoint = "` + getTestFliptEndpoint() + `"
+}
+
+resource "flipt_namespace" "test" {
+  key           = "segment-constraints-data-source"
+  name          = "Segment Constraints Data Source"
+  force_destroy = true
+}
+
+resource "flipt_segment" "test" {
+  namespace_key = flipt_namespace.test.key
+  key           = "testers"
+  name          = "Testers"
+  match_type    = "ANY_MATCH_TYPE"
+}
+
+resource "flipt_segment_constraints" "test" {
+  namespace_key = flipt_namespace.test.key
+  segment_key   = flipt_segment.test.key
+  constraints = [
+    {
+      property    = "email"
+      type        = "STRING_COMPARISON_TYPE"
+      operator    = "suffix"
+      value       = "@example.com"
+      description = "Employees"
+    },
+    {
+      property = "beta"
+      type     = "BOOLEAN_COMPARISON_TYPE"
+      operator = "true"
+    },
+  ]
+}
+
+data "flipt_segment_constraints" "test" {
+  namespace_key = flipt_namespace.test.key
+  segment_key   = flipt_segment.test.key
+  depends_on    = [flipt_segment_constraints.test]
+}
+`
+}
+
+func TestSegmentConstraintsDataSource(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "team-a", client.TypeSegment, "testers", map[string]interface{}{
+		"name": "Testers", "matchType": "ANY_MATCH_TYPE",
+		"constraints": []interface{}{
+			map[string]interface{}{"type": "STRING_COMPARISON_TYPE", "property": "email", "operator": "suffix", "value": "@example.com", "description": "Employees"},
+			map[string]interface{}{"type": "BOOLEAN_COMPARISON_TYPE", "property": "beta", "operator": "true"},
+		},
+	})
+
+	d := NewSegmentConstraintsDataSource()
+	configureDataSource(t, d, fake.config())
+
+	resp, state := readDataSource(t, d, map[string]tftypes.Value{
+		"namespace_key": tftypes.NewValue(tftypes.String, "team-a"),
+		"segment_key":   tftypes.NewValue(tftypes.String, "testers"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read failed: %v", resp.Diagnostics)
+	}
+
+	var data SegmentConstraintsDataSourceModel
+	if diags := state.Get(context.Background(), &data); diags.HasError() {
+		t.Fatalf("Unable to read state: %v", diags)
+	}
+	if data.MatchType.ValueString() != "ANY_MATCH_TYPE" {
+		t.Errorf("Expected match type ANY_MATCH_TYPE, got %s", data.MatchType)
+	}
+
+	var constraints []segmentConstraintModel
+	if diags := data.Constraints.ElementsAs(context.Background(), &constraints, false); diags.HasError() {
+		t.Fatalf("Unable to read constraints: %v", diags)
+	}
+	if len(constraints) != 2 {
+		t.Fatalf("Expected 2 constraints, got %d", len(constraints))
+	}
+	for _, c := range constraints {
+		switch c.Property.ValueString() {
+		case "email":
+			if c.Operator.ValueString() != "suffix" || c.Value.ValueString() != "@example.com" || c.Description.ValueString() != "Employees" {
+				t.Errorf("Unexpected constraint %+v", c)
+			}
+		case "beta":
+			if c.Type.ValueString() != "BOOLEAN_COMPARISON_TYPE" || !c.Value.IsNull() || !c.Description.IsNull() {
+				t.Errorf("Unexpected constraint %+v", c)
+			}
+		default:
+			t.Errorf("Unexpected constraint %+v", c)
+		}
+	}
+
+	resp, _ = readDataSource(t, d, map[string]tftypes.Value{"segment_key": tftypes.NewValue(tftypes.String, "missing")})
+	if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != "Not Found" {
+		t.Errorf("Expected a Not Found error, got %v", resp.Diagnostics)
+	}
+}
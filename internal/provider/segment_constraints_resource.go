@@ -0,0 +1,469 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
+)
+
+var _ resource.Resource = &SegmentConstraintsResource{}
+var _ resource.ResourceWithImportState = &SegmentConstraintsResource{}
+var _ resource.ResourceWithModifyPlan = &SegmentConstraintsResource{}
+var _ resource.ResourceWithValidateConfig = &SegmentConstraintsResource{}
+var _ resource.ResourceWithMoveState = &SegmentConstraintsResource{}
+
+func NewSegmentConstraintsResource() resource.Resource {
+	return &SegmentConstraintsResource{}
+}
+
+// SegmentConstraintsResource manages the complete set of constraints of a
+// segment.
+type SegmentConstraintsResource struct {
+	config *FliptProviderConfig
+}
+
+type SegmentConstraintsResourceModel struct {
+	NamespaceKey   types.String `tfsdk:"namespace_key"`
+	EnvironmentKey types.String `tfsdk:"environment_key"`
+	SegmentKey     types.String `tfsdk:"segment_key"`
+	Constraints    types.Set    `tfsdk:"constraints"`
+	ID             types.String `tfsdk:"id"`
+}
+
+// segmentConstraintModel describes a constraint in the constraints set of
+// flipt_segment_constraints.
+type segmentConstraintModel struct {
+	Property    types.String `tfsdk:"property"`
+	Type        types.String `tfsdk:"type"`
+	Operator    types.String `tfsdk:"operator"`
+	Value       types.String `tfsdk:"value"`
+	Description types.String `tfsdk:"description"`
+}
+
+var segmentConstraintAttrTypes = map[string]attr.Type{
+	"property":    types.StringType,
+	"type":        types.StringType,
+	"operator":    types.StringType,
+	"value":       types.StringType,
+	"description": types.StringType,
+}
+
+func (r *SegmentConstraintsResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_segment_constraints"
+}
+
+func (r *SegmentConstraintsResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Flipt segment constraints resource (the complete set of constraints of a segment). " +
+			"Constraints added outside of this resource are removed on the next apply, so it must not be combined with `flipt_constraint` for the same segment",
+
+		Attributes: map[string]schema.Attribute{
+			"namespace_key": schema.StringAttribute{
+				MarkdownDescription: "Namespace key where the segment belongs. Defaults to the provider's `default_namespace_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"environment_key": schema.StringAttribute{
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"segment_key": schema.StringAttribute{
+				MarkdownDescription: "Key of the segment whose constraints are managed",
+				Required:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"constraints": schema.SetNestedAttribute{
+				MarkdownDescription: "Constraints of the segment. Each combination of property, operator and value may appear once",
+				Required:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"property": schema.StringAttribute{
+							MarkdownDescription: "Property name for the constraint",
+							Required:            true,
+						},
+						"type": schema.StringAttribute{
+							MarkdownDescription: "Constraint type: STRING_COMPARISON_TYPE, NUMBER_COMPARISON_TYPE, BOOLEAN_COMPARISON_TYPE, DATETIME_COMPARISON_TYPE or ENTITY_ID_COMPARISON_TYPE",
+							Required:            true,
+							Validators: []validator.String{
+								constraintTypeValidator(),
+							},
+						},
+						"operator": schema.StringAttribute{
+							MarkdownDescription: "Comparison operator. The operators available depend on the type (e.g., eq, neq, prefix, suffix, isoneof, lt, gte, present, empty, true)",
+							Required:            true,
+						},
+						"value": schema.StringAttribute{
+							MarkdownDescription: "Value to compare against. Omitted for the operators that take no value: empty, notempty, present, notpresent, true and false",
+							Optional:            true,
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "Description of the constraint",
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Identifier of the constraints, the segment key",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *SegmentConstraintsResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerConfig, ok := req.ProviderData.(*FliptProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *FliptProviderConfig, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.config = providerConfig
+}
+
+func (r *SegmentConstraintsResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var constraints types.Set
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("constraints"), &constraints)...)
+	if resp.Diagnostics.HasError() || constraints.IsNull() || constraints.IsUnknown() {
+		return
+	}
+
+	// Constraints that differ only in type or description would overwrite
+	// each other in the segment
+	seen := make(map[string]bool)
+	for _, elem := range constraints.Elements() {
+		obj, ok := elem.(types.Object)
+		if !ok || obj.IsUnknown() {
+			continue
+		}
+		var m segmentConstraintModel
+		resp.Diagnostics.Append(obj.As(ctx, &m, basetypes.ObjectAsOptions{})...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		validateConstraint(&resp.Diagnostics, path.Root("constraints").AtSetValue(elem), m.Type, m.Operator, m.Value)
+
+		if m.Property.IsUnknown() || m.Operator.IsUnknown() || m.Value.IsUnknown() {
+			continue
+		}
+		id := constraintID(client.Constraint{Property: m.Property.ValueString(), Operator: m.Operator.ValueString(), Value: m.Value.ValueString()})
+		if seen[id] {
+			resp.Diagnostics.AddAttributeError(path.Root("constraints"), "Duplicate Constraint",
+				fmt.Sprintf("The constraint %s appears more than once. Each combination of property, operator and value may appear once.", id))
+		}
+		seen[id] = true
+	}
+}
+
+func (r *SegmentConstraintsResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultKeys(ctx, r.config, true, req, resp)
+}
+
+func (r *SegmentConstraintsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data SegmentConstraintsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Creating segment constraints", map[string]interface{}{
+		"environment_key":   envKey,
+		"namespace_key":     data.NamespaceKey.ValueString(),
+		"segment_key":       data.SegmentKey.ValueString(),
+		"constraints_count": len(data.Constraints.Elements()),
+	})
+
+	// Serialize with other changes to the segment's constraints
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeSegment, data.SegmentKey.ValueString())
+	defer unlock()
+
+	if r.writeConstraints(ctx, &resp.Diagnostics, "create segment constraints", envKey, data) {
+		return
+	}
+
+	data.ID = types.StringValue(data.SegmentKey.ValueString())
+
+	tflog.Trace(ctx, "created a segment constraints resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SegmentConstraintsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data SegmentConstraintsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Reading segment constraints", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"segment_key":     data.SegmentKey.ValueString(),
+	})
+
+	segment, err := r.config.Client.GetSegment(ctx, envKey, data.NamespaceKey.ValueString(), data.SegmentKey.ValueString())
+	if err != nil {
+		handleReadError(ctx, resp, "segment", data.SegmentKey.ValueString(), err)
+		return
+	}
+
+	// All constraints of the segment are read, so that constraints added or
+	// changed outside of Terraform show up as drift
+	constraints, diags := constraintsToModel(ctx, segment.Constraints)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Constraints = constraints
+	data.ID = types.StringValue(data.SegmentKey.ValueString())
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SegmentConstraintsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data SegmentConstraintsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Updating segment constraints", map[string]interface{}{
+		"environment_key":   envKey,
+		"namespace_key":     data.NamespaceKey.ValueString(),
+		"segment_key":       data.SegmentKey.ValueString(),
+		"constraints_count": len(data.Constraints.Elements()),
+	})
+
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeSegment, data.SegmentKey.ValueString())
+	defer unlock()
+
+	if r.writeConstraints(ctx, &resp.Diagnostics, "update segment constraints", envKey, data) {
+		return
+	}
+
+	data.ID = types.StringValue(data.SegmentKey.ValueString())
+
+	tflog.Trace(ctx, "updated a segment constraints resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SegmentConstraintsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data SegmentConstraintsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Deleting segment constraints", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+		"segment_key":     data.SegmentKey.ValueString(),
+	})
+
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeSegment, data.SegmentKey.ValueString())
+	defer unlock()
+
+	_, err := r.config.Client.UpdateSegment(ctx, envKey, data.NamespaceKey.ValueString(), data.SegmentKey.ValueString(), func(segment *client.Segment) error {
+		segment.Constraints = nil
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			// Segment doesn't exist, its constraints are already gone
+			return
+		}
+		addParentWriteError(&resp.Diagnostics, "delete segment constraints", "segment", data.SegmentKey.ValueString(), err)
+		return
+	}
+
+	tflog.Trace(ctx, "deleted a segment constraints resource")
+}
+
+// ImportState imports the constraints of a segment by an ID of the form
+// environment_key/namespace_key/segment_key.
+func (r *SegmentConstraintsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts, ok := parseImportID(req, resp, "environment_key", "namespace_key", "segment_key")
+	if !ok {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("environment_key"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("namespace_key"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("segment_key"), parts[2])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), parts[2])...)
+}
+
+// MoveState takes over a flipt_constraint moved to this resource with a
+// moved block. The other constraints of the segment are read on the next
+// refresh, and show up as drift until they are added to the configuration.
+func (r *SegmentConstraintsResource) MoveState(ctx context.Context) []resource.StateMover {
+	constraintSchema := &resource.SchemaResponse{}
+	NewConstraintResource().Schema(ctx, resource.SchemaRequest{}, constraintSchema)
+
+	return []resource.StateMover{
+		{
+			SourceSchema: &constraintSchema.Schema,
+			StateMover: func(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+				if req.SourceTypeName != "flipt_constraint" || !strings.HasSuffix(req.SourceProviderAddress, "/flipt") {
+					return
+				}
+				if req.SourceState == nil {
+					resp.Diagnostics.AddError("Unable to Move Constraint",
+						fmt.Sprintf("The state of the flipt_constraint (schema version %d) could not be read. "+
+							"Run terraform apply with the current provider before moving it.", req.SourceSchemaVersion))
+					return
+				}
+
+				var source ConstraintResourceModel
+				resp.Diagnostics.Append(req.SourceState.Get(ctx, &source)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				constraints, diags := constraintsToModel(ctx, []client.Constraint{constraintFromModel(source)})
+				resp.Diagnostics.Append(diags...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				resp.Diagnostics.Append(resp.TargetState.Set(ctx, SegmentConstraintsResourceModel{
+					NamespaceKey:   source.NamespaceKey,
+					EnvironmentKey: source.EnvironmentKey,
+					SegmentKey:     source.SegmentKey,
+					Constraints:    constraints,
+					ID:             source.SegmentKey,
+				})...)
+			},
+		},
+	}
+}
+
+// writeConstraints replaces the constraints of the segment with the planned
+// constraints, and reports whether it failed.
+func (r *SegmentConstraintsResource) writeConstraints(ctx context.Context, diags *diag.Diagnostics, action, envKey string, data SegmentConstraintsResourceModel) bool {
+	constraints, d := constraintsFromModel(ctx, data.Constraints)
+	diags.Append(d...)
+	if diags.HasError() {
+		return true
+	}
+
+	_, err := r.config.Client.UpdateSegment(ctx, envKey, data.NamespaceKey.ValueString(), data.SegmentKey.ValueString(), func(segment *client.Segment) error {
+		segment.Constraints = constraints
+		return nil
+	})
+	if err != nil {
+		addParentWriteError(diags, action, "segment", data.SegmentKey.ValueString(), err)
+		return true
+	}
+	return false
+}
+
+// constraintsFromModel builds the constraints of a segment from the
+// constraints set of flipt_segment_constraints.
+func constraintsFromModel(ctx context.Context, set types.Set) ([]client.Constraint, diag.Diagnostics) {
+	var models []segmentConstraintModel
+	diags := set.ElementsAs(ctx, &models, false)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	constraints := make([]client.Constraint, 0, len(models))
+	for _, m := range models {
+		constraints = append(constraints, constraintFromModel(ConstraintResourceModel{
+			Property:    m.Property,
+			Type:        m.Type,
+			Operator:    m.Operator,
+			Value:       m.Value,
+			Description: m.Description,
+		}))
+	}
+	return constraints, diags
+}
+
+// constraintsToModel converts the constraints of a segment to the
+// constraints set of flipt_segment_constraints.
+func constraintsToModel(ctx context.Context, constraints []client.Constraint) (types.Set, diag.Diagnostics) {
+	models := make([]segmentConstraintModel, 0, len(constraints))
+	for _, c := range constraints {
+		m := segmentConstraintModel{
+			Property:    types.StringValue(c.Property),
+			Type:        types.StringValue(c.Type),
+			Operator:    types.StringValue(c.Operator),
+			Value:       types.StringNull(),
+			Description: types.StringNull(),
+		}
+		if c.Value != "" {
+			m.Value = types.StringValue(c.Value)
+		}
+		if c.Description != "" {
+			m.Description = types.StringValue(c.Description)
+		}
+		models = append(models, m)
+	}
+
+	return types.SetValueFrom(ctx, types.ObjectType{AttrTypes: segmentConstraintAttrTypes}, models)
+}
@@ -0,0 +1,334 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"terraform-provider-flipt/internal/client"
+)
+
+func TestAccSegmentConstraintsResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccSegmentConstraintsResourceConfig("65"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_segment_constraints.test", "id", "adults"),
+					resource.TestCheckResourceAttr("flipt_segment_constraints.test", "constraints.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs("flipt_segment_constraints.test", "constraints.*", map[string]string{
+						"property": "age",
+						"operator": "lte",
+						"value":    "65",
+					}),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "flipt_segment_constraints.test",
+				ImportState:       true,
+				ImportStateId:     "default/test-segment-constraints-ns/adults",
+				ImportStateVerify: true,
+			},
+			// Update and Read testing
+			{
+				Config: testAccSegmentConstraintsResourceConfig("70"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_segment_constraints.test", "constraints.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs("flipt_segment_constraints.test", "constraints.*", map[string]string{
+						"property": "age",
+						"operator": "lte",
+						"value":    "70",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func testAccSegmentConstraintsResourceConfig(maxAge string) string {
+	return fmt.Sprintf(`
+provider "flipt" {
+  endpoint = %[1]q
+}
+
+resource "flipt_namespace" "test" {
+  key  = "test-segment-constraints-ns"
+  name = "Test Namespace"
+}
+
+resource "flipt_segment" "test" {
+  namespace_key = flipt_namespace.test.key
+  key           = "adults"
+  name          = "Adults"
+  match_type    = "ALL_MATCH_TYPE"
+}
+
+resource "flipt_segment_constraints" "test" {
+  namespace_key = flipt_namespace.test.key
+  segment_key   = flipt_segment.test.key
+
+  constraints = [
+    {
+      property = "age"
+      type     = "NUMBER_COMPARISON_TYPE"
+      operator = "gte"
+      value    = "18"
+    },
+    {
+      property = "age"
+      type     = "NUMBER_COMPARISON_TYPE"
+      operator = "lte"
+      value    = %[2]q
+    },
+  ]
+}
+`, getTestFliptEndpoint(), maxAge)
+}
+
+func TestAccSegmentConstraintsResourceMoved(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			// Moving state between resource types needs Terraform 1.8
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSegmentConstraintsResourceMovedConfig(`
+resource "flipt_constraint" "email" {
+  namespace_key = flipt_namespace.test.key
+  segment_key   = flipt_segment.test.key
+  property      = "email"
+  type          = "STRING_COMPARISON_TYPE"
+  operator      = "suffix"
+  value         = "@example.com"
+}
+
+resource "flipt_constraint" "plan" {
+  namespace_key = flipt_namespace.test.key
+  segment_key   = flipt_segment.test.key
+  property      = "plan"
+  type          = "STRING_COMPARISON_TYPE"
+  operator      = "eq"
+  value         = "premium"
+}
+`),
+				Check: testAccCheckSegmentConstraintCount("test-segment-constraints-moved-ns", "beta-users", 2),
+			},
+			// One constraint is moved, the other is forgotten without being
+			// deleted, as the migration guide describes
+			{
+				Config: testAccSegmentConstraintsResourceMovedConfig(`
+resource "flipt_segment_constraints" "test" {
+  namespace_key = flipt_namespace.test.key
+  segment_key   = flipt_segment.test.key
+
+  constraints = [
+    {
+      property = "email"
+      type     = "STRING_COMPARISON_TYPE"
+      operator = "suffix"
+      value    = "@example.com"
+    },
+    {
+      property = "plan"
+      type     = "STRING_COMPARISON_TYPE"
+      operator = "eq"
+      value    = "premium"
+    },
+  ]
+}
+
+moved {
+  from = flipt_constraint.email
+  to   = flipt_segment_constraints.test
+}
+
+removed {
+  from = flipt_constraint.plan
+
+  lifecycle {
+    destroy = false
+  }
+}
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("flipt_segment_constraints.test", "constraints.#", "2"),
+					testAccCheckSegmentConstraintCount("test-segment-constraints-moved-ns", "beta-users", 2),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckSegmentConstraintCount checks the number of constraints Flipt
+// stores for a segment of the default environment.
+func testAccCheckSegmentConstraintCount(namespaceKey, segmentKey string, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		c := client.New(client.Config{Endpoint: getTestFliptEndpoint()})
+		segment, err := c.GetSegment(context.Background(), "default", namespaceKey, segmentKey)
+		if err != nil {
+			return fmt.Errorf("unable to read segment %s: %w", segmentKey, err)
+		}
+		if len(segment.Constraints) != expected {
+			return fmt.Errorf("expected segment %s to have %d constraints, got %d", segmentKey, expected, len(segment.Constraints))
+		}
+		return nil
+	}
+}
+
+func testAccSegmentConstraintsResourceMovedConfig(constraints string) string {
+	return fmt.Sprintf(`
+provider "flipt" {
+  endpoint = %[1]q
+}
+
+resource "flipt_namespace" "test" {
+  key  = "test-segment-constraints-moved-ns"
+  name = "Test Namespace"
+}
+
+resource "flipt_segment" "test" {
+  namespace_key = flipt_namespace.test.key
+  key           = "beta-users"
+  name          = "Beta Users"
+  match_type    = "ALL_MATCH_TYPE"
+}
+%[2]s`, getTestFliptEndpoint(), constraints)
+}
+
+func TestSegmentConstraintsResource(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.put("default", "default", client.TypeSegment, "adults", map[string]interface{}{
+		"name":      "Adults",
+		"matchType": "ALL_MATCH_TYPE",
+		"constraints": []interface{}{
+			map[string]interface{}{"property": "stale", "type": "STRING_COMPARISON_TYPE", "operator": "eq", "value": "x"},
+		},
+	})
+
+	r := NewSegmentConstraintsResource()
+	configureResource(t, r, fake.config())
+
+	model := func(constraints ...client.Constraint) *SegmentConstraintsResourceModel {
+		set, diags := constraintsToModel(context.Background(), constraints)
+		if diags.HasError() {
+			t.Fatalf("Unable to build constraints: %v", diags)
+		}
+		return &SegmentConstraintsResourceModel{
+			NamespaceKey:   types.StringValue("default"),
+			EnvironmentKey: types.StringValue("default"),
+			SegmentKey:     types.StringValue("adults"),
+			Constraints:    set,
+			ID:             types.StringUnknown(),
+		}
+	}
+	minAge := constraintFromModel(*ageConstraint("gte", "18"))
+	maxAge := constraintFromModel(*ageConstraint("lte", "65"))
+
+	// The constraints replace the existing ones in a single write
+	writes := fake.writes
+	resp, _ := createResource(t, r, model(minAge, maxAge))
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Create failed: %v", resp.Diagnostics)
+	}
+	if got := storedConstraints(t, fake); len(got) != 2 || got[0] != minAge || got[1] != maxAge {
+		t.Fatalf("Expected the two age constraints, got %v", got)
+	}
+	if fake.writes-writes != 1 {
+		t.Errorf("Expected a single write, got %d", fake.writes-writes)
+	}
+
+	// A constraint added outside of Terraform shows up as drift
+	segment := fake.get("default", "default", client.TypeSegment, "adults")
+	segment["constraints"] = append(segment["constraints"].([]interface{}),
+		map[string]interface{}{"property": "country", "type": "STRING_COMPARISON_TYPE", "operator": "eq", "value": "NL"})
+	readResp, state := readResource(t, r, model(minAge, maxAge))
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("Read failed: %v", readResp.Diagnostics)
+	}
+	var data SegmentConstraintsResourceModel
+	state.Get(context.Background(), &data)
+	if len(data.Constraints.Elements()) != 3 {
+		t.Errorf("Expected 3 constraints in state, got %d", len(data.Constraints.Elements()))
+	}
+
+	if resp := deleteResource(t, r, model(minAge, maxAge)); resp.Diagnostics.HasError() {
+		t.Fatalf("Delete failed: %v", resp.Diagnostics)
+	}
+	if got := storedConstraints(t, fake); len(got) != 0 {
+		t.Errorf("Expected constraints to be cleared, got %v", got)
+	}
+}
+
+func TestSegmentConstraintsResourceMoveState(t *testing.T) {
+	ctx := context.Background()
+	r := NewSegmentConstraintsResource()
+	mover := r.(fwresource.ResourceWithMoveState).MoveState(ctx)[0]
+
+	source := tfsdk.State{
+		Schema: *mover.SourceSchema,
+		Raw:    tftypes.NewValue(mover.SourceSchema.Type().TerraformType(ctx), nil),
+	}
+	source.Set(ctx, &ConstraintResourceModel{
+		NamespaceKey:   types.StringValue("team-a"),
+		EnvironmentKey: types.StringValue("production"),
+		SegmentKey:     types.StringValue("beta-users"),
+		Property:       types.StringValue("email"),
+		Type:           types.StringValue("STRING_COMPARISON_TYPE"),
+		Operator:       types.StringValue("suffix"),
+		Value:          types.StringValue("@example.com"),
+		Description:    types.StringNull(),
+		ID:             types.StringValue("email/suffix/@example.com"),
+	})
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+	resp := &fwresource.MoveStateResponse{
+		TargetState: tfsdk.State{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+		},
+	}
+	mover.StateMover(ctx, fwresource.MoveStateRequest{
+		SourceTypeName:        "flipt_constraint",
+		SourceProviderAddress: "registry.terraform.io/lerentis/flipt",
+		SourceSchemaVersion:   1,
+		SourceState:           &source,
+	}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Move failed: %v", resp.Diagnostics)
+	}
+
+	var data SegmentConstraintsResourceModel
+	resp.TargetState.Get(ctx, &data)
+	constraints, _ := constraintsFromModel(ctx, data.Constraints)
+	if data.EnvironmentKey.ValueString() != "production" || data.SegmentKey.ValueString() != "beta-users" || data.ID.ValueString() != "beta-users" {
+		t.Errorf("Unexpected keys in moved state: %+v", data)
+	}
+	if len(constraints) != 1 || constraints[0].Value != "@example.com" {
+		t.Errorf("Expected the moved constraint, got %v", constraints)
+	}
+
+	// Other resource types are left to other movers
+	resp.TargetState.RemoveResource(ctx)
+	mover.StateMover(ctx, fwresource.MoveStateRequest{SourceTypeName: "flipt_segment", SourceProviderAddress: "registry.terraform.io/lerentis/flipt"}, resp)
+	if resp.Diagnostics.HasError() || !resp.TargetState.Raw.IsNull() {
+		t.Errorf("Expected flipt_segment to be skipped, got %v", resp.Diagnostics)
+	}
+}
@@ -5,10 +5,7 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
@@ -46,14 +43,16 @@ func (d *SegmentDataSource) Schema(ctx context.Context, req datasource.SchemaReq
 
 		Attributes: map[string]schema.Attribute{
 			"namespace_key": schema.StringAttribute{
-				MarkdownDescription: "Namespace key",
-				Description:         "Namespace key",
-				Required:            true,
+				MarkdownDescription: "Namespace key. Defaults to the provider's `default_namespace_key`, or `default`",
+				Description:         "Namespace key. Defaults to the provider's default_namespace_key, or default",
+				Optional:            true,
+				Computed:            true,
 			},
 			"environment_key": schema.StringAttribute{
-				MarkdownDescription: "Environment key (defaults to 'default' if not specified)",
-				Description:         "Environment key (defaults to 'default' if not specified)",
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
+				Description:         "Environment key. Defaults to the provider's default_environment_key, or default",
 				Optional:            true,
+				Computed:            true,
 			},
 			"key": schema.StringAttribute{
 				MarkdownDescription: "Segment key",
@@ -103,11 +102,10 @@ func (d *SegmentDataSource) Read(ctx context.Context, req datasource.ReadRequest
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the data source
+	envKey := d.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(d.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Reading segment data source", map[string]interface{}{
 		"environment_key": envKey,
@@ -115,59 +113,21 @@ func (d *SegmentDataSource) Read(ctx context.Context, req datasource.ReadRequest
 		"segment_key":     data.Key.ValueString(),
 	})
 
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Segment/%s",
-		d.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.Key.ValueString())
-
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-
-	d.config.AddAuthHeader(httpReq)
-	httpResp, err := d.config.HTTPClient.Do(httpReq)
+	segment, err := d.config.Client.GetSegment(ctx, envKey, data.NamespaceKey.ValueString(), data.Key.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read segment, got error: %s", err))
 		return
 	}
-	defer httpResp.Body.Close()
-
-	body, err := io.ReadAll(httpResp.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Read Error", fmt.Sprintf("Unable to read response: %s", err))
-		return
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read segment, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var segmentResponse struct {
-		Resource struct {
-			Payload struct {
-				Key         string `json:"key"`
-				Name        string `json:"name"`
-				Description string `json:"description"`
-				MatchType   string `json:"matchType"`
-			} `json:"payload"`
-		} `json:"resource"`
-	}
-
-	if err := json.Unmarshal(body, &segmentResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s", err))
-		return
-	}
 
-	data.Name = types.StringValue(segmentResponse.Resource.Payload.Name)
+	data.Name = types.StringValue(segment.Name)
 
-	if segmentResponse.Resource.Payload.Description != "" {
-		data.Description = types.StringValue(segmentResponse.Resource.Payload.Description)
+	if segment.Description != "" {
+		data.Description = types.StringValue(segment.Description)
 	} else {
 		data.Description = types.StringNull()
 	}
 
-	data.MatchType = types.StringValue(segmentResponse.Resource.Payload.MatchType)
+	data.MatchType = types.StringValue(segment.MatchType)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
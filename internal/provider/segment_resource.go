@@ -4,25 +4,26 @@
 package provider
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
 )
 
 var _ resource.Resource = &SegmentResource{}
 var _ resource.ResourceWithImportState = &SegmentResource{}
+var _ resource.ResourceWithModifyPlan = &SegmentResource{}
 
 func NewSegmentResource() resource.Resource {
 	return &SegmentResource{}
@@ -51,14 +52,19 @@ func (r *SegmentResource) Schema(ctx context.Context, req resource.SchemaRequest
 
 		Attributes: map[string]schema.Attribute{
 			"namespace_key": schema.StringAttribute{
-				MarkdownDescription: "Namespace key where the segment belongs",
-				Required:            true,
+				MarkdownDescription: "Namespace key where the segment belongs. Defaults to the provider's `default_namespace_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
 				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
 			"environment_key": schema.StringAttribute{
-				MarkdownDescription: "Environment key (defaults to 'default')",
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
 				Optional:            true,
 				Computed:            true,
 				PlanModifiers: []planmodifier.String{
@@ -67,8 +73,11 @@ func (r *SegmentResource) Schema(ctx context.Context, req resource.SchemaRequest
 				},
 			},
 			"key": schema.StringAttribute{
-				MarkdownDescription: "Unique key for the segment",
+				MarkdownDescription: "Unique key for the segment. Letters, numbers, `-`, `_` and `,` only",
 				Required:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
@@ -86,6 +95,9 @@ func (r *SegmentResource) Schema(ctx context.Context, req resource.SchemaRequest
 				Optional:            true,
 				Computed:            true,
 				Default:             stringdefault.StaticString("ALL_MATCH_TYPE"),
+				Validators: []validator.String{
+					stringvalidator.OneOf("ALL_MATCH_TYPE", "ANY_MATCH_TYPE"),
+				},
 			},
 		},
 	}
@@ -108,6 +120,10 @@ func (r *SegmentResource) Configure(ctx context.Context, req resource.ConfigureR
 	r.config = providerConfig
 }
 
+func (r *SegmentResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultKeys(ctx, r.config, true, req, resp)
+}
+
 func (r *SegmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data SegmentResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -115,11 +131,10 @@ func (r *SegmentResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Creating segment", map[string]interface{}{
 		"environment_key": envKey,
@@ -127,53 +142,20 @@ func (r *SegmentResource) Create(ctx context.Context, req resource.CreateRequest
 		"segment_key":     data.Key.ValueString(),
 	})
 
-	// Build segment payload
-	segmentPayload := map[string]interface{}{
-		"@type":       "flipt.core.Segment",
-		"key":         data.Key.ValueString(),
-		"name":        data.Name.ValueString(),
-		"matchType":   data.MatchType.ValueString(),
-		"constraints": []interface{}{},
+	segment := &client.Segment{
+		Key:       data.Key.ValueString(),
+		Name:      data.Name.ValueString(),
+		MatchType: data.MatchType.ValueString(),
 	}
 
 	if !data.Description.IsNull() && !data.Description.IsUnknown() {
-		segmentPayload["description"] = data.Description.ValueString()
-	} else {
-		segmentPayload["description"] = ""
-	}
-
-	createReq := map[string]interface{}{
-		"key":     data.Key.ValueString(),
-		"payload": segmentPayload,
+		segment.Description = data.Description.ValueString()
 	}
 
-	reqBody, err := json.Marshal(createReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Serialization Error", fmt.Sprintf("Unable to marshal request: %s", err))
-		return
-	}
-
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources", r.config.Endpoint, envKey, data.NamespaceKey.ValueString())
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
-	if err != nil {
+	if _, err := r.config.Client.CreateSegment(ctx, envKey, data.NamespaceKey.ValueString(), segment); err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create segment, got error: %s", err))
 		return
 	}
-	defer httpResp.Body.Close()
-
-	body, _ := io.ReadAll(httpResp.Body)
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to create segment, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
 
 	tflog.Trace(ctx, "created a segment resource")
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -186,11 +168,10 @@ func (r *SegmentResource) Read(ctx context.Context, req resource.ReadRequest, re
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Reading segment", map[string]interface{}{
 		"environment_key": envKey,
@@ -198,64 +179,21 @@ func (r *SegmentResource) Read(ctx context.Context, req resource.ReadRequest, re
 		"segment_key":     data.Key.ValueString(),
 	})
 
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Segment/%s",
-		r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.Key.ValueString())
-
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
-	if err != nil {
-		resp.State.RemoveResource(ctx)
-		return
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode == http.StatusNotFound {
-		resp.State.RemoveResource(ctx)
-		return
-	}
-
-	body, err := io.ReadAll(httpResp.Body)
+	segment, err := r.config.Client.GetSegment(ctx, envKey, data.NamespaceKey.ValueString(), data.Key.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Read Error", fmt.Sprintf("Unable to read response: %s", err))
-		return
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read segment, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var segmentResponse struct {
-		Resource struct {
-			Payload struct {
-				Key         string `json:"key"`
-				Name        string `json:"name"`
-				Description string `json:"description"`
-				MatchType   string `json:"matchType"`
-			} `json:"payload"`
-		} `json:"resource"`
-	}
-
-	if err := json.Unmarshal(body, &segmentResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s", err))
+		handleReadError(ctx, resp, "segment", data.Key.ValueString(), err)
 		return
 	}
 
-	data.Name = types.StringValue(segmentResponse.Resource.Payload.Name)
+	data.Name = types.StringValue(segment.Name)
 
-	if segmentResponse.Resource.Payload.Description != "" {
-		data.Description = types.StringValue(segmentResponse.Resource.Payload.Description)
+	if segment.Description != "" {
+		data.Description = types.StringValue(segment.Description)
 	} else {
 		data.Description = types.StringNull()
 	}
 
-	data.MatchType = types.StringValue(segmentResponse.Resource.Payload.MatchType)
+	data.MatchType = types.StringValue(segment.MatchType)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -267,11 +205,10 @@ func (r *SegmentResource) Update(ctx context.Context, req resource.UpdateRequest
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Updating segment", map[string]interface{}{
 		"environment_key": envKey,
@@ -279,88 +216,22 @@ func (r *SegmentResource) Update(ctx context.Context, req resource.UpdateRequest
 		"segment_key":     data.Key.ValueString(),
 	})
 
-	// Get current segment to preserve constraints
-	getURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Segment/%s",
-		r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.Key.ValueString())
-
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", getURL, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read segment, got error: %s", err))
-		return
-	}
-	defer httpResp.Body.Close()
-
-	body, _ := io.ReadAll(httpResp.Body)
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read segment, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var segmentResponse struct {
-		Resource struct {
-			Payload struct {
-				Constraints []interface{} `json:"constraints"`
-			} `json:"payload"`
-		} `json:"resource"`
-	}
-
-	if err := json.Unmarshal(body, &segmentResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse segment response: %s", err))
-		return
-	}
-
-	// Build updated segment payload, preserving constraints
-	segmentPayload := map[string]interface{}{
-		"@type":       "flipt.core.Segment",
-		"key":         data.Key.ValueString(),
-		"name":        data.Name.ValueString(),
-		"matchType":   data.MatchType.ValueString(),
-		"constraints": segmentResponse.Resource.Payload.Constraints,
-	}
-
-	if !data.Description.IsNull() && !data.Description.IsUnknown() {
-		segmentPayload["description"] = data.Description.ValueString()
-	} else {
-		segmentPayload["description"] = ""
-	}
-
-	updateReq := map[string]interface{}{
-		"key":     data.Key.ValueString(),
-		"payload": segmentPayload,
-	}
-
-	reqBody, err := json.Marshal(updateReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Serialization Error", fmt.Sprintf("Unable to marshal request: %s", err))
-		return
-	}
-
-	updateURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources", r.config.Endpoint, envKey, data.NamespaceKey.ValueString())
-	httpReq, err = http.NewRequestWithContext(ctx, "PUT", updateURL, bytes.NewReader(reqBody))
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err = r.config.HTTPClient.Do(httpReq)
+	// Hold the segment while merging so constraint changes are not lost
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeSegment, data.Key.ValueString())
+	defer unlock()
+
+	// Replace the segment's own fields, preserving constraints
+	_, err := r.config.Client.UpdateSegment(ctx, envKey, data.NamespaceKey.ValueString(), data.Key.ValueString(), func(segment *client.Segment) error {
+		segment.Name = data.Name.ValueString()
+		segment.MatchType = data.MatchType.ValueString()
+		segment.Description = ""
+		if !data.Description.IsNull() && !data.Description.IsUnknown() {
+			segment.Description = data.Description.ValueString()
+		}
+		return nil
+	})
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update segment, got error: %s", err))
-		return
-	}
-	defer httpResp.Body.Close()
-
-	body, _ = io.ReadAll(httpResp.Body)
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to update segment, status: %d, body: %s", httpResp.StatusCode, string(body)))
+		addParentWriteError(&resp.Diagnostics, "update segment", "segment", data.Key.ValueString(), err)
 		return
 	}
 
@@ -374,11 +245,10 @@ func (r *SegmentResource) Delete(ctx context.Context, req resource.DeleteRequest
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Deleting segment", map[string]interface{}{
 		"environment_key": envKey,
@@ -386,33 +256,24 @@ func (r *SegmentResource) Delete(ctx context.Context, req resource.DeleteRequest
 		"segment_key":     data.Key.ValueString(),
 	})
 
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Segment/%s",
-		r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.Key.ValueString())
-
-	httpReq, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
-	if err != nil {
+	err := r.config.Client.DeleteResource(ctx, envKey, data.NamespaceKey.ValueString(), client.TypeSegment, data.Key.ValueString())
+	if err != nil && !errors.Is(err, client.ErrNotFound) {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete segment, got error: %s", err))
 		return
 	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to delete segment, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
 
 	tflog.Trace(ctx, "deleted a segment resource")
 }
 
+// ImportState imports a segment by an ID of the form
+// environment_key/namespace_key/segment_key.
 func (r *SegmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("key"), req, resp)
+	parts, ok := parseImportID(req, resp, "environment_key", "namespace_key", "segment_key")
+	if !ok {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("environment_key"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("namespace_key"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("key"), parts[2])...)
 }
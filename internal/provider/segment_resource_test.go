@@ -28,6 +28,13 @@ func TestAccSegmentResource(t *testing.T) {
 					resource.TestCheckResourceAttr("flipt_segment.test", "match_type", "ALL_MATCH_TYPE"),
 				),
 			},
+			// ImportState testing
+			{
+				ResourceName:      "flipt_segment.test",
+				ImportState:       true,
+				ImportStateId:     "default/test-namespace/test-segment",
+				ImportStateVerify: true,
+			},
 			// Update and Read testing
 			{
 				Config: testAccSegmentResourceConfig("default", "test-namespace", "test-segment", "Updated Segment", "ANY_MATCH_TYPE"),
@@ -0,0 +1,167 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
+)
+
+var _ datasource.DataSource = &SegmentsDataSource{}
+
+func NewSegmentsDataSource() datasource.DataSource {
+	return &SegmentsDataSource{}
+}
+
+type SegmentsDataSource struct {
+	config *FliptProviderConfig
+}
+
+type SegmentsDataSourceModel struct {
+	NamespaceKey   types.String                     `tfsdk:"namespace_key"`
+	EnvironmentKey types.String                     `tfsdk:"environment_key"`
+	KeyPrefix      types.String                     `tfsdk:"key_prefix"`
+	MatchType      types.String                     `tfsdk:"match_type"`
+	Segments       []SegmentsDataSourceSegmentModel `tfsdk:"segments"`
+}
+
+type SegmentsDataSourceSegmentModel struct {
+	Key         types.String `tfsdk:"key"`
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"`
+	MatchType   types.String `tfsdk:"match_type"`
+}
+
+func (d *SegmentsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_segments"
+}
+
+func (d *SegmentsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the segments of a namespace, optionally filtered",
+		Description:         "Lists the segments of a namespace, optionally filtered",
+
+		Attributes: map[string]schema.Attribute{
+			"namespace_key": schema.StringAttribute{
+				MarkdownDescription: "Namespace key to list the segments of. Defaults to the provider's `default_namespace_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+			},
+			"environment_key": schema.StringAttribute{
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+			},
+			"key_prefix": schema.StringAttribute{
+				MarkdownDescription: "Only list segments whose key starts with this prefix",
+				Optional:            true,
+			},
+			"match_type": schema.StringAttribute{
+				MarkdownDescription: "Only list segments of this match type (ALL_MATCH_TYPE or ANY_MATCH_TYPE)",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("ALL_MATCH_TYPE", "ANY_MATCH_TYPE"),
+				},
+			},
+			"segments": schema.ListNestedAttribute{
+				MarkdownDescription: "Segments matching the filters, ordered by key",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"key": schema.StringAttribute{
+							MarkdownDescription: "Unique key of the segment",
+							Computed:            true,
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Display name of the segment",
+							Computed:            true,
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "Description of the segment",
+							Computed:            true,
+						},
+						"match_type": schema.StringAttribute{
+							MarkdownDescription: "Match type of the segment (ALL_MATCH_TYPE or ANY_MATCH_TYPE)",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *SegmentsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerConfig, ok := req.ProviderData.(*FliptProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *FliptProviderConfig, got: %T", req.ProviderData),
+		)
+		return
+	}
+
+	d.config = providerConfig
+}
+
+func (d *SegmentsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data SegmentsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fall back to the provider defaults for keys not set on the data source
+	envKey := d.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(d.config.NamespaceKey(data.NamespaceKey))
+
+	tflog.Debug(ctx, "Listing segments", map[string]interface{}{
+		"environment_key": envKey,
+		"namespace_key":   data.NamespaceKey.ValueString(),
+	})
+
+	segments, err := d.config.Client.ListSegments(ctx, envKey, data.NamespaceKey.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list segments in namespace '%s', got error: %s", data.NamespaceKey.ValueString(), err))
+		return
+	}
+	slices.SortFunc(segments, func(a, b client.Segment) int { return strings.Compare(a.Key, b.Key) })
+
+	data.Segments = []SegmentsDataSourceSegmentModel{}
+	for _, segment := range segments {
+		if !strings.HasPrefix(segment.Key, data.KeyPrefix.ValueString()) ||
+			(!data.MatchType.IsNull() && segment.MatchType != data.MatchType.ValueString()) {
+			continue
+		}
+
+		item := SegmentsDataSourceSegmentModel{
+			Key:         types.StringValue(segment.Key),
+			Name:        types.StringValue(segment.Name),
+			Description: types.StringNull(),
+			MatchType:   types.StringValue(segment.MatchType),
+		}
+		if segment.Description != "" {
+			item.Description = types.StringValue(segment.Description)
+		}
+		data.Segments = append(data.Segments, item)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"terraform-provider-flipt/internal/client"
+)
+
+func TestAccSegmentsDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSegmentsDataSourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.flipt_segments.all", "segments.#", "2"),
+					resource.TestCheckResourceAttr("data.flipt_segments.all", "segments.0.key", "beta-testers"),
+					resource.TestCheckResourceAttr("data.flipt_segments.all", "segments.0.match_type", "ANY_MATCH_TYPE"),
+					resource.TestCheckResourceAttr("data.flipt_segments.internal", "segments.#", "1"),
+					resource.TestCheckResourceAttr("data.flipt_segments.internal", "segments.0.name", "Internal Users"),
+				),
+			},
+		},
+	})
+}
+
+func testAccSegmentsDataSourceConfig() string {
+	return `
+provider "flipt" {
+  endpoint = "` + getTestFliptEndpoint() + `"
+}
+
+resource "flipt_namespace" "test" {
+  key           = "segments-data-source"
+  name          = "Segments Data Source"
+  force_destroy = true
+}
+
+resource "flipt_segment" "beta" {
+  namespace_key = flipt_namespace.test.key
+  key           = "beta-testers"
+  name          = "Beta Testers"
+  match_type    = "ANY_MATCH_TYPE"
+}
+
+resource "flipt_segment" "internal" {
+  namespace_key = flipt_namespace.test.key
+  key           = "internal-users"
+  name          = "Internal Users"
+}
+
+data "flipt_segments" "all" {
+  namespace_key = flipt_namespace.test.key
+  depends_on    = [flipt_segment.beta, flipt_segment.internal]
+}
+
+data "flipt_segments" "internal" {
+  namespace_key = flipt_namespace.test.key
+  key_prefix    = "internal-"
+  depends_on    = [flipt_segment.beta, flipt_segment.internal]
+}
+`
+}
+
+func TestSegmentsDataSource(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.pageSize = 1
+	fake.put("default", "default", client.TypeSegment, "beta-testers", map[string]interface{}{
+		"name": "Beta Testers", "description": "Opted in", "matchType": "ANY_MATCH_TYPE",
+	})
+	fake.put("default", "default", client.TypeSegment, "internal-users", map[string]interface{}{
+		"name": "Internal Users", "matchType": "ALL_MATCH_TYPE",
+	})
+	fake.put("default", "default", client.TypeSegment, "internal-admins", map[string]interface{}{
+		"name": "Internal Admins", "matchType": "ANY_MATCH_TYPE",
+	})
+
+	str := func(v string) tftypes.Value { return tftypes.NewValue(tftypes.String, v) }
+
+	tests := []struct {
+		name   string
+		config map[string]tftypes.Value
+		want   []string
+	}{
+		{
+			name: "all",
+			want: []string{"beta-testers", "internal-admins", "internal-users"},
+		},
+		{
+			name:   "key prefix",
+			config: map[string]tftypes.Value{"key_prefix": str("internal-")},
+			want:   []string{"internal-admins", "internal-users"},
+		},
+		{
+			name:   "match type",
+			config: map[string]tftypes.Value{"match_type": str("ANY_MATCH_TYPE")},
+			want:   []string{"beta-testers", "internal-admins"},
+		},
+		{
+			name:   "empty namespace",
+			config: map[string]tftypes.Value{"namespace_key": str("other")},
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewSegmentsDataSource()
+			configureDataSource(t, d, fake.config())
+
+			resp, state := readDataSource(t, d, tt.config)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Read failed: %v", resp.Diagnostics)
+			}
+
+			var data SegmentsDataSourceModel
+			if diags := state.Get(context.Background(), &data); diags.HasError() {
+				t.Fatalf("Unable to read state: %v", diags)
+			}
+
+			keys := []string{}
+			for _, segment := range data.Segments {
+				keys = append(keys, segment.Key.ValueString())
+			}
+			if !reflect.DeepEqual(keys, tt.want) {
+				t.Errorf("Expected segments %v, got %v", tt.want, keys)
+			}
+			if len(data.Segments) > 0 && data.Segments[0].Key.ValueString() == "beta-testers" {
+				if data.Segments[0].Description.ValueString() != "Opted in" || data.Segments[0].MatchType.ValueString() != "ANY_MATCH_TYPE" {
+					t.Errorf("Unexpected segment %+v", data.Segments[0])
+				}
+			}
+		})
+	}
+}
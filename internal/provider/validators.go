@@ -0,0 +1,50 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// keyRegexp matches the keys Flipt accepts for namespaces, flags, segments
+// and variants.
+var keyRegexp = regexp.MustCompile(`^[-_,A-Za-z0-9]+$`)
+
+// keyValidator validates a namespace, flag, segment or variant key.
+func keyValidator() validator.String {
+	return stringvalidator.RegexMatches(keyRegexp, "must only contain letters, numbers, '-', '_' and ','")
+}
+
+// segmentKeysValidator validates each key in a list of segment keys.
+func segmentKeysValidator() validator.List {
+	return listvalidator.ValueStringsAre(keyValidator())
+}
+
+// segmentOperatorValidator validates the operator combining the segments of a
+// rule or rollout.
+func segmentOperatorValidator() validator.String {
+	return stringvalidator.OneOf("OR_SEGMENT_OPERATOR", "AND_SEGMENT_OPERATOR")
+}
+
+// validateSegmentOperator rejects AND_SEGMENT_OPERATOR for fewer than two
+// segments, reporting the error on the segment_operator attribute below p.
+func validateSegmentOperator(diags *diag.Diagnostics, p path.Path, operator types.String, segmentKeys types.List) {
+	if operator.ValueString() != "AND_SEGMENT_OPERATOR" || segmentKeys.IsNull() || segmentKeys.IsUnknown() {
+		return
+	}
+
+	if n := len(segmentKeys.Elements()); n < 2 {
+		diags.AddAttributeError(p.AtName("segment_operator"), "Invalid Segment Operator",
+			fmt.Sprintf("AND_SEGMENT_OPERATOR combines several segments, but %d segment key(s) are set. "+
+				"Use OR_SEGMENT_OPERATOR or add segment keys.", n))
+	}
+}
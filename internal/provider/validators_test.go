@@ -0,0 +1,160 @@
+// Copyright (c) terraform-provider-flipt contributors
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestSchemaValidators(t *testing.T) {
+	segmentKeys := func(keys ...string) types.List {
+		list, _ := types.ListValueFrom(context.Background(), types.StringType, keys)
+		return list
+	}
+	flag := func(key, flagType string) *FlagResourceModel {
+		return &FlagResourceModel{
+			NamespaceKey:   types.StringNull(),
+			EnvironmentKey: types.StringNull(),
+			Key:            types.StringValue(key),
+			Name:           types.StringValue("Checkout"),
+			Description:    types.StringNull(),
+			Enabled:        types.BoolNull(),
+			Type:           types.StringValue(flagType),
+			Metadata:       types.DynamicNull(),
+		}
+	}
+	rule := func(operator string, keys ...string) *RuleResourceModel {
+		return &RuleResourceModel{
+			NamespaceKey:    types.StringNull(),
+			EnvironmentKey:  types.StringNull(),
+			FlagKey:         types.StringValue("checkout"),
+			ID:              types.StringNull(),
+			SegmentKeys:     segmentKeys(keys...),
+			SegmentOperator: types.StringValue(operator),
+			Rank:            types.Int64Null(),
+		}
+	}
+
+	tests := []struct {
+		name     string
+		typeName string
+		resource func() resource.Resource
+		config   interface{}
+		expect   string
+	}{
+		{name: "valid flag", typeName: "flipt_flag", resource: NewFlagResource, config: flag("new-checkout_v2,eu", "BOOLEAN_FLAG_TYPE")},
+		{name: "flag key with spaces", typeName: "flipt_flag", resource: NewFlagResource, config: flag("new checkout", "BOOLEAN_FLAG_TYPE"), expect: "must only contain letters"},
+		{name: "flag key with slash", typeName: "flipt_flag", resource: NewFlagResource, config: flag("team/checkout", "BOOLEAN_FLAG_TYPE"), expect: "must only contain letters"},
+		{name: "unknown flag type", typeName: "flipt_flag", resource: NewFlagResource, config: flag("checkout", "BOOL_FLAG_TYPE"), expect: "BOOL_FLAG_TYPE"},
+		{name: "valid rule", typeName: "flipt_rule", resource: NewRuleResource, config: rule("AND_SEGMENT_OPERATOR", "beta", "internal")},
+		{name: "unknown segment operator", typeName: "flipt_rule", resource: NewRuleResource, config: rule("XOR_SEGMENT_OPERATOR", "beta"), expect: "XOR_SEGMENT_OPERATOR"},
+		{name: "invalid segment key", typeName: "flipt_rule", resource: NewRuleResource, config: rule("OR_SEGMENT_OPERATOR", "beta users"), expect: "must only contain letters"},
+		{name: "and with one segment", typeName: "flipt_rule", resource: NewRuleResource, config: rule("AND_SEGMENT_OPERATOR", "beta"), expect: "Invalid Segment Operator"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := validateProviderResourceConfig(t, tt.typeName, tt.resource(), tt.config)
+
+			var messages []string
+			for _, d := range diags {
+				if d.Severity == tfprotov6.DiagnosticSeverityError {
+					messages = append(messages, d.Summary+": "+d.Detail)
+				}
+			}
+			if tt.expect == "" {
+				if len(messages) > 0 {
+					t.Fatalf("Expected no error, got %v", messages)
+				}
+				return
+			}
+			if len(messages) != 1 || !strings.Contains(messages[0], tt.expect) {
+				t.Errorf("Expected a single error containing %q, got %v", tt.expect, messages)
+			}
+		})
+	}
+}
+
+func TestValidateSegmentOperator(t *testing.T) {
+	segmentKeys := func(keys ...string) types.List {
+		list, _ := types.ListValueFrom(context.Background(), types.StringType, keys)
+		return list
+	}
+	p := path.Root("rules").AtListIndex(1)
+
+	tests := []struct {
+		name        string
+		operator    types.String
+		segmentKeys types.List
+		expectError bool
+	}{
+		{name: "and with one segment", operator: types.StringValue("AND_SEGMENT_OPERATOR"), segmentKeys: segmentKeys("beta"), expectError: true},
+		{name: "and with two segments", operator: types.StringValue("AND_SEGMENT_OPERATOR"), segmentKeys: segmentKeys("beta", "internal")},
+		{name: "or with one segment", operator: types.StringValue("OR_SEGMENT_OPERATOR"), segmentKeys: segmentKeys("beta")},
+		{name: "unknown segment keys", operator: types.StringValue("AND_SEGMENT_OPERATOR"), segmentKeys: types.ListUnknown(types.StringType)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			validateSegmentOperator(&diags, p, tt.operator, tt.segmentKeys)
+
+			if !tt.expectError {
+				if diags.HasError() {
+					t.Fatalf("Expected no error, got %v", diags)
+				}
+				return
+			}
+			if !diags.HasError() {
+				t.Fatal("Expected Invalid Segment Operator error, got none")
+			}
+			withPath, ok := diags.Errors()[0].(diag.DiagnosticWithPath)
+			if !ok || !withPath.Path().Equal(p.AtName("segment_operator")) {
+				t.Errorf("Expected error at %s, got %v", p.AtName("segment_operator"), diags)
+			}
+		})
+	}
+}
+
+// validateProviderResourceConfig validates the given model as configuration
+// of a resource through the provider server, running the attribute validators
+// as terraform validate does.
+func validateProviderResourceConfig(t *testing.T, typeName string, r resource.Resource, config interface{}) []*tfprotov6.Diagnostic {
+	t.Helper()
+	ctx := context.Background()
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+	tfType := schemaResp.Schema.Type().TerraformType(ctx)
+
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(tfType, nil)}
+	if diags := state.Set(ctx, config); diags.HasError() {
+		t.Fatalf("Unable to build config: %v", diags)
+	}
+	value, err := tfprotov6.NewDynamicValue(tfType, state.Raw)
+	if err != nil {
+		t.Fatalf("Unable to encode config: %v", err)
+	}
+
+	server, err := testAccProtoV6ProviderFactories["flipt"]()
+	if err != nil {
+		t.Fatalf("Unable to start provider: %v", err)
+	}
+	resp, err := server.ValidateResourceConfig(ctx, &tfprotov6.ValidateResourceConfigRequest{TypeName: typeName, Config: &value})
+	if err != nil {
+		t.Fatalf("ValidateResourceConfig failed: %v", err)
+	}
+
+	return resp.Diagnostics
+}
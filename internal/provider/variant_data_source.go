@@ -5,11 +5,9 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -27,13 +25,13 @@ type VariantDataSource struct {
 }
 
 type VariantDataSourceModel struct {
-	NamespaceKey   types.String `tfsdk:"namespace_key"`
-	EnvironmentKey types.String `tfsdk:"environment_key"`
-	FlagKey        types.String `tfsdk:"flag_key"`
-	Key            types.String `tfsdk:"key"`
-	Name           types.String `tfsdk:"name"`
-	Description    types.String `tfsdk:"description"`
-	Attachment     types.String `tfsdk:"attachment"`
+	NamespaceKey   types.String         `tfsdk:"namespace_key"`
+	EnvironmentKey types.String         `tfsdk:"environment_key"`
+	FlagKey        types.String         `tfsdk:"flag_key"`
+	Key            types.String         `tfsdk:"key"`
+	Name           types.String         `tfsdk:"name"`
+	Description    types.String         `tfsdk:"description"`
+	Attachment     jsontypes.Normalized `tfsdk:"attachment"`
 }
 
 func (d *VariantDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -47,14 +45,16 @@ func (d *VariantDataSource) Schema(ctx context.Context, req datasource.SchemaReq
 
 		Attributes: map[string]schema.Attribute{
 			"namespace_key": schema.StringAttribute{
-				MarkdownDescription: "Namespace key",
-				Description:         "Namespace key",
-				Required:            true,
+				MarkdownDescription: "Namespace key. Defaults to the provider's `default_namespace_key`, or `default`",
+				Description:         "Namespace key. Defaults to the provider's default_namespace_key, or default",
+				Optional:            true,
+				Computed:            true,
 			},
 			"environment_key": schema.StringAttribute{
-				MarkdownDescription: "Environment key (defaults to 'default' if not specified)",
-				Description:         "Environment key (defaults to 'default' if not specified)",
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
+				Description:         "Environment key. Defaults to the provider's default_environment_key, or default",
 				Optional:            true,
+				Computed:            true,
 			},
 			"flag_key": schema.StringAttribute{
 				MarkdownDescription: "Flag key",
@@ -80,6 +80,7 @@ func (d *VariantDataSource) Schema(ctx context.Context, req datasource.SchemaReq
 				MarkdownDescription: "Variant attachment (JSON string)",
 				Description:         "Variant attachment (JSON string)",
 				Computed:            true,
+				CustomType:          jsontypes.NormalizedType{},
 			},
 		},
 	}
@@ -109,11 +110,10 @@ func (d *VariantDataSource) Read(ctx context.Context, req datasource.ReadRequest
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the data source
+	envKey := d.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(d.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Reading variant data source", map[string]interface{}{
 		"environment_key": envKey,
@@ -123,55 +123,15 @@ func (d *VariantDataSource) Read(ctx context.Context, req datasource.ReadRequest
 	})
 
 	// Get the flag to read its variants
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Flag/%s",
-		d.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
-
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-
-	d.config.AddAuthHeader(httpReq)
-	httpResp, err := d.config.HTTPClient.Do(httpReq)
+	flag, err := d.config.Client.GetFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read flag, got error: %s", err))
 		return
 	}
-	defer httpResp.Body.Close()
-
-	body, err := io.ReadAll(httpResp.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Read Error", fmt.Sprintf("Unable to read response: %s", err))
-		return
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read flag, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var flagResponse struct {
-		Resource struct {
-			Payload struct {
-				Variants []struct {
-					Key         string                 `json:"key"`
-					Name        string                 `json:"name"`
-					Description string                 `json:"description"`
-					Attachment  map[string]interface{} `json:"attachment"`
-				} `json:"variants"`
-			} `json:"payload"`
-		} `json:"resource"`
-	}
-
-	if err := json.Unmarshal(body, &flagResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s", err))
-		return
-	}
 
 	// Find the variant by key
 	var found bool
-	for _, v := range flagResponse.Resource.Payload.Variants {
+	for _, v := range flag.Variants {
 		if v.Key == data.Key.ValueString() {
 			found = true
 
@@ -187,16 +147,9 @@ func (d *VariantDataSource) Read(ctx context.Context, req datasource.ReadRequest
 				data.Description = types.StringNull()
 			}
 
-			if len(v.Attachment) > 0 {
-				attachmentJSON, err := json.Marshal(v.Attachment)
-				if err == nil {
-					data.Attachment = types.StringValue(string(attachmentJSON))
-				} else {
-					data.Attachment = types.StringNull()
-				}
-			} else {
-				data.Attachment = types.StringNull()
-			}
+			attachment, diags := variantAttachmentToModel(v.Attachment)
+			resp.Diagnostics.Append(diags...)
+			data.Attachment = attachment
 			break
 		}
 	}
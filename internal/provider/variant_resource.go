@@ -4,24 +4,31 @@
 package provider
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-flipt/internal/client"
 )
 
 var _ resource.Resource = &VariantResource{}
 var _ resource.ResourceWithImportState = &VariantResource{}
+var _ resource.ResourceWithModifyPlan = &VariantResource{}
+
+// errDefaultVariant is returned from the modify function of Delete when the
+// variant is the default variant of its flag.
+var errDefaultVariant = errors.New("variant is the default variant of the flag")
 
 func NewVariantResource() resource.Resource {
 	return &VariantResource{}
@@ -32,13 +39,13 @@ type VariantResource struct {
 }
 
 type VariantResourceModel struct {
-	NamespaceKey   types.String `tfsdk:"namespace_key"`
-	EnvironmentKey types.String `tfsdk:"environment_key"`
-	FlagKey        types.String `tfsdk:"flag_key"`
-	Key            types.String `tfsdk:"key"`
-	Name           types.String `tfsdk:"name"`
-	Description    types.String `tfsdk:"description"`
-	Attachment     types.String `tfsdk:"attachment"`
+	NamespaceKey   types.String         `tfsdk:"namespace_key"`
+	EnvironmentKey types.String         `tfsdk:"environment_key"`
+	FlagKey        types.String         `tfsdk:"flag_key"`
+	Key            types.String         `tfsdk:"key"`
+	Name           types.String         `tfsdk:"name"`
+	Description    types.String         `tfsdk:"description"`
+	Attachment     jsontypes.Normalized `tfsdk:"attachment"`
 }
 
 func (r *VariantResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -51,14 +58,19 @@ func (r *VariantResource) Schema(ctx context.Context, req resource.SchemaRequest
 
 		Attributes: map[string]schema.Attribute{
 			"namespace_key": schema.StringAttribute{
-				MarkdownDescription: "Namespace key where the flag belongs",
-				Required:            true,
+				MarkdownDescription: "Namespace key where the flag belongs. Defaults to the provider's `default_namespace_key`, or `default`",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
 				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
 			"environment_key": schema.StringAttribute{
-				MarkdownDescription: "Environment key (defaults to 'default' if not specified)",
+				MarkdownDescription: "Environment key. Defaults to the provider's `default_environment_key`, or `default`",
 				Optional:            true,
 				Computed:            true,
 				PlanModifiers: []planmodifier.String{
@@ -69,13 +81,19 @@ func (r *VariantResource) Schema(ctx context.Context, req resource.SchemaRequest
 			"flag_key": schema.StringAttribute{
 				MarkdownDescription: "Flag key that this variant belongs to",
 				Required:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
 			"key": schema.StringAttribute{
-				MarkdownDescription: "Unique key for the variant",
+				MarkdownDescription: "Unique key for the variant. Letters, numbers, `-`, `_` and `,` only",
 				Required:            true,
+				Validators: []validator.String{
+					keyValidator(),
+				},
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
@@ -89,8 +107,9 @@ func (r *VariantResource) Schema(ctx context.Context, req resource.SchemaRequest
 				Optional:            true,
 			},
 			"attachment": schema.StringAttribute{
-				MarkdownDescription: "JSON attachment data for the variant",
+				MarkdownDescription: "JSON attachment data for the variant, such as `jsonencode({ color = \"blue\" })`. Any JSON value is accepted, and formatting or key order differences from the stored value are not reported as changes",
 				Optional:            true,
+				CustomType:          jsontypes.NormalizedType{},
 			},
 		},
 	}
@@ -113,6 +132,10 @@ func (r *VariantResource) Configure(ctx context.Context, req resource.ConfigureR
 	r.config = providerConfig
 }
 
+func (r *VariantResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultKeys(ctx, r.config, true, req, resp)
+}
+
 func (r *VariantResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data VariantResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -120,11 +143,10 @@ func (r *VariantResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Creating variant", map[string]interface{}{
 		"environment_key": envKey,
@@ -133,135 +155,31 @@ func (r *VariantResource) Create(ctx context.Context, req resource.CreateRequest
 		"variant_key":     data.Key.ValueString(),
 	})
 
-	// First, get the current flag to read existing variants
-	flagURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Flag/%s",
-		r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
-
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", flagURL, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read flag, got error: %s", err))
-		return
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(httpResp.Body)
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read flag, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var flagResponse struct {
-		Resource struct {
-			Payload struct {
-				Type        string                   `json:"type"`
-				Key         string                   `json:"key"`
-				Name        string                   `json:"name"`
-				Description string                   `json:"description"`
-				Enabled     bool                     `json:"enabled"`
-				Variants    []map[string]interface{} `json:"variants"`
-				Metadata    map[string]interface{}   `json:"metadata"`
-			} `json:"payload"`
-		} `json:"resource"`
-	}
-
-	body, _ := io.ReadAll(httpResp.Body)
-	if err := json.Unmarshal(body, &flagResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse flag response: %s", err))
+	newVariant, diags := variantFromModel(data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Build new variant
-	newVariant := map[string]interface{}{
-		"key": data.Key.ValueString(),
-	}
-
-	if !data.Name.IsNull() && !data.Name.IsUnknown() {
-		newVariant["name"] = data.Name.ValueString()
-	} else {
-		newVariant["name"] = ""
-	}
-
-	if !data.Description.IsNull() && !data.Description.IsUnknown() {
-		newVariant["description"] = data.Description.ValueString()
-	} else {
-		newVariant["description"] = ""
-	}
+	// Serialize with other changes to the flag's variants
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
 
-	if !data.Attachment.IsNull() && !data.Attachment.IsUnknown() {
-		// Parse the attachment JSON string into a map
-		var attachmentData map[string]interface{}
-		if err := json.Unmarshal([]byte(data.Attachment.ValueString()), &attachmentData); err != nil {
-			resp.Diagnostics.AddError("Invalid Attachment", fmt.Sprintf("Attachment must be valid JSON: %s", err))
-			return
+	// Add new variant to the existing variants of the flag. A variant with the
+	// same key is replaced so that retrying after a lost response does not
+	// add it twice.
+	_, err := r.config.Client.UpdateFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString(), func(flag *client.Flag) error {
+		for i, v := range flag.Variants {
+			if v.Key == newVariant.Key {
+				flag.Variants[i] = newVariant
+				return nil
+			}
 		}
-		newVariant["attachment"] = attachmentData
-	} else {
-		newVariant["attachment"] = map[string]interface{}{}
-	}
-
-	// Add new variant to existing variants
-	existingVariants := flagResponse.Resource.Payload.Variants
-	if existingVariants == nil {
-		existingVariants = []map[string]interface{}{}
-	}
-	allVariants := append(existingVariants, newVariant)
-
-	// Update the flag with all variants (including the new one)
-	flagPayload := map[string]interface{}{
-		"@type":       "flipt.core.Flag",
-		"key":         flagResponse.Resource.Payload.Key,
-		"name":        flagResponse.Resource.Payload.Name,
-		"description": flagResponse.Resource.Payload.Description,
-		"type":        flagResponse.Resource.Payload.Type,
-		"enabled":     flagResponse.Resource.Payload.Enabled,
-		"variants":    allVariants,
-		"rules":       []interface{}{},
-		"metadata":    flagResponse.Resource.Payload.Metadata,
-	}
-
-	updateReq := map[string]interface{}{
-		"key":     data.FlagKey.ValueString(),
-		"payload": flagPayload,
-	}
-
-	reqBody, err := json.Marshal(updateReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Serialization Error", fmt.Sprintf("Unable to marshal request: %s", err))
-		return
-	}
-
-	updateURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources", r.config.Endpoint, envKey, data.NamespaceKey.ValueString())
-	httpReq, err = http.NewRequestWithContext(ctx, "PUT", updateURL, bytes.NewReader(reqBody))
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err = r.config.HTTPClient.Do(httpReq)
+		flag.Variants = append(flag.Variants, newVariant)
+		return nil
+	})
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create variant, got error: %s", err))
-		return
-	}
-	defer httpResp.Body.Close()
-
-	body, _ = io.ReadAll(httpResp.Body)
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to create variant, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	// Parse response to confirm variant was created
-	if err := json.Unmarshal(body, &flagResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s", err))
+		addParentWriteError(&resp.Diagnostics, "create variant", "flag", data.FlagKey.ValueString(), err)
 		return
 	}
 
@@ -277,11 +195,10 @@ func (r *VariantResource) Read(ctx context.Context, req resource.ReadRequest, re
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Reading variant", map[string]interface{}{
 		"environment_key": envKey,
@@ -291,60 +208,15 @@ func (r *VariantResource) Read(ctx context.Context, req resource.ReadRequest, re
 	})
 
 	// Get the flag to read its variants
-	url := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Flag/%s",
-		r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
-
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
+	flag, err := r.config.Client.GetFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
 	if err != nil {
-		resp.State.RemoveResource(ctx)
-		return
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode == http.StatusNotFound {
-		resp.State.RemoveResource(ctx)
-		return
-	}
-
-	body, err := io.ReadAll(httpResp.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Read Error", fmt.Sprintf("Unable to read response: %s", err))
-		return
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read flag, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var flagResponse struct {
-		Resource struct {
-			Payload struct {
-				Variants []struct {
-					Key         string                 `json:"key"`
-					Name        string                 `json:"name"`
-					Description string                 `json:"description"`
-					Attachment  map[string]interface{} `json:"attachment"`
-				} `json:"variants"`
-			} `json:"payload"`
-		} `json:"resource"`
-	}
-
-	if err := json.Unmarshal(body, &flagResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse response: %s", err))
+		handleReadError(ctx, resp, "flag", data.FlagKey.ValueString(), err)
 		return
 	}
 
 	// Find the variant by key
 	var found bool
-	for _, v := range flagResponse.Resource.Payload.Variants {
+	for _, v := range flag.Variants {
 		if v.Key == data.Key.ValueString() {
 			found = true
 
@@ -360,16 +232,14 @@ func (r *VariantResource) Read(ctx context.Context, req resource.ReadRequest, re
 				data.Description = types.StringNull()
 			}
 
-			if len(v.Attachment) > 0 {
-				attachmentJSON, err := json.Marshal(v.Attachment)
-				if err == nil {
-					data.Attachment = types.StringValue(string(attachmentJSON))
-				} else {
-					data.Attachment = types.StringNull()
-				}
-			} else {
-				data.Attachment = types.StringNull()
+			// Flipt stores a missing attachment as an empty object, keep an
+			// explicitly configured empty object as it is
+			attachment, diags := variantAttachmentToModel(v.Attachment)
+			resp.Diagnostics.Append(diags...)
+			if attachment.IsNull() && isEmptyAttachment(data.Attachment) {
+				attachment = data.Attachment
 			}
+			data.Attachment = attachment
 			break
 		}
 	}
@@ -389,11 +259,10 @@ func (r *VariantResource) Update(ctx context.Context, req resource.UpdateRequest
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Updating variant", map[string]interface{}{
 		"environment_key": envKey,
@@ -402,136 +271,31 @@ func (r *VariantResource) Update(ctx context.Context, req resource.UpdateRequest
 		"variant_key":     data.Key.ValueString(),
 	})
 
-	// Get the current flag to read existing variants
-	flagURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Flag/%s",
-		r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
-
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", flagURL, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read flag, got error: %s", err))
-		return
-	}
-	defer httpResp.Body.Close()
-
-	body, _ := io.ReadAll(httpResp.Body)
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read flag, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
-	}
-
-	var flagResponse struct {
-		Resource struct {
-			Payload struct {
-				Type        string                   `json:"type"`
-				Key         string                   `json:"key"`
-				Name        string                   `json:"name"`
-				Description string                   `json:"description"`
-				Enabled     bool                     `json:"enabled"`
-				Variants    []map[string]interface{} `json:"variants"`
-				Metadata    map[string]interface{}   `json:"metadata"`
-			} `json:"payload"`
-		} `json:"resource"`
-	}
-
-	if err := json.Unmarshal(body, &flagResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse flag response: %s", err))
+	updatedVariant, diags := variantFromModel(data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Update the variant in the variants list
-	updatedVariants := make([]map[string]interface{}, 0)
-	found := false
-	for _, v := range flagResponse.Resource.Payload.Variants {
-		if vKey, ok := v["key"].(string); ok && vKey == data.Key.ValueString() {
-			found = true
-			updatedVariant := map[string]interface{}{
-				"key": data.Key.ValueString(),
-			}
-
-			if !data.Name.IsNull() && !data.Name.IsUnknown() {
-				updatedVariant["name"] = data.Name.ValueString()
-			} else {
-				updatedVariant["name"] = ""
-			}
-
-			if !data.Description.IsNull() && !data.Description.IsUnknown() {
-				updatedVariant["description"] = data.Description.ValueString()
-			} else {
-				updatedVariant["description"] = ""
-			}
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
 
-			if !data.Attachment.IsNull() && !data.Attachment.IsUnknown() {
-				var attachmentData map[string]interface{}
-				if err := json.Unmarshal([]byte(data.Attachment.ValueString()), &attachmentData); err != nil {
-					resp.Diagnostics.AddError("Invalid Attachment", fmt.Sprintf("Attachment must be valid JSON: %s", err))
-					return
-				}
-				updatedVariant["attachment"] = attachmentData
-			} else {
-				updatedVariant["attachment"] = map[string]interface{}{}
+	// Update the variant in the variants list of the flag
+	_, err := r.config.Client.UpdateFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString(), func(flag *client.Flag) error {
+		for i, v := range flag.Variants {
+			if v.Key == data.Key.ValueString() {
+				flag.Variants[i] = updatedVariant
+				return nil
 			}
-
-			updatedVariants = append(updatedVariants, updatedVariant)
-		} else {
-			updatedVariants = append(updatedVariants, v)
 		}
-	}
-
-	if !found {
+		return errNotInParent
+	})
+	if errors.Is(err, errNotInParent) {
 		resp.Diagnostics.AddError("Variant Not Found", fmt.Sprintf("Variant with key '%s' not found in flag", data.Key.ValueString()))
 		return
 	}
-
-	// Update the flag with modified variants
-	flagPayload := map[string]interface{}{
-		"@type":       "flipt.core.Flag",
-		"key":         flagResponse.Resource.Payload.Key,
-		"name":        flagResponse.Resource.Payload.Name,
-		"description": flagResponse.Resource.Payload.Description,
-		"type":        flagResponse.Resource.Payload.Type,
-		"enabled":     flagResponse.Resource.Payload.Enabled,
-		"variants":    updatedVariants,
-		"rules":       []interface{}{},
-		"metadata":    flagResponse.Resource.Payload.Metadata,
-	}
-
-	updateReq := map[string]interface{}{
-		"key":     data.FlagKey.ValueString(),
-		"payload": flagPayload,
-	}
-
-	reqBody, err := json.Marshal(updateReq)
 	if err != nil {
-		resp.Diagnostics.AddError("Serialization Error", fmt.Sprintf("Unable to marshal request: %s", err))
-		return
-	}
-
-	updateURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources", r.config.Endpoint, envKey, data.NamespaceKey.ValueString())
-	httpReq, err = http.NewRequestWithContext(ctx, "PUT", updateURL, bytes.NewReader(reqBody))
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err = r.config.HTTPClient.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update variant, got error: %s", err))
-		return
-	}
-	defer httpResp.Body.Close()
-
-	body, _ = io.ReadAll(httpResp.Body)
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to update variant, status: %d, body: %s", httpResp.StatusCode, string(body)))
+		addParentWriteError(&resp.Diagnostics, "update variant", "flag", data.FlagKey.ValueString(), err)
 		return
 	}
 
@@ -545,11 +309,10 @@ func (r *VariantResource) Delete(ctx context.Context, req resource.DeleteRequest
 		return
 	}
 
-	// Determine environment key (default to "default" if not specified)
-	envKey := "default"
-	if !data.EnvironmentKey.IsNull() && !data.EnvironmentKey.IsUnknown() {
-		envKey = data.EnvironmentKey.ValueString()
-	}
+	// Fall back to the provider defaults for keys not set on the resource
+	envKey := r.config.EnvironmentKey(data.EnvironmentKey)
+	data.EnvironmentKey = types.StringValue(envKey)
+	data.NamespaceKey = types.StringValue(r.config.NamespaceKey(data.NamespaceKey))
 
 	tflog.Debug(ctx, "Deleting variant", map[string]interface{}{
 		"environment_key": envKey,
@@ -558,111 +321,119 @@ func (r *VariantResource) Delete(ctx context.Context, req resource.DeleteRequest
 		"variant_key":     data.Key.ValueString(),
 	})
 
-	// Get the current flag to read existing variants
-	flagURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources/flipt.core.Flag/%s",
-		r.config.Endpoint, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString())
+	unlock := r.config.LockParent(envKey, data.NamespaceKey.ValueString(), client.TypeFlag, data.FlagKey.ValueString())
+	defer unlock()
 
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", flagURL, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
-	}
+	// Remove the variant from the variants list of the flag
+	_, err := r.config.Client.UpdateFlag(ctx, envKey, data.NamespaceKey.ValueString(), data.FlagKey.ValueString(), func(flag *client.Flag) error {
+		// Flipt rejects a default variant that is not one of the flag's
+		// variants. Clearing it here would leave flipt_flag_default_variant
+		// out of sync, so the default has to be changed first.
+		if flag.DefaultVariant == data.Key.ValueString() {
+			return errDefaultVariant
+		}
 
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err := r.config.HTTPClient.Do(httpReq)
+		remainingVariants := make([]client.Variant, 0, len(flag.Variants))
+		for _, v := range flag.Variants {
+			if v.Key != data.Key.ValueString() {
+				remainingVariants = append(remainingVariants, v)
+			}
+		}
+		flag.Variants = remainingVariants
+		return nil
+	})
 	if err != nil {
-		// If flag doesn't exist, variant is already gone
+		if errors.Is(err, client.ErrNotFound) {
+			// Flag doesn't exist, so variant is gone
+			return
+		}
+		if errors.Is(err, errDefaultVariant) {
+			resp.Diagnostics.AddError("Variant Is Default",
+				fmt.Sprintf("Unable to delete variant: variant '%s' is the default variant of flag '%s'. "+
+					"Change or remove the flag's flipt_flag_default_variant first. Setting its variant_key from the "+
+					"flipt_variant resource, e.g. flipt_variant.example.key, lets Terraform order the changes.",
+					data.Key.ValueString(), data.FlagKey.ValueString()))
+			return
+		}
+		addParentWriteError(&resp.Diagnostics, "delete variant", "flag", data.FlagKey.ValueString(), err)
 		return
 	}
-	defer httpResp.Body.Close()
 
-	if httpResp.StatusCode == http.StatusNotFound {
-		// Flag doesn't exist, so variant is gone
-		return
-	}
+	tflog.Trace(ctx, "deleted a variant resource")
+}
 
-	body, _ := io.ReadAll(httpResp.Body)
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read flag, status: %d, body: %s", httpResp.StatusCode, string(body)))
+// ImportState imports a variant by an ID of the form
+// environment_key/namespace_key/flag_key/variant_key.
+func (r *VariantResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts, ok := parseImportID(req, resp, "environment_key", "namespace_key", "flag_key", "variant_key")
+	if !ok {
 		return
 	}
 
-	var flagResponse struct {
-		Resource struct {
-			Payload struct {
-				Type        string                   `json:"type"`
-				Key         string                   `json:"key"`
-				Name        string                   `json:"name"`
-				Description string                   `json:"description"`
-				Enabled     bool                     `json:"enabled"`
-				Variants    []map[string]interface{} `json:"variants"`
-				Metadata    map[string]interface{}   `json:"metadata"`
-			} `json:"payload"`
-		} `json:"resource"`
-	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("environment_key"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("namespace_key"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("flag_key"), parts[2])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("key"), parts[3])...)
+}
 
-	if err := json.Unmarshal(body, &flagResponse); err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse flag response: %s", err))
-		return
-	}
+// variantFromModel builds the variant payload from the resource model.
+func variantFromModel(data VariantResourceModel) (client.Variant, diag.Diagnostics) {
+	var diags diag.Diagnostics
 
-	// Remove the variant from the variants list
-	remainingVariants := make([]map[string]interface{}, 0)
-	for _, v := range flagResponse.Resource.Payload.Variants {
-		if vKey, ok := v["key"].(string); !ok || vKey != data.Key.ValueString() {
-			remainingVariants = append(remainingVariants, v)
-		}
+	variant := client.Variant{
+		Key:        data.Key.ValueString(),
+		Attachment: map[string]interface{}{},
 	}
 
-	// Update the flag with remaining variants (excluding the deleted one)
-	flagPayload := map[string]interface{}{
-		"@type":       "flipt.core.Flag",
-		"key":         flagResponse.Resource.Payload.Key,
-		"name":        flagResponse.Resource.Payload.Name,
-		"description": flagResponse.Resource.Payload.Description,
-		"type":        flagResponse.Resource.Payload.Type,
-		"enabled":     flagResponse.Resource.Payload.Enabled,
-		"variants":    remainingVariants,
-		"rules":       []interface{}{},
-		"metadata":    flagResponse.Resource.Payload.Metadata,
+	if !data.Name.IsNull() && !data.Name.IsUnknown() {
+		variant.Name = data.Name.ValueString()
 	}
 
-	updateReq := map[string]interface{}{
-		"key":     data.FlagKey.ValueString(),
-		"payload": flagPayload,
+	if !data.Description.IsNull() && !data.Description.IsUnknown() {
+		variant.Description = data.Description.ValueString()
 	}
 
-	reqBody, err := json.Marshal(updateReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Serialization Error", fmt.Sprintf("Unable to marshal request: %s", err))
-		return
+	if !data.Attachment.IsNull() && !data.Attachment.IsUnknown() {
+		diags.Append(data.Attachment.Unmarshal(&variant.Attachment)...)
 	}
 
-	updateURL := fmt.Sprintf("%s/api/v2/environments/%s/namespaces/%s/resources", r.config.Endpoint, envKey, data.NamespaceKey.ValueString())
-	httpReq, err = http.NewRequestWithContext(ctx, "PUT", updateURL, bytes.NewReader(reqBody))
-	if err != nil {
-		resp.Diagnostics.AddError("Request Error", fmt.Sprintf("Unable to create request: %s", err))
-		return
+	return variant, diags
+}
+
+// variantAttachmentToModel converts the attachment of a variant to its JSON
+// string. Flipt returns a missing attachment as an empty object or string,
+// both are null in the model.
+func variantAttachmentToModel(attachment interface{}) (jsontypes.Normalized, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	switch v := attachment.(type) {
+	case nil:
+		return jsontypes.NewNormalizedNull(), diags
+	case string:
+		if v == "" {
+			return jsontypes.NewNormalizedNull(), diags
+		}
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return jsontypes.NewNormalizedNull(), diags
+		}
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
 
-	r.config.AddAuthHeader(httpReq)
-	httpResp, err = r.config.HTTPClient.Do(httpReq)
+	attachmentJSON, err := json.Marshal(attachment)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete variant, got error: %s", err))
-		return
+		diags.AddError("Invalid Attachment", fmt.Sprintf("Unable to encode the variant attachment as JSON: %s", err))
+		return jsontypes.NewNormalizedNull(), diags
 	}
-	defer httpResp.Body.Close()
+	return jsontypes.NewNormalizedValue(string(attachmentJSON)), diags
+}
 
-	body, _ = io.ReadAll(httpResp.Body)
-	if httpResp.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to delete variant, status: %d, body: %s", httpResp.StatusCode, string(body)))
-		return
+// isEmptyAttachment reports whether an attachment is set to an empty JSON
+// object.
+func isEmptyAttachment(attachment jsontypes.Normalized) bool {
+	if attachment.IsNull() || attachment.IsUnknown() {
+		return false
 	}
 
-	tflog.Trace(ctx, "deleted a variant resource")
-}
-
-func (r *VariantResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("key"), req, resp)
+	var v map[string]interface{}
+	return json.Unmarshal([]byte(attachment.ValueString()), &v) == nil && v != nil && len(v) == 0
 }
@@ -4,12 +4,22 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"terraform-provider-flipt/internal/client"
 )
 
 func TestAccVariantResource(t *testing.T) {
@@ -28,6 +38,13 @@ func TestAccVariantResource(t *testing.T) {
 					resource.TestCheckResourceAttr("flipt_variant.test", "name", "Test Variant"),
 				),
 			},
+			// ImportState testing
+			{
+				ResourceName:      "flipt_variant.test",
+				ImportState:       true,
+				ImportStateId:     "default/test-namespace/test-flag/test-variant",
+				ImportStateVerify: true,
+			},
 			// Update and Read testing
 			{
 				Config: testAccVariantResourceConfig("default", "test-namespace", "test-flag", "test-variant", "Updated Variant"),
@@ -69,6 +86,158 @@ resource "flipt_variant" "test" {
 `
 }
 
+func TestAccVariantResourceAttachment(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Whitespace and key order of the attachment differ from what
+			// Flipt returns, which must not show up as a change
+			{
+				Config: testAccVariantResourceAttachmentConfig(`<<EOT
+{
+  "size":  "large",
+  "color": "red",
+  "tags":  ["a", "b"]
+}
+EOT`),
+				Check: resource.TestCheckResourceAttrSet("flipt_variant.test", "attachment"),
+			},
+			{
+				Config: testAccVariantResourceAttachmentConfig(`jsonencode({ color = "red", size = "large", tags = ["a", "b"] })`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+			// Arrays and scalars are accepted as well
+			{
+				Config: testAccVariantResourceAttachmentConfig(`jsonencode([1, 2, 3])`),
+				Check:  resource.TestCheckResourceAttr("flipt_variant.test", "attachment", "[1,2,3]"),
+			},
+			{
+				Config: testAccVariantResourceAttachmentConfig(`jsonencode("blue")`),
+				Check:  resource.TestCheckResourceAttr("flipt_variant.test", "attachment", `"blue"`),
+			},
+		},
+	})
+}
+
+func testAccVariantResourceAttachmentConfig(attachment string) string {
+	return `
+provider "flipt" {
+  endpoint = "` + getTestFliptEndpoint() + `"
+}
+
+resource "flipt_namespace" "test" {
+  key  = "attachment-namespace"
+  name = "Attachment Namespace"
+}
+
+resource "flipt_flag" "test" {
+  namespace_key = flipt_namespace.test.key
+  key           = "attachment-flag"
+  name          = "Attachment Flag"
+}
+
+resource "flipt_variant" "test" {
+  namespace_key = flipt_namespace.test.key
+  flag_key      = flipt_flag.test.key
+  key           = "attachment-variant"
+  attachment    = ` + attachment + `
+}
+`
+}
+
+func TestVariantResourceAttachment(t *testing.T) {
+	tests := []struct {
+		name       string
+		attachment jsontypes.Normalized
+		stored     interface{}
+	}{
+		{
+			name:       "object",
+			attachment: jsontypes.NewNormalizedValue(`{ "size": "large", "color": "red" }`),
+			stored:     map[string]interface{}{"color": "red", "size": "large"},
+		},
+		{
+			name:       "array",
+			attachment: jsontypes.NewNormalizedValue(`[1, "two", {"three": 3}]`),
+			stored:     []interface{}{float64(1), "two", map[string]interface{}{"three": float64(3)}},
+		},
+		{
+			name:       "scalar",
+			attachment: jsontypes.NewNormalizedValue(`"blue"`),
+			stored:     "blue",
+		},
+		{
+			name:       "empty object",
+			attachment: jsontypes.NewNormalizedValue(`{}`),
+			stored:     map[string]interface{}{},
+		},
+		{
+			name:       "none",
+			attachment: jsontypes.NewNormalizedNull(),
+			stored:     map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeFlipt(t)
+			fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+				"name":    "Checkout",
+				"type":    "VARIANT_FLAG_TYPE",
+				"enabled": true,
+			})
+
+			r := NewVariantResource()
+			configureResource(t, r, fake.config())
+
+			model := &VariantResourceModel{
+				NamespaceKey:   types.StringValue("default"),
+				EnvironmentKey: types.StringValue("default"),
+				FlagKey:        types.StringValue("checkout"),
+				Key:            types.StringValue("blue"),
+				Name:           types.StringNull(),
+				Description:    types.StringNull(),
+				Attachment:     tt.attachment,
+			}
+			resp, _ := createResource(t, r, model)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Create failed: %v", resp.Diagnostics)
+			}
+
+			variants, _ := fake.get("default", "default", client.TypeFlag, "checkout")["variants"].([]interface{})
+			if len(variants) != 1 {
+				t.Fatalf("Expected 1 variant, got %d", len(variants))
+			}
+			if got := variants[0].(map[string]interface{})["attachment"]; !reflect.DeepEqual(got, tt.stored) {
+				t.Errorf("Expected stored attachment %#v, got %#v", tt.stored, got)
+			}
+
+			readResp, state := readResource(t, r, model)
+			if readResp.Diagnostics.HasError() {
+				t.Fatalf("Read failed: %v", readResp.Diagnostics)
+			}
+			var read VariantResourceModel
+			state.Get(context.Background(), &read)
+
+			if tt.attachment.IsNull() {
+				if !read.Attachment.IsNull() {
+					t.Errorf("Expected no attachment, got %s", read.Attachment)
+				}
+				return
+			}
+			equal, diags := tt.attachment.StringSemanticEquals(context.Background(), read.Attachment)
+			if diags.HasError() || !equal {
+				t.Errorf("Expected attachment equal to %s, got %s", tt.attachment, read.Attachment)
+			}
+		})
+	}
+}
+
 func TestVariantResourceHTTP(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -135,3 +304,160 @@ func TestVariantResourceHTTP(t *testing.T) {
 		t.Fatal("Expected server URL to be set")
 	}
 }
+
+func TestVariantResourceConcurrentCreate(t *testing.T) {
+	fake := newFakeFlipt(t)
+	fake.readDelay = 5 * time.Millisecond
+	fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+		"name":    "Checkout",
+		"type":    "VARIANT_FLAG_TYPE",
+		"enabled": true,
+	})
+
+	// Resources are created per operation but share the provider configuration.
+	config := fake.config()
+
+	const count = 20
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			r := NewVariantResource()
+			configureResource(t, r, config)
+
+			resp, _ := createResource(t, r, &VariantResourceModel{
+				NamespaceKey:   types.StringValue("default"),
+				EnvironmentKey: types.StringValue("default"),
+				FlagKey:        types.StringValue("checkout"),
+				Key:            types.StringValue(fmt.Sprintf("variant-%d", i)),
+				Name:           types.StringNull(),
+				Description:    types.StringNull(),
+				Attachment:     jsontypes.NewNormalizedNull(),
+			})
+			if resp.Diagnostics.HasError() {
+				t.Errorf("Create failed: %v", resp.Diagnostics)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	variants, _ := fake.get("default", "default", client.TypeFlag, "checkout")["variants"].([]interface{})
+	if len(variants) != count {
+		t.Fatalf("Expected %d variants, got %d", count, len(variants))
+	}
+}
+
+func TestVariantResourceCreateConflict(t *testing.T) {
+	tests := []struct {
+		name           string
+		externalWrites int
+		expectErr      bool
+	}{
+		{name: "Retried after concurrent change", externalWrites: 2},
+		{name: "Concurrent changes persist", externalWrites: 100, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeFlipt(t)
+			fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+				"name":    "Checkout",
+				"type":    "VARIANT_FLAG_TYPE",
+				"enabled": true,
+			})
+			fake.externalWrites = tt.externalWrites
+
+			r := NewVariantResource()
+			configureResource(t, r, fake.config())
+
+			resp, _ := createResource(t, r, &VariantResourceModel{
+				NamespaceKey:   types.StringValue("default"),
+				EnvironmentKey: types.StringValue("default"),
+				FlagKey:        types.StringValue("checkout"),
+				Key:            types.StringValue("blue"),
+				Name:           types.StringNull(),
+				Description:    types.StringNull(),
+				Attachment:     jsontypes.NewNormalizedNull(),
+			})
+
+			if tt.expectErr {
+				if !resp.Diagnostics.HasError() {
+					t.Fatal("Expected an error")
+				}
+				d := resp.Diagnostics.Errors()[0]
+				if d.Summary() != "Concurrent Modification" || !strings.Contains(d.Detail(), "flag 'checkout'") {
+					t.Errorf("Unexpected diagnostic: %s: %s", d.Summary(), d.Detail())
+				}
+				return
+			}
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Create failed: %v", resp.Diagnostics)
+			}
+			variants, _ := fake.get("default", "default", client.TypeFlag, "checkout")["variants"].([]interface{})
+			if len(variants) != 1 {
+				t.Errorf("Expected 1 variant, got %d", len(variants))
+			}
+		})
+	}
+}
+
+func TestVariantResourceDeleteDefault(t *testing.T) {
+	tests := []struct {
+		name            string
+		variantKey      string
+		expectErr       string
+		expectRemaining []string
+	}{
+		{name: "Default variant", variantKey: "blue", expectErr: "Variant Is Default", expectRemaining: []string{"blue", "green"}},
+		{name: "Other variant", variantKey: "green", expectRemaining: []string{"blue"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeFlipt(t)
+			fake.put("default", "default", client.TypeFlag, "checkout", map[string]interface{}{
+				"name":           "Checkout",
+				"type":           "VARIANT_FLAG_TYPE",
+				"enabled":        true,
+				"defaultVariant": "blue",
+				"variants":       []interface{}{map[string]interface{}{"key": "blue"}, map[string]interface{}{"key": "green"}},
+			})
+
+			r := NewVariantResource()
+			configureResource(t, r, fake.config())
+
+			resp := deleteResource(t, r, &VariantResourceModel{
+				NamespaceKey:   types.StringValue("default"),
+				EnvironmentKey: types.StringValue("default"),
+				FlagKey:        types.StringValue("checkout"),
+				Key:            types.StringValue(tt.variantKey),
+				Name:           types.StringNull(),
+				Description:    types.StringNull(),
+				Attachment:     jsontypes.NewNormalizedNull(),
+			})
+			if tt.expectErr != "" {
+				if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != tt.expectErr {
+					t.Fatalf("Expected %q error, got %v", tt.expectErr, resp.Diagnostics)
+				}
+			} else if resp.Diagnostics.HasError() {
+				t.Fatalf("Delete failed: %v", resp.Diagnostics)
+			}
+
+			// The default variant is never changed by deleting a variant
+			flag := fake.get("default", "default", client.TypeFlag, "checkout")
+			if defaultVariant, _ := flag["defaultVariant"].(string); defaultVariant != "blue" {
+				t.Errorf("Expected default variant blue, got %q", defaultVariant)
+			}
+			var remaining []string
+			for _, v := range flag["variants"].([]interface{}) {
+				remaining = append(remaining, v.(map[string]interface{})["key"].(string))
+			}
+			if !reflect.DeepEqual(remaining, tt.expectRemaining) {
+				t.Errorf("Expected variants %v to remain, got %v", tt.expectRemaining, remaining)
+			}
+		})
+	}
+}